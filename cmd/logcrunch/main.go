@@ -5,14 +5,25 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"flag"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/anomaly"
+	"github.com/KirilStrezikozin/logcrunch/internal/handlers"
+	"github.com/KirilStrezikozin/logcrunch/internal/metrics"
+	"github.com/KirilStrezikozin/logcrunch/internal/multiline"
+	"github.com/KirilStrezikozin/logcrunch/internal/search"
+	"github.com/KirilStrezikozin/logcrunch/internal/services"
+	"github.com/KirilStrezikozin/logcrunch/internal/tail"
 	"github.com/KirilStrezikozin/logcrunch/internal/types"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -22,49 +33,171 @@ import (
 )
 
 func main() {
+	stdin := flag.Bool("stdin", false, "ingest lines piped to standard input, the UI keeps serving after EOF")
+	stdinDecoder := flag.String("stdin-decoder", tail.DecoderText, "decoder of standard input lines: json, text, docker or cri")
+	openUI := flag.Bool("open", false, "open the web UI in a browser (default true with -stdin)")
+	flag.Parse()
+
+	openSet := false
+	flag.Visit(func(f *flag.Flag) {
+		openSet = openSet || f.Name == "open"
+	})
+	if !openSet {
+		*openUI = *stdin
+	}
+
 	sourceScheme := os.Getenv("LOGCRUNCH_SOURCE_SCHEME")
 	sourceHost := os.Getenv("LOGCRUNCH_SOURCE_HOST")
 	sourcePort := os.Getenv("LOGCRUNCH_SOURCE_PORT")
 	sourcePath := os.Getenv("LOGCRUNCH_SOURCE_PATH")
 	serveHost := os.Getenv("LOGCRUNCH_SERVE_HOST")
 	servePort := os.Getenv("LOGCRUNCH_SERVE_PORT")
+	syslogUDPAddr := os.Getenv("LOGCRUNCH_SYSLOG_UDP_ADDR")
+	syslogTCPAddr := os.Getenv("LOGCRUNCH_SYSLOG_TCP_ADDR")
+	tailPaths := os.Getenv("LOGCRUNCH_TAIL_PATHS")
+	tailDecoder := cmp.Or(os.Getenv("LOGCRUNCH_TAIL_DECODER"), tail.DecoderJSON)
+	multilineStart := os.Getenv("LOGCRUNCH_MULTILINE_START")
+	multilineContinue := os.Getenv("LOGCRUNCH_MULTILINE_CONTINUE") // regexp or "stacktrace"
+	multilineTimeout := os.Getenv("LOGCRUNCH_MULTILINE_TIMEOUT")
+	pipelineConfig := os.Getenv("LOGCRUNCH_PIPELINE_CONFIG")
+	alertRules := os.Getenv("LOGCRUNCH_ALERT_RULES")
+	anomalySigma := os.Getenv("LOGCRUNCH_ANOMALY_SIGMA")
+	anomalyInterval := os.Getenv("LOGCRUNCH_ANOMALY_INTERVAL")
+	metricsConfig := os.Getenv("LOGCRUNCH_METRICS_CONFIG")
+	storeCapacity := os.Getenv("LOGCRUNCH_STORE_CAPACITY")
+	searchSegments := os.Getenv("LOGCRUNCH_SEARCH_SEGMENTS") // max on-disk segments of evicted logs
 
-	reconnect := make(chan struct{})
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt)
 
-	url := url.URL{Scheme: sourceScheme, Host: sourceHost + ":" + sourcePort, Path: sourcePath}
 	logger := zerolog.New(zerolog.ConsoleWriter{
 		Out:        os.Stdout,
 		TimeFormat: time.RFC3339,
 	}).With().Timestamp().Logger()
 
-	c := internal.NewWebSocketClient(url, logger)
-	s := internal.NewStore(1000)
+	reg := metrics.NewRegistry()
+
+	db := internal.NewBoltDB()
+	db.Instrument(reg)
+	if err := db.Open(); err != nil {
+		logger.Fatal().Err(err).Msg("db")
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Error().Err(err).Msg("db close")
+		}
+	}()
+
+	capacity := internal.DefaultStoreCapacity
+	if storeCapacity != "" {
+		var err error
+		if capacity, err = strconv.Atoi(storeCapacity); err != nil {
+			logger.Fatal().Err(err).Msg("store capacity")
+		}
+	}
+
+	anomalyConfig := anomaly.DefaultConfig
+	if anomalySigma != "" {
+		var err error
+		if anomalyConfig.Sigma, err = strconv.ParseFloat(anomalySigma, 64); err != nil || anomalyConfig.Sigma <= 0 {
+			logger.Fatal().Err(err).Str("sigma", anomalySigma).Msg("anomaly sigma")
+		}
+	}
+	if anomalyInterval != "" {
+		var err error
+		if anomalyConfig.Interval, err = time.ParseDuration(anomalyInterval); err != nil || anomalyConfig.Interval <= 0 {
+			logger.Fatal().Err(err).Str("interval", anomalyInterval).Msg("anomaly interval")
+		}
+	}
 
-	readLoop := func() error {
-		return c.Read(func(data []byte) {
-			log, err := internal.NewLog(data)
-			if err != nil {
-				logger.Error().Err(err).Msg("invalid log data")
-				return
+	var archive *search.Archive
+	if searchSegments != "" {
+		maxSegments, err := strconv.Atoi(searchSegments)
+		if err != nil || maxSegments < 0 {
+			logger.Fatal().Err(err).Str("segments", searchSegments).Msg("search segments")
+		}
+		if maxSegments > 0 {
+			if archive, err = search.NewArchive(db, maxSegments); err != nil {
+				logger.Fatal().Err(err).Msg("search segments")
 			}
+		}
+	}
 
-			logger.Info().Str("id", string(log.ID)).Msg("log received")
-			s.AddLog(log)
-		})
+	store := internal.NewStore(capacity)
+	searchService := services.NewSearchService(store, archive, logger)
+	fieldService := services.NewFieldService(store, logger)
+	aggregateService := services.NewAggregateService(store, archive, logger)
+	histogramService := services.NewHistogramService(store, logger)
+	patternService := services.NewPatternService(store, logger)
+	anomalyService := services.NewAnomalyService(store, anomalyConfig, logger)
+	ids := internal.NewSequenceGenerator()
+	wsClient := internal.NewWebSocketClient(logger)
+
+	alertService, err := services.NewAlertService(store, alertRules, logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("alert")
 	}
 
-	doReconnect := func() {
-		defer func() { reconnect <- struct{}{} }()
+	metricsService, err := services.NewMetricsService(store, reg, metricsConfig, logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("metrics")
+	}
 
-		if err := c.Dial(); err != nil {
-			logger.Error().Err(err).Msg("dial failed")
-			return
+	pipelineService, err := services.NewPipelineService(store, pipelineConfig, logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("pipeline")
+	}
+	pipelineService.Instrument(reg)
+
+	logService := services.NewLogService(wsClient, store, pipelineService, logger)
+	connService := services.NewConnectionService(db, wsClient, logService, logger)
+	connService.Instrument(reg)
+	profilerService := services.NewProfilerService(db, store, logger)
+	viewService := services.NewViewService(db, logger)
+	otlpService := services.NewOTLPService(pipelineService, ids, logger)
+	syslogService := services.NewSyslogService(pipelineService, ids, logger)
+
+	var multilineConfig *multiline.Config
+	if multilineStart != "" || multilineContinue != "" {
+		var timeout time.Duration
+		if multilineTimeout != "" {
+			var err error
+			if timeout, err = time.ParseDuration(multilineTimeout); err != nil {
+				logger.Fatal().Err(err).Msg("multiline timeout")
+			}
 		}
 
-		if err := readLoop(); err != nil {
-			logger.Error().Err(err).Msg("read")
+		cfg, err := multiline.NewConfig(multilineStart, multilineContinue, timeout)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("multiline")
+		}
+		multilineConfig = &cfg
+	}
+
+	var stdinService *services.StdinService
+	if *stdin {
+		var err error
+		stdinService, err = services.NewStdinService(pipelineService, ids, *stdinDecoder, multilineConfig, logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("stdin")
+		}
+	}
+
+	var tailService *services.TailService
+	if tailPaths != "" {
+		var err error
+		tailService, err = services.NewTailService(
+			db, pipelineService, ids, strings.Split(tailPaths, ","), tailDecoder, multilineConfig, logger,
+		)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("tail")
+		}
+	}
+
+	if sourceHost != "" {
+		url := url.URL{Scheme: sourceScheme, Host: sourceHost + ":" + sourcePort, Path: sourcePath}
+		if _, err := connService.SetURL(url.String()); err != nil {
+			logger.Error().Err(err).Msg("set source url")
 		}
 	}
 
@@ -72,27 +205,58 @@ func main() {
 		Logger: &logger,
 	})
 
-	h := internal.NewHandler(logger)
+	h := handlers.New(logger, connService, logService, profilerService, otlpService, pipelineService, searchService, fieldService, aggregateService, histogramService, viewService, patternService, alertService, anomalyService, metricsService)
 
 	r := chi.NewRouter()
 	r.Use(reqLogger)
 
 	r.Handle(types.EndpointStatic, h.Static())
 	r.Handle(types.EndpontIndex, h.Index())
+	r.Get(types.EndpointGetSavedViewPage, h.GetSavedViewPage)
 
 	r.Get(types.EndpointGetConnectionStatus, h.GetConnectionStatus)
 	r.Get(types.EndpointGetConnectionURL, h.GetConnectionURL)
 	r.Post(types.EndpointPostConnectionURL, h.PostConnectionURL)
 
-	r.HandleFunc("/unread", func(w http.ResponseWriter, r *http.Request) {
-		// ctx := r.Context()
-		// dataComponent := templates.Logs(s.GetUnreadLogs(1))
-		// err := dataComponent.Render(ctx, w)
-		// if err != nil {
-		// 	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-		// 	return
-		// }
-	})
+	r.Get(types.EndpointGetLog, h.GetLog)
+	r.Get(types.EndpointGetLogSearch, h.GetLogSearch)
+	r.Get(types.EndpointGetFields, h.GetFields)
+	r.Get(types.EndpointGetAggregate, h.GetAggregate)
+	r.Get(types.EndpointGetHistogram, h.GetHistogram)
+	r.Get(types.EndpointGetPatterns, h.GetPatterns)
+	r.Get(types.EndpointGetPatternLogs, h.GetPatternLogs)
+	r.Get(types.EndpointGetAlerts, h.GetAlerts)
+	r.Get(types.EndpointGetAnomalies, h.GetAnomalies)
+	r.Get(types.EndpointGetMetrics, h.GetMetrics)
+	r.Post(types.EndpointPostLogs, h.PostLogs)
+	r.Post(types.EndpointPostOTLPLogs, h.PostOTLPLogs)
+	r.Post(types.EndpointPostOTLPTraces, h.PostOTLPTraces)
+
+	r.Get(types.EndpointGetSavedViews, h.GetSavedViews)
+	r.Post(types.EndpointPostSavedViews, h.PostSavedViews)
+	r.Delete(types.EndpointDeleteSavedView, h.DeleteSavedView)
+
+	r.Get(types.EndpointGetPipeline, h.GetPipeline)
+	r.Post(types.EndpointPostPipelineReload, h.PostPipelineReload)
+
+	r.Method(http.MethodGet, types.EndpointGetLogListView, h.GetLogListView())
+	r.Get(types.EndpointGetLogSearchView, h.GetLogSearchView)
+	r.Get(types.EndpointGetLogColumnsView, h.GetLogColumnsView)
+	r.Get(types.EndpointGetFieldSuggestView, h.GetFieldSuggestView)
+	r.Get(types.EndpointGetFieldPathsView, h.GetFieldPathsView)
+	r.Get(types.EndpointGetAggregateView, h.GetAggregateView)
+	r.Get(types.EndpointGetHistogramView, h.GetHistogramView)
+	r.Get(types.EndpointGetPatternsView, h.GetPatternsView)
+	r.Get(types.EndpointGetPatternLogsView, h.GetPatternLogsView)
+	r.Get(types.EndpointGetAlertsView, h.GetAlertsView)
+	r.Method(http.MethodGet, types.EndpointGetProfilerView, h.GetProfilerView())
+
+	r.Get(types.EndpointGetProfilerTimeline, h.GetProfilerTimeline)
+	r.Get(types.EndpointGetProfilerDiff, h.GetProfilerDiff)
+	r.Get(types.EndpointGetProfilerHealth, h.GetProfilerHealth)
+	r.Get(types.EndpointGetProfilerCriticalPath, h.GetProfilerCriticalPath)
+	r.Get(types.EndpointGetProfilerSessions, h.GetProfilerSessions)
+	r.Post(types.EndpointPostProfilerSessions, h.PostProfilerSessions)
 
 	server := http.Server{
 		Addr:         serveHost + ":" + servePort,
@@ -108,7 +272,75 @@ func main() {
 		}
 	}()
 
-	go doReconnect() // initial connect
+	if stdinService != nil {
+		go func() {
+			if err := stdinService.Read(os.Stdin); err != nil {
+				logger.Error().Err(err).Msg("stdin")
+			}
+			logger.Info().Msg("stdin closed, UI keeps serving until interrupted")
+		}()
+	}
+
+	if *openUI {
+		uiURL := url.URL{Scheme: "http", Host: cmp.Or(serveHost, "localhost") + ":" + servePort}
+		logger.Info().Msgf("opening UI at %s", uiURL.String())
+		if err := openBrowser(uiURL.String()); err != nil {
+			logger.Error().Err(err).Msg("open UI")
+		}
+	}
+
+	for _, l := range []struct {
+		addr   string
+		listen func(string) error
+	}{
+		{syslogUDPAddr, syslogService.ListenUDP},
+		{syslogTCPAddr, syslogService.ListenTCP},
+	} {
+		if l.addr == "" {
+			continue
+		}
+		go func() {
+			if err := l.listen(l.addr); err != nil {
+				logger.Error().Err(err).Msg("syslog")
+			}
+		}()
+	}
+	defer func() {
+		if err := syslogService.Close(); err != nil {
+			logger.Error().Err(err).Msg("syslog close")
+		}
+	}()
+
+	stopTail := make(chan struct{})
+	tailDone := make(chan struct{})
+	if tailService != nil {
+		go func() {
+			defer close(tailDone)
+			tailService.Run(stopTail)
+		}()
+	} else {
+		close(tailDone)
+	}
+
+	stopPipeline := make(chan struct{})
+	go pipelineService.Watch(stopPipeline)
+
+	stopAlerts := make(chan struct{})
+	go alertService.Run(stopAlerts)
+
+	stopSearch := make(chan struct{})
+	searchDone := make(chan struct{})
+	go func() {
+		defer close(searchDone)
+		searchService.Run(stopSearch)
+	}()
+
+	stopReconnect := make(chan struct{})
+	reconnectDone := make(chan struct{})
+	go func() {
+		defer close(reconnectDone)
+		connService.ReconnectLoop(stopReconnect)
+	}()
 
 	defer func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -120,27 +352,14 @@ func main() {
 		}
 	}()
 
-	for {
-		select {
-		case <-interrupt:
-			logger.Info().Msg("interrupt")
-			if err := c.Close(); err != nil {
-				logger.Error().Err(err).Msg("websocket client close")
-			}
-			<-reconnect // wait for the reconnect go-routine to exit
-			return
-		case <-reconnect:
-			if err := c.Close(); err != nil {
-				logger.Error().Err(err).Msg("websocket client close")
-			}
-			logger.Info().Msgf("(re)connecting to %s in 3 seconds...", url.String())
-			select {
-			case <-interrupt:
-				logger.Info().Msg("reconnect cancelled due to interrupt")
-				return
-			case <-time.After(3 * time.Second):
-				go doReconnect()
-			}
-		}
-	}
+	<-interrupt
+	logger.Info().Msg("interrupt")
+	close(stopReconnect)
+	close(stopPipeline)
+	close(stopAlerts)
+	close(stopTail)
+	close(stopSearch)
+	<-reconnectDone // wait for the reconnect loop to exit
+	<-tailDone      // wait for tail checkpoints to be saved
+	<-searchDone    // wait for evicted logs to be archived
 }
@@ -5,94 +5,32 @@
 package main
 
 import (
+	"context"
 	"log"
+	"log/slog"
+	"math/rand/v2"
 	"net/http"
 	"os"
-	"strconv"
 	"time"
 
-	"github.com/KirilStrezikozin/logcrunch/internal"
-	"github.com/gorilla/websocket"
+	"github.com/KirilStrezikozin/logcrunch/pkg/producer"
 	_ "github.com/joho/godotenv/autoload"
 )
 
-var upgrader = websocket.Upgrader{HandshakeTimeout: 10 * time.Second}
+const sendPeriod = 5 * time.Second
 
-const (
-	pongWait       = 10 * time.Second
-	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 1 << 14
-	writeWait      = 10 * time.Second
-)
-
-func ws(w http.ResponseWriter, r *http.Request) {
-	c, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Print("upgrade:", err)
-		return
-	}
-	defer c.Close()
-
-	log.Print("new conn")
-
-	c.SetReadLimit(maxMessageSize)
-	c.SetReadDeadline(time.Now().Add(pongWait))
-	c.SetPongHandler(func(_ string) error {
-		log.Printf("pong received")
-		c.SetReadDeadline(time.Now().Add(pongWait))
-		return nil
-	})
+// work simulates a tracked request with nested calls.
+func work(ctx context.Context, logger *slog.Logger, iteration int) {
+	ctx, end := producer.Start(ctx, "work")
+	defer end()
 
-	sendTicker := time.NewTicker(5 * time.Second)
-	pingTicker := time.NewTicker(pingPeriod)
-
-	defer sendTicker.Stop()
-	defer pingTicker.Stop()
-
-	done := make(chan struct{})
-	msgs := make(chan []byte, 100)
-	lastLogID := -1
-
-	go func() {
-		defer close(done)
-		for {
-			_, message, err := c.ReadMessage()
-			if err != nil {
-				log.Println("read:", err)
-				break
-			}
-			log.Printf("recv: %s", message)
-		}
-	}()
+	logger.InfoContext(ctx, "New log message", "iteration", iteration)
 
-	for {
-		select {
-		case <-done:
-			log.Println("closing")
-			return
-		case msg := <-msgs:
-			c.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := c.WriteMessage(websocket.TextMessage, msg); err != nil {
-				log.Println("write:", err)
-				return
-			}
-		case <-pingTicker.C:
-			c.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := c.WriteMessage(websocket.PingMessage, nil); err != nil {
-				log.Println("ping:", err)
-				return
-			}
-			log.Println("ping sent")
-		case <-sendTicker.C:
-			lastLogID++
-			newLog := internal.Log{ID: internal.LogID(strconv.Itoa(lastLogID)), Message: "New log message"}
-			msg, err := newLog.MarshalJSON()
-			if err != nil {
-				log.Println("marshal:", err)
-				return
-			}
-			msgs <- msg
-		}
+	for range 2 {
+		func() {
+			defer producer.Track(ctx, "step")()
+			time.Sleep(time.Duration(rand.IntN(50)) * time.Millisecond)
+		}()
 	}
 }
 
@@ -101,9 +39,23 @@ func main() {
 	sourcePort := os.Getenv("LOGCRUNCH_SOURCE_PORT")
 	sourcePath := os.Getenv("LOGCRUNCH_SOURCE_PATH")
 
-	http.HandleFunc(sourcePath, ws)
+	p := producer.New(producer.Options{ProducerID: "server"})
+	producer.SetDefault(p)
+	logger := slog.New(producer.NewHandler(p, nil))
+
+	go func() {
+		ticker := time.NewTicker(sendPeriod)
+		defer ticker.Stop()
+
+		for i := 0; ; i++ {
+			<-ticker.C
+			work(context.Background(), logger, i)
+		}
+	}()
+
+	http.Handle(sourcePath, producer.NewWebSocketServer(p))
 	log.Fatal(func() error {
-		server := &http.Server{Addr: sourceHost + ":" + sourcePort, Handler: nil, WriteTimeout: 10 * time.Second, ReadTimeout: 10 * time.Second}
+		server := &http.Server{Addr: sourceHost + ":" + sourcePort, Handler: nil, ReadTimeout: 10 * time.Second}
 		return server.ListenAndServe()
 	}())
 }
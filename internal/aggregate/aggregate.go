@@ -0,0 +1,274 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package aggregate
+
+import (
+	"cmp"
+	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/search"
+)
+
+const (
+	// MaxBuckets limits time buckets of a series. The step of a query
+	// is widened to a multiple of itself to stay within the limit.
+	MaxBuckets = 500
+	// MaxSeries limits series of a result, those with the most
+	// logs are kept.
+	MaxSeries = 20
+)
+
+// Result is the result of a [Query].
+type Result struct {
+	Query string   `json:"query"`
+	By    []string `json:"by,omitempty"`
+	// Width of time buckets in seconds, zero for a single bucket.
+	Step   internal.Timestamp `json:"step"`
+	Series []Series           `json:"series"`
+	// Number of series left out of the result beyond [MaxSeries].
+	Omitted int `json:"omitted,omitempty"`
+}
+
+// Series is a time series of one group of logs.
+type Series struct {
+	// Values of the query's by fields, empty if a log has none.
+	Group  map[string]string `json:"group,omitempty"`
+	Points []Point           `json:"points"`
+	// Number of logs aggregated.
+	Count int `json:"count"`
+}
+
+// Name returns the group values joined by commas.
+func (s Series) Name(by []string) string {
+	if len(by) == 0 {
+		return "all"
+	}
+	values := make([]string, len(by))
+	for i, field := range by {
+		values[i] = s.Group[field]
+	}
+	return strings.Join(values, ", ")
+}
+
+// Point is the value of a time bucket starting at Timestamp.
+type Point struct {
+	Timestamp internal.Timestamp `json:"timestamp"`
+	Value     float64            `json:"value"`
+}
+
+// sample is a log matched by a query.
+type sample struct {
+	group string
+	ts    internal.Timestamp
+	value float64
+}
+
+// bucket accumulates samples of a time bucket.
+type bucket struct {
+	count    int
+	sum      float64
+	min, max float64
+	values   []float64
+}
+
+// Evaluate aggregates logs matched by q.
+func (q Query) Evaluate(logs iter.Seq[*internal.Log]) Result {
+	groups := make(map[string]map[string]string)
+	var samples []sample
+
+	for log := range logs {
+		value, ok := q.Sample(log)
+		if !ok {
+			continue
+		}
+
+		s := sample{ts: log.Timestamp, value: value}
+
+		if len(q.By) != 0 {
+			values := make([]string, len(q.By))
+			for i, field := range q.By {
+				v, _ := log.Attr(field)
+				values[i], _ = search.Format(v)
+			}
+			s.group = strings.Join(values, "\x00")
+			if _, ok := groups[s.group]; !ok {
+				group := make(map[string]string, len(q.By))
+				for i, field := range q.By {
+					group[field] = values[i]
+				}
+				groups[s.group] = group
+			}
+		}
+		samples = append(samples, s)
+	}
+
+	res := Result{Query: q.String(), By: q.By, Series: []Series{}}
+	if len(samples) == 0 {
+		return res
+	}
+
+	first, last := samples[0].ts, samples[0].ts
+	for _, s := range samples {
+		first, last = min(first, s.ts), max(last, s.ts)
+	}
+
+	// Buckets are numbered by the multiple of the step they start at.
+	step := internal.Timestamp(q.Step.Seconds())
+	index := func(internal.Timestamp) int64 { return 0 }
+	start := func(int64) internal.Timestamp { return first }
+	if step != 0 {
+		if n := math.Floor(float64((last - first) / step)); n >= MaxBuckets {
+			step *= internal.Timestamp(math.Ceil((n + 1) / MaxBuckets))
+		}
+		index = func(ts internal.Timestamp) int64 { return int64(math.Floor(float64(ts / step))) }
+		start = func(i int64) internal.Timestamp { return internal.Timestamp(i) * step }
+		res.Step = step
+	}
+
+	series := make(map[string]map[int64]*bucket)
+	for _, s := range samples {
+		buckets, ok := series[s.group]
+		if !ok {
+			buckets = make(map[int64]*bucket)
+			series[s.group] = buckets
+		}
+
+		i := index(s.ts)
+		b, ok := buckets[i]
+		if !ok {
+			b = &bucket{min: s.value, max: s.value}
+			buckets[i] = b
+		}
+		b.add(s.value, q.Percentile != 0)
+	}
+
+	for group, buckets := range series {
+		out := Series{Group: groups[group]}
+		for i, b := range buckets {
+			out.Count += b.count
+			out.Points = append(out.Points, Point{Timestamp: start(i), Value: q.value(b)})
+		}
+
+		// Buckets without logs count zero.
+		if step != 0 && (q.Func == FuncCount || q.Func == FuncSum) {
+			for i := index(first); i <= index(last); i++ {
+				if _, ok := buckets[i]; !ok {
+					out.Points = append(out.Points, Point{Timestamp: start(i)})
+				}
+			}
+		}
+
+		slices.SortFunc(out.Points, func(a, b Point) int {
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		})
+		res.Series = append(res.Series, out)
+	}
+
+	slices.SortFunc(res.Series, func(a, b Series) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name(q.By), b.Name(q.By))
+	})
+	if len(res.Series) > MaxSeries {
+		res.Omitted = len(res.Series) - MaxSeries
+		res.Series = res.Series[:MaxSeries]
+	}
+	return res
+}
+
+// Sample reports whether log is matched by q and returns the value of
+// its field. Values of logs counted by count without a field are zero.
+func (q Query) Sample(log *internal.Log) (float64, bool) {
+	if !q.where.Empty() && !q.where.Match(log) {
+		return 0, false
+	}
+	if q.Field == "" {
+		return 0, true
+	}
+
+	// Unset top-level fields are empty.
+	v, ok := log.Attr(q.Field)
+	if !ok || v == nil || v == "" {
+		return 0, false
+	}
+	value, ok := number(v)
+	return value, ok || q.Func == FuncCount
+}
+
+// Aggregate returns the aggregate of values sampled from logs by
+// [Query.Sample], or false if there are none and q is not a count.
+func (q Query) Aggregate(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, q.Func == FuncCount
+	}
+
+	b := &bucket{min: values[0], max: values[0]}
+	for _, v := range values {
+		b.add(v, q.Percentile != 0)
+	}
+	return q.value(b), true
+}
+
+// add adds a sample value to b, keeping it for percentiles if keep is set.
+func (b *bucket) add(value float64, keep bool) {
+	b.count++
+	b.sum += value
+	b.min, b.max = min(b.min, value), max(b.max, value)
+	if keep {
+		b.values = append(b.values, value)
+	}
+}
+
+// value returns the aggregate of b.
+func (q Query) value(b *bucket) float64 {
+	switch q.Func {
+	case FuncCount:
+		return float64(b.count)
+	case FuncSum:
+		return b.sum
+	case FuncAvg:
+		return b.sum / float64(b.count)
+	case FuncMin:
+		return b.min
+	case FuncMax:
+		return b.max
+	default:
+		slices.Sort(b.values)
+		return percentile(b.values, q.Percentile)
+	}
+}
+
+// percentile returns the p-th percentile of sorted values,
+// interpolating linearly between the closest ranks.
+func percentile(values []float64, p float64) float64 {
+	rank := p / 100 * float64(len(values)-1)
+	lo := int(math.Floor(rank))
+	hi := min(lo+1, len(values)-1)
+	return values[lo] + (values[hi]-values[lo])*(rank-float64(lo))
+}
+
+// number converts an attribute value to a number.
+// Strings holding a number are converted as well.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case internal.Timestamp:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
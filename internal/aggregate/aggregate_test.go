@@ -0,0 +1,196 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package aggregate
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery(`P99.9( attrs.latency_ms ) BY attrs.route, level Every 1m where level:error "timed out"`)
+	require.NoError(t, err)
+	assert.Equal(t, "p99.9", q.Func)
+	assert.InDelta(t, 99.9, q.Percentile, 1e-9)
+	assert.Equal(t, "attrs.latency_ms", q.Field)
+	assert.Equal(t, []string{"attrs.route", "level"}, q.By)
+	assert.Equal(t, time.Minute, q.Step)
+	assert.Equal(t, `level:error "timed out"`, q.Where)
+	assert.Equal(t, `p99.9(attrs.latency_ms) by attrs.route, level every 1m0s where level:error "timed out"`, q.String())
+
+	q, err = ParseQuery("count()")
+	require.NoError(t, err)
+	assert.Equal(t, Query{Func: FuncCount}, q)
+
+	for _, s := range []string{
+		"",
+		"level",
+		"count",
+		"median(x)",
+		"p0(x)",
+		"p101(x)",
+		"pnan(x)",
+		"pinf(x)",
+		"avg()",
+		"p95()",
+		"count() by",
+		"count() every",
+		"count() every 0s",
+		"count() every 10",
+		"count() every 1s 2s",
+		"count() by level by id",
+		"count() level",
+	} {
+		_, err := ParseQuery(s)
+		assert.ErrorIs(t, err, internal.ErrInvalidAggregateQuery, s)
+	}
+}
+
+func newLog(ts float64, level string, attrs map[string]any) *internal.Log {
+	return &internal.Log{
+		Timestamp: internal.Timestamp(ts),
+		Level:     level,
+		Message:   level + " message",
+		Attrs:     attrs,
+	}
+}
+
+func evaluate(t *testing.T, query string, logs ...*internal.Log) Result {
+	t.Helper()
+	q, err := ParseQuery(query)
+	require.NoError(t, err)
+	return q.Evaluate(slices.Values(logs))
+}
+
+func TestEvaluate_CountByLevel(t *testing.T) {
+	res := evaluate(t, "count() by level every 10s",
+		newLog(101, "info", nil),
+		newLog(105, "info", nil),
+		newLog(109, "error", nil),
+		newLog(131, "info", nil),
+	)
+
+	assert.Equal(t, Result{
+		Query: "count() by level every 10s",
+		By:    []string{"level"},
+		Step:  10,
+		Series: []Series{
+			{
+				Group: map[string]string{"level": "info"},
+				Points: []Point{
+					{Timestamp: 100, Value: 2},
+					{Timestamp: 110},
+					{Timestamp: 120},
+					{Timestamp: 130, Value: 1},
+				},
+				Count: 3,
+			},
+			{
+				Group: map[string]string{"level": "error"},
+				Points: []Point{
+					{Timestamp: 100, Value: 1},
+					{Timestamp: 110},
+					{Timestamp: 120},
+					{Timestamp: 130},
+				},
+				Count: 1,
+			},
+		},
+	}, res)
+	assert.Equal(t, "info", res.Series[0].Name([]string{"level"}))
+}
+
+func TestEvaluate_Functions(t *testing.T) {
+	var logs []*internal.Log
+	for i := 1; i <= 100; i++ {
+		route := "/a"
+		if i%2 == 0 {
+			route = "/b"
+		}
+		logs = append(logs, newLog(float64(i), "info", map[string]any{
+			"latency_ms": float64(i),
+			"route":      route,
+		}))
+	}
+	logs = append(logs,
+		newLog(1, "info", map[string]any{"latency_ms": "1000", "route": "/c"}),
+		newLog(1, "info", map[string]any{"latency_ms": "slow", "route": "/c"}),
+		newLog(1, "info", nil),
+	)
+
+	for query, want := range map[string]float64{
+		"count()":                 103,
+		"count(attrs.latency_ms)": 102,
+		"sum(attrs.latency_ms)":   6050,
+		"avg(attrs.latency_ms)":   6050.0 / 101,
+		"min(attrs.latency_ms)":   1,
+		"max(attrs.latency_ms)":   1000,
+		"p50(attrs.latency_ms)":   51,
+		"p100(attrs.latency_ms)":  1000,
+	} {
+		res := evaluate(t, query, logs...)
+		require.Len(t, res.Series, 1, query)
+		require.Len(t, res.Series[0].Points, 1, query)
+		assert.InDelta(t, want, res.Series[0].Points[0].Value, 1e-9, query)
+		assert.Equal(t, internal.Timestamp(1), res.Series[0].Points[0].Timestamp, query)
+	}
+
+	res := evaluate(t, "p95(attrs.latency_ms) by attrs.route where attrs.route:/a", logs...)
+	require.Len(t, res.Series, 1)
+	assert.Equal(t, map[string]string{"attrs.route": "/a"}, res.Series[0].Group)
+	assert.InDelta(t, 94.1, res.Series[0].Points[0].Value, 1e-9)
+}
+
+func TestEvaluate_Limits(t *testing.T) {
+	var logs []*internal.Log
+	for i := range MaxSeries + 5 {
+		for range i + 1 {
+			logs = append(logs, newLog(float64(i*1000), "info", map[string]any{"n": strconv.Itoa(i)}))
+		}
+	}
+
+	res := evaluate(t, "count() by attrs.n every 1s", logs...)
+	assert.Equal(t, 5, res.Omitted)
+	require.Len(t, res.Series, MaxSeries)
+	assert.Equal(t, map[string]string{"attrs.n": strconv.Itoa(MaxSeries + 4)}, res.Series[0].Group)
+	assert.Greater(t, res.Step, internal.Timestamp(1))
+	assert.LessOrEqual(t, len(res.Series[0].Points), MaxBuckets)
+
+	res = evaluate(t, "count()")
+	assert.Empty(t, res.Series)
+}
+
+func TestQuery_SampleAggregate(t *testing.T) {
+	q, err := ParseQuery("p50(attrs.ms) where level:error")
+	require.NoError(t, err)
+
+	_, ok := q.Sample(newLog(0, "info", map[string]any{"ms": 1.0}))
+	assert.False(t, ok)
+	_, ok = q.Sample(newLog(0, "error", nil))
+	assert.False(t, ok)
+	v, ok := q.Sample(newLog(0, "error", map[string]any{"ms": "12.5"}))
+	assert.True(t, ok)
+	assert.InDelta(t, 12.5, v, 1e-9)
+
+	values := []float64{30, 10, 20}
+	v, ok = q.Aggregate(values)
+	assert.True(t, ok)
+	assert.InDelta(t, 20, v, 1e-9)
+	assert.Equal(t, []float64{30, 10, 20}, values)
+	_, ok = q.Aggregate(nil)
+	assert.False(t, ok)
+
+	q, err = ParseQuery("count()")
+	require.NoError(t, err)
+	v, ok = q.Aggregate(nil)
+	assert.True(t, ok)
+	assert.Zero(t, v)
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package aggregate evaluates aggregation queries over logs, such as
+// "count() by level every 10s" or "p95(attrs.latency_ms) by attrs.route".
+package aggregate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/search"
+)
+
+// Aggregation functions. Percentiles are written as pNN, e.g. p95 or p99.9.
+const (
+	FuncCount = "count"
+	FuncSum   = "sum"
+	FuncAvg   = "avg"
+	FuncMin   = "min"
+	FuncMax   = "max"
+)
+
+// Query is a parsed aggregation query:
+//
+//	func(field) [by field, ...] [every duration] [where search query]
+//
+// Fields are flattened attribute paths as accepted by [internal.Log.Attr].
+// Only count takes no field. The where clause filters logs with the
+// syntax of [search.ParseQuery].
+type Query struct {
+	Func string
+	// Percentile in (0, 100] if Func is a percentile.
+	Percentile float64
+	Field      string
+	By         []string
+	// Width of time buckets, or zero for a single bucket.
+	Step  time.Duration
+	Where string
+
+	where search.Query
+}
+
+var (
+	callRe  = regexp.MustCompile(`^\s*([A-Za-z]\w*(?:\.\d+)?)\s*\(\s*([^()\s]*)\s*\)`)
+	whereRe = regexp.MustCompile(`(?i)(?:^|\s)where(?:\s|$)`)
+)
+
+// ParseQuery parses an aggregation query.
+func ParseQuery(s string) (Query, error) {
+	var q Query
+
+	m := callRe.FindStringSubmatch(s)
+	if m == nil {
+		return q, fmt.Errorf("%w: expected func(field) in %q", internal.ErrInvalidAggregateQuery, s)
+	}
+	rest := s[len(m[0]):]
+
+	q.Func, q.Field = strings.ToLower(m[1]), m[2]
+	switch q.Func {
+	case FuncCount:
+	case FuncSum, FuncAvg, FuncMin, FuncMax:
+		if q.Field == "" {
+			return q, fmt.Errorf("%w: %s requires a field", internal.ErrInvalidAggregateQuery, q.Func)
+		}
+	default:
+		// Written so that NaN is rejected too.
+		p, err := strconv.ParseFloat(strings.TrimPrefix(q.Func, "p"), 64)
+		if !strings.HasPrefix(q.Func, "p") || err != nil || !(p > 0 && p <= 100) {
+			return q, fmt.Errorf("%w: unknown function %q", internal.ErrInvalidAggregateQuery, m[1])
+		}
+		if q.Field == "" {
+			return q, fmt.Errorf("%w: %s requires a field", internal.ErrInvalidAggregateQuery, q.Func)
+		}
+		q.Percentile = p
+	}
+
+	if loc := whereRe.FindStringIndex(rest); loc != nil {
+		q.Where = strings.TrimSpace(rest[loc[1]:])
+		q.where = search.ParseQuery(q.Where)
+		rest = rest[:loc[0]]
+	}
+
+	words := strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	for i := 0; i < len(words); i++ {
+		switch strings.ToLower(words[i]) {
+		case "by":
+			if q.By != nil {
+				return q, fmt.Errorf("%w: repeated by", internal.ErrInvalidAggregateQuery)
+			}
+			for i+1 < len(words) && !keyword(words[i+1]) {
+				i++
+				q.By = append(q.By, words[i])
+			}
+			if q.By == nil {
+				return q, fmt.Errorf("%w: by requires a field", internal.ErrInvalidAggregateQuery)
+			}
+		case "every":
+			if q.Step != 0 || i+1 == len(words) {
+				return q, fmt.Errorf("%w: every requires a single duration", internal.ErrInvalidAggregateQuery)
+			}
+			i++
+			step, err := time.ParseDuration(words[i])
+			if err != nil || step <= 0 {
+				return q, fmt.Errorf("%w: invalid duration %q", internal.ErrInvalidAggregateQuery, words[i])
+			}
+			q.Step = step
+		default:
+			return q, fmt.Errorf("%w: unexpected %q", internal.ErrInvalidAggregateQuery, words[i])
+		}
+	}
+
+	return q, nil
+}
+
+// keyword reports whether word is a keyword of the query syntax.
+func keyword(word string) bool {
+	return strings.EqualFold(word, "by") || strings.EqualFold(word, "every")
+}
+
+// String formats q in the syntax accepted by [ParseQuery].
+func (q Query) String() string {
+	var b strings.Builder
+	b.WriteString(q.Func + "(" + q.Field + ")")
+	if len(q.By) != 0 {
+		b.WriteString(" by " + strings.Join(q.By, ", "))
+	}
+	if q.Step != 0 {
+		b.WriteString(" every " + q.Step.String())
+	}
+	if q.Where != "" {
+		b.WriteString(" where " + q.Where)
+	}
+	return b.String()
+}
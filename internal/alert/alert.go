@@ -0,0 +1,169 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package alert evaluates rules over logs as they are ingested and
+// notifies webhooks when alerts fire and resolve.
+package alert
+
+import (
+	"sync"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// States of an alert.
+const (
+	// The rule's comparison does not hold.
+	StateInactive = "inactive"
+	// The comparison holds, but not for as long as the rule requires.
+	StatePending = "pending"
+	// The comparison has held for as long as the rule requires.
+	StateFiring = "firing"
+	// The comparison stopped holding after the alert fired.
+	StateResolved = "resolved"
+)
+
+// Alert is the state of the alert of a rule.
+type Alert struct {
+	Rule      string  `json:"rule"`
+	Query     string  `json:"query"`
+	Level     string  `json:"level,omitempty"`
+	Op        string  `json:"op"`
+	Threshold float64 `json:"threshold"`
+	Window    string  `json:"window"`
+
+	State string `json:"state"`
+	// Query value at the last evaluation, unset if there were
+	// no logs to aggregate.
+	Value  float64 `json:"value"`
+	NoData bool    `json:"no_data,omitempty"`
+
+	// When the comparison started holding, the alert fired and resolved.
+	ActiveAt    time.Time `json:"active_at,omitzero"`
+	FiredAt     time.Time `json:"fired_at,omitzero"`
+	ResolvedAt  time.Time `json:"resolved_at,omitzero"`
+	EvaluatedAt time.Time `json:"evaluated_at,omitzero"`
+
+	// Error notifying webhooks the alert fired or resolved, if it failed.
+	NotifyError string `json:"notify_error,omitempty"`
+}
+
+// Notification is an alert that fired or resolved, with the URLs of
+// webhooks to notify.
+type Notification struct {
+	Alert
+	Webhooks []string `json:"-"`
+}
+
+// Engine evaluates rules over logs added to the store, with which it
+// is kept in step as an [internal.LogIndex]. Windows of rules hold logs
+// by when they were ingested, so logs removed from the store stay in
+// them. It is safe for concurrent use.
+type Engine struct {
+	mu    sync.Mutex
+	rules []*rule
+	now   func() time.Time
+}
+
+// NewEngine returns an engine of the configured rules.
+func NewEngine(cfg Config) (*Engine, error) {
+	e := &Engine{now: time.Now}
+	for i, rc := range cfg.Rules {
+		r, err := newRule(i, rc, cfg.Webhooks)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+// Len returns the number of rules.
+func (e *Engine) Len() int {
+	return len(e.rules)
+}
+
+// Add adds log to the windows of the rules matching it.
+func (e *Engine) Add(_ int, log *internal.Log) {
+	if len(e.rules) == 0 {
+		return
+	}
+
+	now := e.now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range e.rules {
+		r.observe(now, log)
+	}
+}
+
+// Remove does nothing, logs leave windows of rules as time passes.
+func (e *Engine) Remove(int, *internal.Log) {}
+
+// Evaluate evaluates the rules and returns notifications of the alerts
+// that fired or resolved since the last evaluation.
+func (e *Engine) Evaluate() []Notification {
+	now := e.now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var ns []Notification
+	for _, r := range e.rules {
+		a := &r.alert
+		value, ok := r.value(now)
+		a.Value, a.NoData, a.EvaluatedAt = value, !ok, now
+
+		if ok && r.holds(value) {
+			if a.State != StatePending && a.State != StateFiring {
+				a.State, a.ActiveAt = StatePending, now
+			}
+			if a.State == StatePending && now.Sub(a.ActiveAt) >= r.pending {
+				a.State, a.FiredAt, a.ResolvedAt = StateFiring, now, time.Time{}
+				a.NotifyError = ""
+				ns = append(ns, Notification{Alert: *a, Webhooks: r.webhooks})
+			}
+			continue
+		}
+
+		switch a.State {
+		case StatePending:
+			a.State, a.ActiveAt = StateInactive, time.Time{}
+		case StateFiring:
+			a.State, a.ResolvedAt = StateResolved, now
+			a.NotifyError = ""
+			ns = append(ns, Notification{Alert: *a, Webhooks: r.webhooks})
+		}
+	}
+	return ns
+}
+
+// Alerts returns alerts of the rules, in the order they are configured.
+func (e *Engine) Alerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]Alert, len(e.rules))
+	for i, r := range e.rules {
+		alerts[i] = r.alert
+	}
+	return alerts
+}
+
+// SetNotifyError records the error notifying webhooks of the alert of
+// the named rule, or clears it if err is nil.
+func (e *Engine) SetNotifyError(rule string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range e.rules {
+		if r.alert.Rule != rule {
+			continue
+		}
+		r.alert.NotifyError = ""
+		if err != nil {
+			r.alert.NotifyError = err.Error()
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEngine returns an engine of rules and a function advancing its clock.
+func newTestEngine(t *testing.T, cfg Config) (*Engine, func(time.Duration)) {
+	t.Helper()
+	e, err := NewEngine(cfg)
+	require.NoError(t, err)
+
+	now := time.Unix(1_700_000_000, 0)
+	e.now = func() time.Time { return now }
+	return e, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{
+		"webhooks": ["http://a"],
+		"rules": [{"name": "errors", "query": "count()", "level": "err", "window": "1m", "threshold": 10}]
+	}`))
+	require.NoError(t, err)
+	require.Len(t, cfg.Rules, 1)
+
+	e, err := NewEngine(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 1, e.Len())
+	assert.Equal(t, []Alert{{
+		Rule: "errors", Query: "count()", Level: internal.LevelError, Op: OpAbove,
+		Threshold: 10, Window: "1m0s", State: StateInactive, NoData: true,
+	}}, e.Alerts())
+
+	_, err = ParseConfig([]byte(`{"rules": {}}`))
+	assert.ErrorIs(t, err, internal.ErrInvalidAlertConfig)
+
+	for _, rc := range []RuleConfig{
+		{Query: "median(x)", Window: "1m"},
+		{Query: "count() by level", Window: "1m"},
+		{Query: "count() every 1s", Window: "1m"},
+		{Query: "count()", Window: "1m", Level: "loud"},
+		{Query: "count()"},
+		{Query: "count()", Window: "-1m"},
+		{Query: "count()", Window: "1m", For: "soon"},
+		{Query: "count()", Window: "1m", Op: "=="},
+	} {
+		_, err := NewEngine(Config{Rules: []RuleConfig{rc}})
+		assert.ErrorIs(t, err, internal.ErrInvalidAlertConfig, rc)
+	}
+}
+
+func TestEngine_CountByLevel(t *testing.T) {
+	e, advance := newTestEngine(t, Config{
+		Webhooks: []string{"http://a"},
+		Rules: []RuleConfig{{
+			Name:      "x-errors",
+			Query:     "count() where id.producer_id:x",
+			Level:     "error",
+			Window:    "1m",
+			Threshold: 2,
+			Webhooks:  []string{"http://b"},
+		}},
+	})
+
+	for _, l := range []internal.Log{
+		{ID: internal.LogID{ProducerID: "x"}, Level: "ERROR"},
+		{ID: internal.LogID{ProducerID: "x"}, Level: "fatal"},
+		{ID: internal.LogID{ProducerID: "x"}, Level: "info"},
+		{ID: internal.LogID{ProducerID: "x"}, Level: "custom"},
+		{ID: internal.LogID{ProducerID: "y"}, Level: "error"},
+	} {
+		e.Add(0, &l)
+	}
+
+	assert.Empty(t, e.Evaluate())
+	assert.Equal(t, StateInactive, e.Alerts()[0].State)
+	assert.InDelta(t, 2, e.Alerts()[0].Value, 1e-9)
+	assert.False(t, e.Alerts()[0].NoData)
+
+	advance(30 * time.Second)
+	e.Add(0, &internal.Log{ID: internal.LogID{ProducerID: "x"}, Level: "error"})
+	ns := e.Evaluate()
+	require.Len(t, ns, 1)
+	assert.Equal(t, StateFiring, ns[0].State)
+	assert.InDelta(t, 3, ns[0].Value, 1e-9)
+	assert.Equal(t, []string{"http://a", "http://b"}, ns[0].Webhooks)
+	assert.Empty(t, e.Evaluate())
+
+	// The first two logs leave the window.
+	advance(31 * time.Second)
+	ns = e.Evaluate()
+	require.Len(t, ns, 1)
+	assert.Equal(t, StateResolved, ns[0].State)
+	assert.InDelta(t, 1, ns[0].Value, 1e-9)
+	assert.False(t, ns[0].ResolvedAt.IsZero())
+
+	advance(time.Minute)
+	assert.Empty(t, e.Evaluate())
+	a := e.Alerts()[0]
+	assert.Equal(t, StateResolved, a.State)
+	assert.Zero(t, a.Value)
+}
+
+func TestEngine_PercentileFor(t *testing.T) {
+	e, advance := newTestEngine(t, Config{
+		Rules: []RuleConfig{{
+			Query:     "p99(duration) where source_function:DB.Query",
+			Window:    "5m",
+			Threshold: 0.2,
+			For:       "30s",
+		}},
+	})
+
+	call := func(fn string, d float64) {
+		e.Add(0, &internal.Log{SourceFunction: fn, FunctionCallStartedAt: 10, FunctionCallEndedAt: internal.Timestamp(10 + d)})
+	}
+
+	assert.Empty(t, e.Evaluate())
+	assert.True(t, e.Alerts()[0].NoData)
+
+	call("DB.Query", 0.5)
+	call("Cache.Get", 0.05)
+	assert.Empty(t, e.Evaluate())
+	a := e.Alerts()[0]
+	assert.Equal(t, "rule-0", a.Rule)
+	assert.Equal(t, StatePending, a.State)
+	assert.InDelta(t, 0.5, a.Value, 1e-9)
+
+	advance(10 * time.Second)
+	for range 200 {
+		call("DB.Query", 0.01)
+	}
+	// The value drops below the threshold before the alert fires.
+	assert.Empty(t, e.Evaluate())
+	assert.Equal(t, StateInactive, e.Alerts()[0].State)
+	assert.True(t, e.Alerts()[0].ActiveAt.IsZero())
+
+	for range 10 {
+		call("DB.Query", 1)
+	}
+	assert.Empty(t, e.Evaluate())
+	advance(29 * time.Second)
+	assert.Empty(t, e.Evaluate())
+	advance(time.Second)
+	ns := e.Evaluate()
+	require.Len(t, ns, 1)
+	assert.Equal(t, StateFiring, ns[0].State)
+	assert.Equal(t, "rule-0", ns[0].Rule)
+}
+
+func TestEngine_SetNotifyError(t *testing.T) {
+	e, _ := newTestEngine(t, Config{Rules: []RuleConfig{{Name: "r", Query: "count()", Window: "1m", Op: OpBelow, Threshold: 1}}})
+
+	ns := e.Evaluate()
+	require.Len(t, ns, 1)
+	e.SetNotifyError("r", assert.AnError)
+	assert.Equal(t, assert.AnError.Error(), e.Alerts()[0].NotifyError)
+	e.SetNotifyError("r", nil)
+	assert.Empty(t, e.Alerts()[0].NotifyError)
+}
+
+func TestNotify(t *testing.T) {
+	received := make(chan Notification, 1)
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := status
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var n Notification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		received <- n
+		w.WriteHeader(code)
+	}))
+	defer srv.Close()
+
+	e, _ := newTestEngine(t, Config{
+		Webhooks: []string{srv.URL},
+		Rules:    []RuleConfig{{Name: "quiet", Query: "count()", Window: "1m", Op: OpBelowOrEqual, Threshold: 0}},
+	})
+	ns := e.Evaluate()
+	require.Len(t, ns, 1)
+
+	require.NoError(t, Notify(context.Background(), srv.Client(), ns[0].Webhooks[0], ns[0]))
+	n := <-received
+	assert.Equal(t, "quiet", n.Rule)
+	assert.Equal(t, StateFiring, n.State)
+	assert.Equal(t, "count()", n.Query)
+	assert.True(t, n.FiredAt.Equal(ns[0].FiredAt))
+
+	status = http.StatusInternalServerError
+	err := Notify(context.Background(), srv.Client(), srv.URL, ns[0])
+	assert.ErrorContains(t, err, "500")
+	<-received
+
+	srv.Close()
+	assert.Error(t, Notify(context.Background(), srv.Client(), srv.URL, ns[0]))
+}
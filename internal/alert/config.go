@@ -0,0 +1,73 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// Config is an alerting configuration, as read from a JSON file:
+//
+//	{
+//		"webhooks": ["http://localhost:9000/alerts"],
+//		"rules": [
+//			{"name": "x-errors", "query": "count() where id.producer_id:x", "level": "error", "window": "1m", "threshold": 10},
+//			{"name": "slow-queries", "query": "p99(duration) where source_function:DB.Query", "window": "5m", "threshold": 0.2, "for": "30s"}
+//		]
+//	}
+type Config struct {
+	// URLs of webhooks notified of alerts of all rules.
+	Webhooks []string     `json:"webhooks"`
+	Rules    []RuleConfig `json:"rules"`
+}
+
+// RuleConfig configures a rule alerting when the value of an aggregation
+// query over logs ingested within a sliding window crosses a threshold.
+type RuleConfig struct {
+	Name string `json:"name"`
+
+	// Aggregation query in the syntax of [aggregate.ParseQuery], without
+	// by and every clauses, such as "count() where level:error".
+	Query string `json:"query"`
+	// Minimum level of logs aggregated, such as "warn" for warnings and
+	// more severe logs. Logs of all levels are aggregated if empty.
+	Level string `json:"level"`
+	// Duration of the window, such as "1m".
+	Window string `json:"window"`
+
+	// Comparison of the query value to Threshold, one of ">", ">=", "<"
+	// and "<=", ">" if empty.
+	Op        string  `json:"op"`
+	Threshold float64 `json:"threshold"`
+	// How long the comparison must hold before the alert fires,
+	// such as "30s". It fires right away if empty.
+	For string `json:"for"`
+
+	// URLs of webhooks notified of alerts of the rule,
+	// in addition to those of the config.
+	Webhooks []string `json:"webhooks"`
+}
+
+// ParseConfig parses a JSON encoded alerting configuration.
+func ParseConfig(data []byte) (Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%w: %w", internal.ErrInvalidAlertConfig, err)
+	}
+	return cfg, nil
+}
+
+// LoadConfig reads the alerting configuration file at path.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read alert config: %w", err)
+	}
+	return ParseConfig(data)
+}
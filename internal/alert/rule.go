@@ -0,0 +1,163 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package alert
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/aggregate"
+)
+
+// Comparisons of query values to thresholds.
+const (
+	OpAbove        = ">"
+	OpAboveOrEqual = ">="
+	OpBelow        = "<"
+	OpBelowOrEqual = "<="
+)
+
+// MaxSamples limits values of logs a rule keeps within its window,
+// the oldest are dropped beyond it.
+const MaxSamples = 100_000
+
+// sample is the value of a log matched by a rule.
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+// rule is a compiled [RuleConfig] and the state of its alert.
+type rule struct {
+	query aggregate.Query
+	// Index of the minimum level in [internal.Levels], -1 for all levels.
+	level     int
+	window    time.Duration
+	pending   time.Duration
+	op        string
+	threshold float64
+	webhooks  []string
+
+	// Samples ordered by when they were ingested.
+	samples []sample
+	alert   Alert
+}
+
+func newRule(i int, cfg RuleConfig, webhooks []string) (*rule, error) {
+	name := cfg.Name
+	if name == "" {
+		name = "rule-" + strconv.Itoa(i)
+	}
+
+	q, err := aggregate.ParseQuery(cfg.Query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: rule %s: %w", internal.ErrInvalidAlertConfig, name, err)
+	}
+	if len(q.By) != 0 || q.Step != 0 {
+		return nil, fmt.Errorf("%w: rule %s: query cannot group by fields or time", internal.ErrInvalidAlertConfig, name)
+	}
+
+	r := &rule{
+		query:     q,
+		level:     -1,
+		op:        cfg.Op,
+		threshold: cfg.Threshold,
+		webhooks:  slices.Concat(webhooks, cfg.Webhooks),
+	}
+
+	if cfg.Level != "" {
+		level := internal.NormalizeLevel(cfg.Level)
+		if level == internal.LevelOther {
+			return nil, fmt.Errorf("%w: rule %s: unknown level %q", internal.ErrInvalidAlertConfig, name, cfg.Level)
+		}
+		r.level = slices.Index(internal.Levels, level)
+	}
+
+	if r.window, err = time.ParseDuration(cfg.Window); err != nil || r.window <= 0 {
+		return nil, fmt.Errorf("%w: rule %s: invalid window %q", internal.ErrInvalidAlertConfig, name, cfg.Window)
+	}
+	if cfg.For != "" {
+		if r.pending, err = time.ParseDuration(cfg.For); err != nil || r.pending < 0 {
+			return nil, fmt.Errorf("%w: rule %s: invalid for %q", internal.ErrInvalidAlertConfig, name, cfg.For)
+		}
+	}
+
+	switch r.op {
+	case "":
+		r.op = OpAbove
+	case OpAbove, OpAboveOrEqual, OpBelow, OpBelowOrEqual:
+	default:
+		return nil, fmt.Errorf("%w: rule %s: unknown op %q", internal.ErrInvalidAlertConfig, name, cfg.Op)
+	}
+
+	r.alert = Alert{
+		Rule:      name,
+		Query:     q.String(),
+		Level:     cfg.Level,
+		Op:        r.op,
+		Threshold: r.threshold,
+		Window:    r.window.String(),
+		State:     StateInactive,
+		NoData:    true,
+	}
+	if r.level >= 0 {
+		r.alert.Level = internal.Levels[r.level]
+	}
+	return r, nil
+}
+
+// observe adds the value of log to the window if the rule matches log.
+func (r *rule) observe(now time.Time, log *internal.Log) {
+	if r.level >= 0 {
+		level := internal.NormalizeLevel(log.Level)
+		if level == internal.LevelOther || slices.Index(internal.Levels, level) < r.level {
+			return
+		}
+	}
+
+	value, ok := r.query.Sample(log)
+	if !ok {
+		return
+	}
+
+	if len(r.samples) == MaxSamples {
+		r.samples = r.samples[1:]
+	}
+	r.samples = append(r.samples, sample{at: now, value: value})
+}
+
+// value returns the query value over samples within the window ending
+// at now, or false if there are no samples to aggregate.
+func (r *rule) value(now time.Time) (float64, bool) {
+	start := now.Add(-r.window)
+	i := sort.Search(len(r.samples), func(i int) bool {
+		return r.samples[i].at.After(start)
+	})
+	r.samples = r.samples[i:]
+
+	values := make([]float64, len(r.samples))
+	for i, s := range r.samples {
+		values[i] = s.value
+	}
+	return r.query.Aggregate(values)
+}
+
+// holds reports whether value compares to the threshold as configured.
+func (r *rule) holds(value float64) bool {
+	switch r.op {
+	case OpAboveOrEqual:
+		return value >= r.threshold
+	case OpBelow:
+		return value < r.threshold
+	case OpBelowOrEqual:
+		return value <= r.threshold
+	default:
+		return value > r.threshold
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package anomaly
+
+import "math"
+
+// baseline is an exponentially weighted moving average and variance
+// of observations of a series.
+type baseline struct {
+	n        int
+	mean     float64
+	variance float64
+}
+
+// deviation returns how many standard deviations x is from the mean,
+// using a standard deviation of at least minStdDev, and the standard
+// deviation used.
+func (b *baseline) deviation(x, minStdDev float64) (sigma, stdDev float64) {
+	stdDev = max(math.Sqrt(b.variance), minStdDev)
+	if stdDev == 0 {
+		return 0, 0
+	}
+	return (x - b.mean) / stdDev, stdDev
+}
+
+// observe updates the baseline with x, weighing it by alpha.
+func (b *baseline) observe(x, alpha float64) {
+	b.n++
+	if b.n == 1 {
+		b.mean = x
+		return
+	}
+
+	diff := x - b.mean
+	b.mean += alpha * diff
+	b.variance = (1 - alpha) * (b.variance + alpha*diff*diff)
+}
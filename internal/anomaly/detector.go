@@ -0,0 +1,304 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package anomaly detects anomalous log rates of producers and
+// latencies of functions against baselines learned from the stream.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
+)
+
+// Kinds of anomalies.
+const (
+	// The number of logs of a producer in an interval.
+	KindRate = "rate"
+	// The duration of a function call.
+	KindLatency = "latency"
+)
+
+const (
+	// MaxAnomalies limits anomalies kept, the oldest are dropped beyond it.
+	MaxAnomalies = 1000
+	// MaxGap limits intervals without logs of a producer counted as such.
+	// Longer gaps are counted as MaxGap intervals.
+	MaxGap = 60
+	// MinLatencyDeviation is the minimum standard deviation of latencies
+	// as a fraction of their mean, so that slight changes in latencies of
+	// calls that hardly vary are not anomalies.
+	MinLatencyDeviation = 0.1
+)
+
+// Config configures a [Detector].
+type Config struct {
+	// Deviation from a baseline, in standard deviations, past which
+	// an observation is an anomaly.
+	Sigma float64
+	// Weight of each observation in baselines, in (0, 1].
+	Alpha float64
+	// Width of intervals logs of producers are counted in.
+	Interval time.Duration
+	// Number of observations a baseline learns from before
+	// observations are checked against it.
+	WarmUp int
+}
+
+// DefaultConfig is the configuration of detectors, unless overridden.
+var DefaultConfig = Config{
+	Sigma:    3,
+	Alpha:    0.1,
+	Interval: 10 * time.Second,
+	WarmUp:   20,
+}
+
+// Anomaly is an observation deviating from its baseline past the
+// configured number of standard deviations.
+type Anomaly struct {
+	Kind string `json:"kind"`
+	// Producer id for rates, function name for latencies.
+	Key string `json:"key"`
+	// Producer of the call, for latencies.
+	ProducerID string `json:"producer_id,omitempty"`
+	// Log of the call, for latencies.
+	LogID *internal.LogID `json:"log_id,omitempty"`
+
+	// Bounds of the interval for rates, of the call for latencies.
+	Start internal.Timestamp `json:"start"`
+	End   internal.Timestamp `json:"end"`
+
+	// Observed and expected values, in logs per interval for rates
+	// and in seconds for latencies.
+	Value    float64 `json:"value"`
+	Expected float64 `json:"expected"`
+	StdDev   float64 `json:"std_dev"`
+	// Deviation of Value from Expected in standard deviations.
+	Sigma float64 `json:"sigma"`
+}
+
+// String describes a.
+func (a Anomaly) String() string {
+	switch a.Kind {
+	case KindRate:
+		return fmt.Sprintf("%s: %g logs, expected %.4g (%+.1f sigma)", a.Key, a.Value, a.Expected, a.Sigma)
+	default:
+		return fmt.Sprintf("%s: %.4gs, expected %.4gs (%+.1f sigma)", a.Key, a.Value, a.Expected, a.Sigma)
+	}
+}
+
+// rate counts logs of a producer in the current interval.
+type rate struct {
+	interval int64
+	count    int
+	baseline baseline
+	// Calls of named functions in the interval.
+	calls []internal.Log
+}
+
+// Detector learns baselines of log rates and latencies from logs added
+// to the store, with which it is kept in step as an [internal.LogIndex].
+// Rates of producers deviating from their baselines either way and
+// latencies of functions exceeding theirs are anomalies. Logs are
+// counted in intervals by their timestamps, so an interval is checked
+// when a producer's first log in a later interval is added. It is safe
+// for concurrent use.
+//
+// Latencies of single calls are checked as logs are added, but their
+// baselines learn from the profiler's per-function statistics of the
+// call trees of each interval of a producer, once it is over.
+//
+// Baselines are exponentially weighted and have no seasonal component,
+// so daily or weekly patterns are learned as drift.
+type Detector struct {
+	mu  sync.Mutex
+	cfg Config
+
+	rates     map[string]*rate
+	latencies map[string]*baseline
+
+	// Anomalies in the order they are detected, the oldest at head.
+	anomalies []Anomaly
+	head      int
+
+	onAnomaly func(a Anomaly)
+}
+
+// NewDetector returns a detector configured by cfg. Unset fields of cfg
+// are those of [DefaultConfig]. onAnomaly, if not nil, is called with
+// each anomaly when it is detected, with the store locked.
+func NewDetector(cfg Config, onAnomaly func(a Anomaly)) *Detector {
+	if cfg.Sigma <= 0 {
+		cfg.Sigma = DefaultConfig.Sigma
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = DefaultConfig.Alpha
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultConfig.Interval
+	}
+	if cfg.WarmUp <= 0 {
+		cfg.WarmUp = DefaultConfig.WarmUp
+	}
+
+	return &Detector{
+		cfg:       cfg,
+		rates:     make(map[string]*rate),
+		latencies: make(map[string]*baseline),
+		onAnomaly: onAnomaly,
+	}
+}
+
+// Config returns the configuration of d.
+func (d *Detector) Config() Config {
+	return d.cfg
+}
+
+// Add observes log. Latencies are only learned of metric logs naming
+// their source function.
+func (d *Detector) Add(_ int, log *internal.Log) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.observeRate(log)
+	if log.Type() == internal.LogTypeMetric {
+		d.observeLatency(log)
+	}
+}
+
+// Remove does nothing, baselines keep what they learned from logs
+// removed from the store.
+func (d *Detector) Remove(int, *internal.Log) {}
+
+func (d *Detector) observeRate(log *internal.Log) {
+	width := d.cfg.Interval.Seconds()
+	interval := int64(math.Floor(float64(log.Timestamp) / width))
+
+	r, ok := d.rates[log.ID.ProducerID]
+	if !ok {
+		d.rates[log.ID.ProducerID] = &rate{interval: interval, count: 1}
+		return
+	}
+
+	// Logs of past intervals are counted in the current one.
+	if interval <= r.interval {
+		r.count++
+		return
+	}
+
+	// Intervals in between had no logs.
+	gap := min(interval-r.interval-1, MaxGap)
+	counts := append(make([]int, 0, gap+1), r.count)
+	for range gap {
+		counts = append(counts, 0)
+	}
+
+	for i, count := range counts {
+		start := float64(r.interval+int64(i)) * width
+		x := float64(count)
+		if r.baseline.n >= d.cfg.WarmUp {
+			// Counts vary at least as much as Poisson distributed ones.
+			sigma, stdDev := r.baseline.deviation(x, math.Sqrt(max(r.baseline.mean, 1)))
+			if math.Abs(sigma) > d.cfg.Sigma {
+				d.report(Anomaly{
+					Kind:     KindRate,
+					Key:      log.ID.ProducerID,
+					Start:    internal.Timestamp(start),
+					End:      internal.Timestamp(start + width),
+					Value:    x,
+					Expected: r.baseline.mean,
+					StdDev:   stdDev,
+					Sigma:    sigma,
+				})
+			}
+		}
+		r.baseline.observe(x, d.cfg.Alpha)
+	}
+
+	d.learnLatencies(r.calls)
+	r.interval, r.count, r.calls = interval, 1, r.calls[:0]
+}
+
+// learnLatencies updates baselines of latencies with the mean duration
+// of calls of each function in the per-function statistics of calls.
+func (d *Detector) learnLatencies(calls []internal.Log) {
+	if len(calls) == 0 {
+		return
+	}
+
+	for _, s := range profiler.ComputeFunctionStats(profiler.BuildCallTree(calls)) {
+		b, ok := d.latencies[s.Name]
+		if !ok {
+			b = &baseline{}
+			d.latencies[s.Name] = b
+		}
+		b.observe(float64(s.Total)/float64(s.Calls), d.cfg.Alpha)
+	}
+}
+
+func (d *Detector) observeLatency(log *internal.Log) {
+	// Keyed by function only, messages may be unbounded in number.
+	name := log.SourceFunction
+	if name == "" {
+		return
+	}
+
+	// Learned from once the interval of the call is over.
+	r := d.rates[log.ID.ProducerID]
+	r.calls = append(r.calls, *log)
+
+	b, ok := d.latencies[name]
+	x := float64(log.FunctionCallEndedAt - log.FunctionCallStartedAt)
+	if ok && b.n >= d.cfg.WarmUp {
+		sigma, stdDev := b.deviation(x, b.mean*MinLatencyDeviation)
+		if sigma > d.cfg.Sigma {
+			id := log.ID
+			d.report(Anomaly{
+				Kind:       KindLatency,
+				Key:        name,
+				ProducerID: log.ID.ProducerID,
+				LogID:      &id,
+				Start:      log.FunctionCallStartedAt,
+				End:        log.FunctionCallEndedAt,
+				Value:      x,
+				Expected:   b.mean,
+				StdDev:     stdDev,
+				Sigma:      sigma,
+			})
+		}
+	}
+}
+
+func (d *Detector) report(a Anomaly) {
+	if len(d.anomalies) < MaxAnomalies {
+		d.anomalies = append(d.anomalies, a)
+	} else {
+		d.anomalies[d.head] = a
+		d.head = (d.head + 1) % MaxAnomalies
+	}
+
+	if d.onAnomaly != nil {
+		d.onAnomaly(a)
+	}
+}
+
+// Anomalies returns anomalies overlapping the range from, to, in the
+// order they were detected. A zero bound leaves that side open.
+func (d *Detector) Anomalies(from, to internal.Timestamp) []Anomaly {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	res := make([]Anomaly, 0)
+	for i := range d.anomalies {
+		a := d.anomalies[(d.head+i)%len(d.anomalies)]
+		if (from == 0 || a.End >= from) && (to == 0 || a.Start <= to) {
+			res = append(res, a)
+		}
+	}
+	return res
+}
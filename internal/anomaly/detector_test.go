@@ -0,0 +1,166 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package anomaly
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline(t *testing.T) {
+	var b baseline
+	for _, x := range []float64{10, 12, 8, 10, 11, 9} {
+		b.observe(x, 0.5)
+	}
+	assert.Equal(t, 6, b.n)
+	assert.InDelta(t, 9.6875, b.mean, 1e-9)
+	assert.Greater(t, b.variance, 0.0)
+
+	sigma, stdDev := b.deviation(b.mean+2*stdDevOf(b), 0)
+	assert.InDelta(t, 2, sigma, 1e-9)
+	assert.InDelta(t, stdDevOf(b), stdDev, 1e-9)
+
+	sigma, stdDev = b.deviation(b.mean-100, 50)
+	assert.InDelta(t, -2, sigma, 1e-9)
+	assert.InDelta(t, 50, stdDev, 1e-9)
+
+	sigma, _ = (&baseline{}).deviation(1, 0)
+	assert.Zero(t, sigma)
+}
+
+func stdDevOf(b baseline) float64 {
+	_, stdDev := b.deviation(b.mean, 0)
+	return stdDev
+}
+
+// logsAt returns n logs of producer spread over the second starting at ts.
+func logsAt(producer string, ts float64, n int) []internal.Log {
+	logs := make([]internal.Log, n)
+	for i := range logs {
+		logs[i] = internal.Log{
+			ID:        internal.LogID{ProducerID: producer, SequenceNumber: i},
+			Timestamp: internal.Timestamp(ts + float64(i)/float64(n+1)),
+		}
+	}
+	return logs
+}
+
+func TestDetector_Rate(t *testing.T) {
+	var reported []Anomaly
+	d := NewDetector(Config{Interval: time.Second, WarmUp: 10}, func(a Anomaly) {
+		reported = append(reported, a)
+	})
+	add := func(logs []internal.Log) {
+		for i := range logs {
+			d.Add(0, &logs[i])
+		}
+	}
+
+	for i := range 30 {
+		add(logsAt("a", float64(100+i), 20+i%3))
+		add(logsAt("b", float64(100+i), 20))
+	}
+	assert.Empty(t, reported)
+
+	// A burst of a.
+	add(logsAt("a", 130, 100))
+	add(logsAt("a", 131, 20))
+	require.Len(t, reported, 1)
+	a := reported[0]
+	assert.Equal(t, KindRate, a.Kind)
+	assert.Equal(t, "a", a.Key)
+	assert.Equal(t, internal.Timestamp(130), a.Start)
+	assert.Equal(t, internal.Timestamp(131), a.End)
+	assert.InDelta(t, 100, a.Value, 1e-9)
+	assert.InDelta(t, 21, a.Expected, 1)
+	assert.Greater(t, a.Sigma, 3.0)
+
+	// b goes quiet for a few intervals.
+	add(logsAt("b", 135, 20))
+	require.Len(t, reported, 2)
+	a = reported[1]
+	assert.Equal(t, "b", a.Key)
+	assert.Equal(t, internal.Timestamp(130), a.Start)
+	assert.Zero(t, a.Value)
+	assert.Less(t, a.Sigma, -3.0)
+
+	assert.Equal(t, reported, d.Anomalies(0, 0))
+	assert.Equal(t, reported, d.Anomalies(130.5, 130.9))
+	assert.Empty(t, d.Anomalies(0, 129))
+}
+
+func TestDetector_Latency(t *testing.T) {
+	d := NewDetector(Config{Interval: time.Second, WarmUp: 5}, nil)
+	assert.InDelta(t, DefaultConfig.Sigma, d.Config().Sigma, 1e-9)
+
+	call := func(seq int, fn string, start, duration float64) {
+		d.Add(0, &internal.Log{
+			ID:                    internal.LogID{ProducerID: "p", SequenceNumber: seq},
+			Timestamp:             internal.Timestamp(start + duration),
+			SourceFunction:        fn,
+			FunctionCallStartedAt: internal.Timestamp(start),
+			FunctionCallEndedAt:   internal.Timestamp(start + duration),
+		})
+	}
+
+	for i := range 20 {
+		call(i, "DB.Query", float64(i), 0.1+float64(i%2)*0.001)
+		call(100+i, "Cache.Get", float64(i), 0.001)
+	}
+	assert.Empty(t, d.Anomalies(0, 0))
+
+	// Faster calls and a slightly slower one are not anomalies.
+	call(200, "DB.Query", 20, 0.01)
+	call(150, "Cache.Get", 20, 0.001)
+	call(151, "Cache.Get", 20.2, 0.0011)
+	call(201, "DB.Query", 21, 0.11)
+	assert.Empty(t, d.Anomalies(0, 0))
+
+	// Baselines learn from per-function statistics of each interval
+	// once it is over, such as the mean latency of calls.
+	assert.Equal(t, 20, d.latencies["DB.Query"].n)
+	assert.Equal(t, 20, d.latencies["Cache.Get"].n)
+	assert.InDelta(t, 0.001005, d.latencies["Cache.Get"].mean, 1e-9)
+
+	call(202, "DB.Query", 22, 0.5)
+	anomalies := d.Anomalies(0, 0)
+	require.Len(t, anomalies, 1)
+	a := anomalies[0]
+	assert.Equal(t, KindLatency, a.Kind)
+	assert.Equal(t, "DB.Query", a.Key)
+	assert.Equal(t, "p", a.ProducerID)
+	assert.Equal(t, &internal.LogID{ProducerID: "p", SequenceNumber: 202}, a.LogID)
+	assert.Equal(t, internal.Timestamp(22), a.Start)
+	assert.InDelta(t, 0.5, a.Value, 1e-9)
+	assert.Contains(t, a.String(), "DB.Query: 0.5s")
+
+	// Calls of unnamed functions are not keyed by their messages.
+	for i := range 30 {
+		d.Add(0, &internal.Log{
+			ID:                    internal.LogID{ProducerID: "p", SequenceNumber: 300 + i},
+			Message:               fmt.Sprintf("call %d", i),
+			FunctionCallStartedAt: 1,
+			FunctionCallEndedAt:   2,
+		})
+	}
+	assert.Len(t, d.latencies, 2)
+}
+
+func TestDetector_MaxAnomalies(t *testing.T) {
+	d := NewDetector(Config{}, nil)
+	for i := range MaxAnomalies + 10 {
+		d.report(Anomaly{Key: "k", Start: internal.Timestamp(i), End: internal.Timestamp(i)})
+	}
+
+	anomalies := d.Anomalies(0, 0)
+	require.Len(t, anomalies, MaxAnomalies)
+	assert.Equal(t, internal.Timestamp(10), anomalies[0].Start)
+	assert.Equal(t, internal.Timestamp(MaxAnomalies+9), anomalies[MaxAnomalies-1].Start)
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package catalog keeps a live catalog of the fields of ingested logs.
+package catalog
+
+import (
+	"cmp"
+	"hash/maphash"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/search"
+)
+
+const (
+	// MaxFields is the maximum number of fields in a catalog,
+	// fields seen after are not cataloged.
+	MaxFields = 10_000
+
+	// TopValues is the number of most frequent values of a field reported.
+	TopValues = 10
+
+	// Values longer than this are not counted for top values.
+	maxValueLength = 256
+	// Number of values counted for top values, more than reported
+	// to make estimates of the reported ones more accurate.
+	topKSize = 4 * TopValues
+)
+
+// Types of field values.
+const (
+	TypeString = "string"
+	TypeNumber = "number"
+	TypeBool   = "bool"
+	TypeTime   = "timestamp"
+)
+
+// Field describes a flattened attribute path seen in logs.
+type Field struct {
+	Path string `json:"path"`
+	// Number of logs the field was seen in, by type of its value.
+	Types map[string]int64 `json:"types"`
+	Count int64            `json:"count"`
+	// Estimated number of distinct values.
+	Cardinality uint64       `json:"cardinality"`
+	TopValues   []ValueCount `json:"top_values"`
+	FirstSeen   time.Time    `json:"first_seen"`
+	LastSeen    time.Time    `json:"last_seen"`
+}
+
+type field struct {
+	types     map[string]int64
+	count     int64
+	distinct  hll
+	top       *topK
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// Catalog catalogs fields of logs added to it. It is an [internal.LogIndex]
+// of the logs of a [internal.Store], which keeps fields of logs evicted
+// from the store. It is safe for concurrent use.
+type Catalog struct {
+	mu      sync.RWMutex
+	seed    maphash.Seed
+	fields  map[string]*field
+	dropped int64
+
+	// now returns the time logs are seen.
+	now func() time.Time
+}
+
+func New() *Catalog {
+	return &Catalog{
+		seed:   maphash.MakeSeed(),
+		fields: make(map[string]*field),
+		now:    time.Now,
+	}
+}
+
+// typeOf returns the type of a value at a flattened attribute path.
+// Arrays, flattened to nil values, are not cataloged.
+func typeOf(v any) (string, bool) {
+	switch v.(type) {
+	case string:
+		return TypeString, true
+	case float64, int:
+		return TypeNumber, true
+	case bool:
+		return TypeBool, true
+	case internal.Timestamp:
+		return TypeTime, true
+	default:
+		return "", false
+	}
+}
+
+// Add catalogs fields of a log.
+func (c *Catalog) Add(_ int, log *internal.Log) {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path, v := range log.AllAttrs() {
+		typ, ok := typeOf(v)
+		// Unset top-level fields are not seen.
+		if !ok || v == "" || v == 0 || v == internal.Timestamp(0) {
+			continue
+		}
+
+		f, ok := c.fields[path]
+		if !ok {
+			if len(c.fields) >= MaxFields {
+				c.dropped++
+				continue
+			}
+			f = &field{types: make(map[string]int64, 1), top: newTopK(topKSize), firstSeen: now}
+			c.fields[path] = f
+		}
+
+		f.types[typ]++
+		f.count++
+		f.lastSeen = now
+
+		if s, ok := search.Format(v); ok {
+			f.distinct.add(maphash.String(c.seed, s))
+			if len(s) <= maxValueLength {
+				f.top.add(s)
+			}
+		}
+	}
+}
+
+// Remove does nothing, fields of logs evicted from the store stay cataloged.
+func (c *Catalog) Remove(int, *internal.Log) {}
+
+// Dropped returns the number of times a field was not cataloged
+// because there were MaxFields fields already.
+func (c *Catalog) Dropped() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dropped
+}
+
+// Fields returns fields with paths starting with prefix, by path.
+func (c *Catalog) Fields(prefix string) []Field {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var res []Field
+	for _, path := range slices.Sorted(maps.Keys(c.fields)) {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+
+		f := c.fields[path]
+		res = append(res, Field{
+			Path:        path,
+			Types:       maps.Clone(f.types),
+			Count:       f.count,
+			Cardinality: f.distinct.estimate(),
+			TopValues:   f.top.top(TopValues),
+			FirstSeen:   f.firstSeen,
+			LastSeen:    f.lastSeen,
+		})
+	}
+	return res
+}
+
+// Suggest returns at most limit completions of the last word of a
+// search query: field paths for a word without a colon, and frequent
+// values of a field for a word such as "level:err". Completions are the
+// whole query with the last word completed, most frequent first.
+func (c *Catalog) Suggest(query string, limit int) []string {
+	head := query[:strings.LastIndexAny(query, " \t")+1]
+	word := query[len(head):]
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	type suggestion struct {
+		text  string
+		count int64
+	}
+	var suggestions []suggestion
+
+	if path, partial, ok := strings.Cut(word, ":"); ok {
+		f, ok := c.fields[path]
+		if !ok {
+			return nil
+		}
+		partial = strings.ToLower(strings.TrimPrefix(partial, `"`))
+		for _, v := range f.top.top(topKSize) {
+			if strings.HasPrefix(strings.ToLower(v.Value), partial) {
+				suggestions = append(suggestions, suggestion{head + path + ":" + quote(v.Value), v.Count})
+			}
+		}
+	} else {
+		for path, f := range c.fields {
+			if strings.HasPrefix(path, word) {
+				suggestions = append(suggestions, suggestion{head + path + ":", f.count})
+			}
+		}
+	}
+
+	slices.SortFunc(suggestions, func(a, b suggestion) int {
+		return cmp.Or(cmp.Compare(b.count, a.count), cmp.Compare(a.text, b.text))
+	})
+
+	res := make([]string, 0, min(limit, len(suggestions)))
+	for _, s := range suggestions[:min(limit, len(suggestions))] {
+		res = append(res, s.text)
+	}
+	return res
+}
+
+// quote quotes a value of more than one token as a phrase.
+func quote(value string) string {
+	if len(search.Tokenize(value)) > 1 || strings.ContainsAny(value, " \t") {
+		return `"` + strings.ReplaceAll(value, `"`, "") + `"`
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package catalog
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mix returns a well distributed hash of x, the same on every run
+// unlike a seeded [maphash], so that estimates are reproducible.
+func mix(x uint64) uint64 {
+	// The finalizer of splitmix64.
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+func TestHLL(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 100_000} {
+		var h hll
+		for i := range n {
+			h.add(mix(uint64(i)))
+			h.add(mix(uint64(i)))
+		}
+		assert.InEpsilon(t, float64(n)+1, float64(h.estimate())+1, 0.1, n)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	top := newTopK(3)
+	for _, v := range []string{"a", "b", "a", "c", "a", "b", "d"} {
+		top.add(v)
+	}
+
+	res := top.top(2)
+	assert.Equal(t, []ValueCount{{Value: "a", Count: 3}, {Value: "b", Count: 2}}, res)
+	assert.Len(t, top.top(10), 3)
+}
+
+func newLog(seq int, level string, attrs map[string]any) *internal.Log {
+	return &internal.Log{
+		ID:        internal.LogID{ProducerID: "app", SequenceNumber: seq},
+		Timestamp: internal.Timestamp(seq),
+		Level:     level,
+		Message:   "msg " + strconv.Itoa(seq),
+		Attrs:     attrs,
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	c := New()
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	c.Add(0, newLog(1, "info", map[string]any{"status": 200.0, "user": map[string]any{"name": "alice"}}))
+	c.Add(1, newLog(2, "error", map[string]any{"status": "timeout", "tags": []any{"a"}}))
+	c.Add(2, newLog(3, "info", nil))
+
+	fields := c.Fields("")
+	paths := make([]string, 0, len(fields))
+	for _, f := range fields {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{
+		"attrs.status", "attrs.user.name", "id.producer_id", "id.sequence_number",
+		"level", "message", "timestamp",
+	}, paths)
+
+	// Hashes are seeded randomly, and distinct values may collide
+	// in the sketch.
+	status := c.Fields("attrs.status")
+	require.Len(t, status, 1)
+	assert.InDelta(t, 2, status[0].Cardinality, 1)
+	status[0].Cardinality = 0
+	assert.Equal(t, Field{
+		Path:      "attrs.status",
+		Types:     map[string]int64{TypeNumber: 1, TypeString: 1},
+		Count:     2,
+		TopValues: []ValueCount{{Value: "200", Count: 1}, {Value: "timeout", Count: 1}},
+		FirstSeen: time.Date(2025, time.March, 1, 12, 0, 1, 0, time.UTC),
+		LastSeen:  time.Date(2025, time.March, 1, 12, 0, 2, 0, time.UTC),
+	}, status[0])
+
+	level := c.Fields("level")[0]
+	assert.Equal(t, []ValueCount{{Value: "info", Count: 2}, {Value: "error", Count: 1}}, level.TopValues)
+	assert.InDelta(t, 3, c.Fields("message")[0].Cardinality, 1)
+	assert.Equal(t, map[string]int64{TypeTime: 3}, c.Fields("timestamp")[0].Types)
+}
+
+func TestCatalog_MaxFields(t *testing.T) {
+	c := New()
+	attrs := make(map[string]any, MaxFields)
+	for i := range MaxFields {
+		attrs["k"+strconv.Itoa(i)] = "v"
+	}
+	c.Add(0, newLog(1, "info", attrs))
+
+	assert.Len(t, c.Fields(""), MaxFields)
+	assert.Positive(t, c.Dropped())
+}
+
+func TestCatalog_Suggest(t *testing.T) {
+	c := New()
+	c.Add(0, newLog(1, "error", map[string]any{"user": "Alice Smith"}))
+	c.Add(1, newLog(2, "error", map[string]any{"user": "bob"}))
+	c.Add(2, newLog(3, "warn", nil))
+
+	assert.Equal(t, []string{"timeout level:"}, c.Suggest("timeout lev", 10))
+	assert.Equal(t, []string{"attrs.user:"}, c.Suggest("attrs.", 10))
+	assert.Equal(t, []string{"level:error", "level:warn"}, c.Suggest("level:", 10))
+	assert.Equal(t, []string{"x level:warn"}, c.Suggest("x level:W", 10))
+	assert.Equal(t, []string{`attrs.user:"Alice Smith"`}, c.Suggest(`attrs.user:"al`, 10))
+	assert.Len(t, c.Suggest("", 2), 2)
+	assert.Empty(t, c.Suggest("missing:x", 10))
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package catalog
+
+import (
+	"math"
+	"math/bits"
+)
+
+const (
+	hllPrecision = 10
+	hllRegisters = 1 << hllPrecision
+)
+
+// hll is a HyperLogLog sketch estimating the number of distinct
+// hashes added to it, with a standard error of about 3%.
+type hll [hllRegisters]uint8
+
+func (h *hll) add(hash uint64) {
+	i := hash >> (64 - hllPrecision)
+	// The guard bit bounds the rank of hashes with no bits set.
+	w := hash<<hllPrecision | 1<<(hllPrecision-1)
+	if rank := uint8(bits.LeadingZeros64(w) + 1); rank > h[i] {
+		h[i] = rank
+	}
+}
+
+func (h *hll) estimate() uint64 {
+	const m = float64(hllRegisters)
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	sum, zeros := 0.0, 0
+	for _, r := range h {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	e := alpha * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small cardinalities.
+		e = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(e))
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package catalog
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ValueCount is a value of a field and an estimate of how many times
+// it was seen, an overestimate by at most Error.
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+	Error int64  `json:"error,omitempty"`
+}
+
+// topK finds the most frequent values with the Space-Saving algorithm,
+// counting at most k values at a time.
+type topK struct {
+	k      int
+	counts map[string]*ValueCount
+}
+
+func newTopK(k int) *topK {
+	return &topK{k: k, counts: make(map[string]*ValueCount, k)}
+}
+
+func (t *topK) add(value string) {
+	if c, ok := t.counts[value]; ok {
+		c.Count++
+		return
+	}
+
+	if len(t.counts) < t.k {
+		t.counts[value] = &ValueCount{Value: value, Count: 1}
+		return
+	}
+
+	// Replace the least counted value, which the new value may have
+	// been seen as many times as.
+	var least *ValueCount
+	for _, c := range t.counts {
+		if least == nil || c.Count < least.Count {
+			least = c
+		}
+	}
+	delete(t.counts, least.Value)
+	t.counts[value] = &ValueCount{Value: value, Count: least.Count + 1, Error: least.Count}
+}
+
+// top returns at most n of the most counted values, most counted first.
+func (t *topK) top(n int) []ValueCount {
+	res := make([]ValueCount, 0, len(t.counts))
+	for _, c := range t.counts {
+		res = append(res, *c)
+	}
+	slices.SortFunc(res, func(a, b ValueCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Value, b.Value))
+	})
+	return res[:min(n, len(res))]
+}
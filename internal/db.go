@@ -6,7 +6,9 @@ package internal
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/KirilStrezikozin/logcrunch/internal/metrics"
 	"github.com/KirilStrezikozin/logcrunch/internal/types"
 	"github.com/boltdb/bolt"
 )
@@ -20,12 +22,18 @@ func (e *DBError) Error() string {
 	return fmt.Sprintf("db %s: %v", e.Op, e.Err)
 }
 
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
+
 type DBReader interface {
 	Get(bucketName, key []byte, fn func([]byte) error) error
+	ForEach(bucketName []byte, fn func(key, value []byte) error) error
 }
 
 type DBWriter interface {
 	Put(bucketName, key, value []byte) error
+	Delete(bucketName, key []byte) error
 }
 
 type DBReadWriter interface {
@@ -40,16 +48,33 @@ type DB interface {
 }
 
 type BoltDB struct {
-	db *bolt.DB
+	db   *bolt.DB
+	path string
+
+	writeDuration *metrics.Histogram
 }
 
 func NewBoltDB() *BoltDB {
-	return &BoltDB{}
+	return NewBoltDBAt(types.DBFilePath)
+}
+
+// NewBoltDBAt returns a database of the file at path.
+func NewBoltDBAt(path string) *BoltDB {
+	return &BoltDB{path: path}
+}
+
+// Instrument registers the latency of writes to db with reg.
+func (db *BoltDB) Instrument(reg *metrics.Registry) {
+	db.writeDuration = reg.NewHistogram(
+		"logcrunch_db_write_duration_seconds",
+		"Latency of database writes by operation.",
+		metrics.DefaultBuckets, "op",
+	)
 }
 
 func (db *BoltDB) Open() error {
 	var err error
-	db.db, err = bolt.Open(types.DBFilePath, types.DBFileMode, nil)
+	db.db, err = bolt.Open(db.path, types.DBFileMode, nil)
 	if err != nil {
 		return &DBError{Op: "open", Err: err}
 	}
@@ -66,13 +91,9 @@ func (db *BoltDB) Close() error {
 
 func (db *BoltDB) Get(bucketName, key []byte, fn func([]byte) error) error {
 	err := db.db.View(func(tx *bolt.Tx) error {
-		b, err := tx.CreateBucketIfNotExists(bucketName)
-		if err != nil {
-			return &DBError{Op: "create or get bucket", Err: err}
-		}
-
+		b := tx.Bucket(bucketName)
 		if b == nil {
-			return nil
+			return fn(nil)
 		}
 		value := b.Get(key)
 		return fn(value)
@@ -84,7 +105,25 @@ func (db *BoltDB) Get(bucketName, key []byte, fn func([]byte) error) error {
 	return nil
 }
 
+func (db *BoltDB) ForEach(bucketName []byte, fn func(key, value []byte) error) error {
+	err := db.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(fn)
+	})
+
+	if err != nil {
+		return &DBError{Op: "for each", Err: err}
+	}
+	return nil
+}
+
 func (db *BoltDB) Put(bucketName, key, value []byte) error {
+	start := time.Now()
+	defer func() { db.writeDuration.Observe(time.Since(start).Seconds(), "put") }()
+
 	err := db.db.Update(func(tx *bolt.Tx) error {
 		b, err := tx.CreateBucketIfNotExists(bucketName)
 		if err != nil {
@@ -102,3 +141,21 @@ func (db *BoltDB) Put(bucketName, key, value []byte) error {
 	}
 	return nil
 }
+
+func (db *BoltDB) Delete(bucketName, key []byte) error {
+	start := time.Now()
+	defer func() { db.writeDuration.Observe(time.Since(start).Seconds(), "delete") }()
+
+	err := db.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		if b == nil {
+			return nil
+		}
+		return b.Delete(key)
+	})
+
+	if err != nil {
+		return &DBError{Op: "delete", Err: err}
+	}
+	return nil
+}
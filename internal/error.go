@@ -9,4 +9,18 @@ import "errors"
 var (
 	ErrNilConnection                = errors.New("nil connection")
 	ErrConnectionAlreadyEstablished = errors.New("connection already established")
+	ErrSessionNotFound              = errors.New("session not found")
+	ErrCallNotFound                 = errors.New("call not found")
+	ErrMalformedProtobuf            = errors.New("malformed protobuf message")
+	ErrValueTooDeep                 = errors.New("value nested too deeply")
+	ErrMalformedSyslog              = errors.New("malformed syslog message")
+	ErrUnknownDecoder               = errors.New("unknown decoder")
+	ErrMalformedContainerLog        = errors.New("malformed container log")
+	ErrInvalidPipelineConfig        = errors.New("invalid pipeline config")
+	ErrInvalidAggregateQuery        = errors.New("invalid aggregate query")
+	ErrViewNotFound                 = errors.New("view not found")
+	ErrInvalidViewName              = errors.New("invalid view name")
+	ErrPatternNotFound              = errors.New("pattern not found")
+	ErrInvalidAlertConfig           = errors.New("invalid alert config")
+	ErrInvalidMetricsConfig         = errors.New("invalid metrics config")
 )
@@ -5,28 +5,198 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/histogram"
+	"github.com/KirilStrezikozin/logcrunch/internal/metrics"
+	"github.com/KirilStrezikozin/logcrunch/internal/otlp"
+	"github.com/KirilStrezikozin/logcrunch/internal/patterns"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
 	"github.com/KirilStrezikozin/logcrunch/internal/services"
+	"github.com/KirilStrezikozin/logcrunch/internal/types"
 	"github.com/KirilStrezikozin/logcrunch/web/templates"
 	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
 )
 
 type Handler struct {
-	logger      zerolog.Logger
-	connService services.IConnectionService
+	logger           zerolog.Logger
+	connService      services.IConnectionService
+	logService       services.ILogService
+	profilerService  services.IProfilerService
+	otlpService      services.IOTLPService
+	pipelineService  services.IPipelineService
+	searchService    services.ISearchService
+	fieldService     services.IFieldService
+	aggregateService services.IAggregateService
+	histogramService services.IHistogramService
+	viewService      services.IViewService
+	patternService   services.IPatternService
+	alertService     services.IAlertService
+	anomalyService   services.IAnomalyService
+	metricsService   services.IMetricsService
 }
 
-func New(logger zerolog.Logger, connService services.IConnectionService) *Handler {
+func New(
+	logger zerolog.Logger,
+	connService services.IConnectionService,
+	logService services.ILogService,
+	profilerService services.IProfilerService,
+	otlpService services.IOTLPService,
+	pipelineService services.IPipelineService,
+	searchService services.ISearchService,
+	fieldService services.IFieldService,
+	aggregateService services.IAggregateService,
+	histogramService services.IHistogramService,
+	viewService services.IViewService,
+	patternService services.IPatternService,
+	alertService services.IAlertService,
+	anomalyService services.IAnomalyService,
+	metricsService services.IMetricsService,
+) *Handler {
 	return &Handler{
-		logger:      logger,
-		connService: connService,
+		logger:           logger,
+		connService:      connService,
+		logService:       logService,
+		profilerService:  profilerService,
+		otlpService:      otlpService,
+		pipelineService:  pipelineService,
+		searchService:    searchService,
+		fieldService:     fieldService,
+		aggregateService: aggregateService,
+		histogramService: histogramService,
+		viewService:      viewService,
+		patternService:   patternService,
+		alertService:     alertService,
+		anomalyService:   anomalyService,
+		metricsService:   metricsService,
 	}
 }
 
 func (h *Handler) Index() http.Handler {
-	return templ.Handler(templates.Index())
+	return templ.Handler(templates.Index(internal.SavedView{}))
+}
+
+// GetSavedViewPage renders the page restoring a saved view.
+func (h *Handler) GetSavedViewPage(w http.ResponseWriter, r *http.Request) {
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, "invalid view name", http.StatusBadRequest)
+		return
+	}
+
+	view, err := h.viewService.GetView(name)
+	if errors.Is(err, internal.ErrViewNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get saved view")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := r.Context()
+	component := templates.Index(view)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// renderSavedViews renders the saved views menu, open if requested
+// from it.
+func (h *Handler) renderSavedViews(w http.ResponseWriter, r *http.Request, open bool) {
+	views, err := h.viewService.ListViews()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to list saved views")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := r.Context()
+	component := templates.SavedViews(views, open)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetSavedViews(w http.ResponseWriter, r *http.Request) {
+	h.renderSavedViews(w, r, false)
+}
+
+// PostSavedViews saves the state of the UI submitted from the saved
+// views menu under a name.
+func (h *Handler) PostSavedViews(w http.ResponseWriter, r *http.Request) {
+	window, err := parseWindow(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	value, err := strconv.Atoi(r.FormValue(templates.ViewModeParam))
+	mode := types.ViewMode(value)
+	if err != nil || (mode != types.ViewModeLogList && mode != types.ViewModeProfiler) {
+		http.Error(w, "invalid view mode", http.StatusBadRequest)
+		return
+	}
+
+	// Columns are not shown in the profiler view.
+	var columns []internal.Column
+	if mode == types.ViewModeLogList {
+		if columns, err = parseColumns(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	_, err = h.viewService.SaveView(internal.SavedView{
+		Name:    r.FormValue(templates.ViewNameInputName),
+		Mode:    mode,
+		Query:   r.FormValue(templates.SearchQueryParam),
+		Columns: columns,
+		From:    window.From,
+		To:      window.To,
+	})
+	if errors.Is(err, internal.ErrInvalidViewName) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		h.logger.Error().Err(err).Msg("failed to save view")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	h.renderSavedViews(w, r, true)
+}
+
+func (h *Handler) DeleteSavedView(w http.ResponseWriter, r *http.Request) {
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, "invalid view name", http.StatusBadRequest)
+		return
+	}
+
+	if err = h.viewService.DeleteView(name); err != nil {
+		h.logger.Error().Err(err).Msg("failed to delete saved view")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	h.renderSavedViews(w, r, true)
 }
 
 func (h *Handler) Static() http.Handler {
@@ -85,3 +255,731 @@ func (h *Handler) GetConnectionStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// parseLogID reads a log id from the producer_id and sequence_number URL parameters.
+func parseLogID(r *http.Request) (internal.LogID, error) {
+	seq, err := strconv.Atoi(chi.URLParam(r, "sequence_number"))
+	if err != nil {
+		return internal.LogID{}, fmt.Errorf("invalid sequence number: %w", err)
+	}
+
+	// Producer ids are path escaped, chi leaves them escaped
+	// when routing on the raw path.
+	producerID, err := url.PathUnescape(chi.URLParam(r, "producer_id"))
+	if err != nil {
+		return internal.LogID{}, fmt.Errorf("invalid producer id: %w", err)
+	}
+	return internal.LogID{ProducerID: producerID, SequenceNumber: seq}, nil
+}
+
+func (h *Handler) GetLog(w http.ResponseWriter, r *http.Request) {
+	id, err := parseLogID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log, ok := h.logService.GetLog(id)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	// The detail is of the logs view unless opened from the profiler.
+	mode := types.ViewModeLogList
+	if r.FormValue(templates.ViewModeParam) == strconv.Itoa(int(types.ViewModeProfiler)) {
+		mode = types.ViewModeProfiler
+	}
+
+	ctx := r.Context()
+	component := templates.LogDetail(log, mode)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// PostLogs ingests newline delimited JSON encoded logs.
+// Unparsable lines are skipped.
+func (h *Handler) PostLogs(w http.ResponseWriter, r *http.Request) {
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(nil, internal.WebSocketReadLimit)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		if err := h.logService.AddLogData(services.SourceHTTP, line); err != nil {
+			h.logger.Error().Err(err).Bytes("data", line).Msg("unparsable log data, skipping")
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) PostOTLPLogs(w http.ResponseWriter, r *http.Request) {
+	h.postOTLP(w, r, h.otlpService.ExportLogs)
+}
+
+func (h *Handler) PostOTLPTraces(w http.ResponseWriter, r *http.Request) {
+	h.postOTLP(w, r, h.otlpService.ExportTraces)
+}
+
+// postOTLP reads an OTLP/HTTP export request, optionally gzip compressed,
+// passes it to export and writes an empty export response.
+func (h *Handler) postOTLP(w http.ResponseWriter, r *http.Request, export func([]byte, otlp.Format) error) {
+	format, ok := otlp.FormatOf(r.Header.Get("Content-Type"))
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, r.Body, otlp.MaxRequestSize)
+	switch r.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = io.LimitReader(gz, otlp.MaxRequestSize+1)
+	default:
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(data) > otlp.MaxRequestSize {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := export(data, format); err != nil {
+		h.logger.Error().Err(err).Msg("failed to decode OTLP export request")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(format.EmptyResponse())
+}
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+// search returns logs matching the query parameters.
+func (h *Handler) search(r *http.Request) ([]internal.Log, error) {
+	window, err := parseWindow(r, "")
+	if err != nil {
+		return nil, err
+	}
+
+	limit := defaultSearchLimit
+	if value := r.FormValue(templates.SearchLimitParam); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", templates.SearchLimitParam, value)
+		}
+	}
+
+	query := r.FormValue(templates.SearchQueryParam)
+	return h.searchService.Search(query, window, min(limit, maxSearchLimit)), nil
+}
+
+// GetLogSearch returns logs matching a full-text query as JSON, newest first.
+func (h *Handler) GetLogSearch(w http.ResponseWriter, r *http.Request) {
+	logs, err := h.search(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(logs); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetLogSearchView renders rows of logs matching a full-text query.
+func (h *Handler) GetLogSearchView(w http.ResponseWriter, r *http.Request) {
+	logs, err := h.search(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	columns, err := parseColumns(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	component := templates.LogRows(logs, columns)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseColumns reads columns of the logs table from the query parameters,
+// returning the default ones if there are none. Widths over
+// [internal.MaxColumnWidth] are clamped to it.
+func parseColumns(r *http.Request) ([]internal.Column, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	paths := r.Form[templates.ColumnParam]
+	widths := r.Form[templates.ColumnWidthParam]
+	if len(paths) == 0 {
+		return internal.DefaultColumns, nil
+	}
+	if len(paths) > internal.MaxColumns {
+		return nil, fmt.Errorf("too many columns: %d > %d", len(paths), internal.MaxColumns)
+	}
+
+	columns := make([]internal.Column, len(paths))
+	for i, path := range paths {
+		columns[i].Path = path
+		if i >= len(widths) || widths[i] == "" {
+			continue
+		}
+
+		width, err := strconv.Atoi(widths[i])
+		if err != nil || width < 0 {
+			return nil, fmt.Errorf("invalid %s: %q", templates.ColumnWidthParam, widths[i])
+		}
+		columns[i].Width = min(width, internal.MaxColumnWidth)
+	}
+	return columns, nil
+}
+
+// GetLogColumnsView renders the logs table after adding, removing or
+// moving a column.
+func (h *Handler) GetLogColumnsView(w http.ResponseWriter, r *http.Request) {
+	columns, err := parseColumns(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	columns = slices.Clone(columns)
+
+	i, err := strconv.Atoi(r.FormValue(templates.ColumnIndexParam))
+	if err != nil || i < 0 || i > len(columns) {
+		http.Error(w, "invalid column index", http.StatusBadRequest)
+		return
+	}
+
+	switch op := r.FormValue(templates.ColumnOpParam); {
+	case op == templates.ColumnOpAdd:
+		path := strings.TrimSpace(r.FormValue(templates.ColumnPathParam))
+		if path != "" && len(columns) < internal.MaxColumns &&
+			!slices.ContainsFunc(columns, func(c internal.Column) bool { return c.Path == path }) {
+			columns = slices.Insert(columns, i, internal.Column{Path: path, Width: defaultColumnWidth})
+		}
+	case i == len(columns):
+		http.Error(w, "invalid column index", http.StatusBadRequest)
+		return
+	case op == templates.ColumnOpRemove:
+		if len(columns) > 1 {
+			columns = slices.Delete(columns, i, i+1)
+		}
+	case op == templates.ColumnOpLeft:
+		if i > 0 {
+			columns[i-1], columns[i] = columns[i], columns[i-1]
+		}
+	case op == templates.ColumnOpRight:
+		if i < len(columns)-1 {
+			columns[i], columns[i+1] = columns[i+1], columns[i]
+		}
+	default:
+		http.Error(w, fmt.Sprintf("invalid %s: %q", templates.ColumnOpParam, op), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	component := templates.LogTable(columns)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+const (
+	// defaultColumnWidth is the width in pixels of added columns.
+	defaultColumnWidth = 160
+	// maxColumnPaths limits paths suggested as columns.
+	maxColumnPaths = 500
+)
+
+// GetFieldPathsView renders paths of cataloged fields to add as columns.
+func (h *Handler) GetFieldPathsView(w http.ResponseWriter, r *http.Request) {
+	fields := h.fieldService.GetFields("").Fields
+	fields = fields[:min(len(fields), maxColumnPaths)]
+
+	ctx := r.Context()
+	component := templates.ColumnPaths(fields)
+	if err := component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetFields returns cataloged fields as JSON, optionally those
+// whose paths start with a prefix.
+func (h *Handler) GetFields(w http.ResponseWriter, r *http.Request) {
+	fields := h.fieldService.GetFields(r.FormValue(templates.FieldPrefixParam))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(fields); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// maxSuggestions limits completions of the search query.
+const maxSuggestions = 20
+
+// GetFieldSuggestView renders completions of the search query.
+func (h *Handler) GetFieldSuggestView(w http.ResponseWriter, r *http.Request) {
+	suggestions := h.fieldService.Suggest(r.FormValue(templates.SearchQueryParam), maxSuggestions)
+
+	ctx := r.Context()
+	component := templates.SearchSuggestions(suggestions)
+	if err := component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetAggregate returns the result of an aggregation query as JSON time series.
+func (h *Handler) GetAggregate(w http.ResponseWriter, r *http.Request) {
+	window, err := parseWindow(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.aggregateService.Aggregate(r.FormValue(templates.AggregateQueryParam), window)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetAggregateView renders the result of an aggregation query as a chart.
+// Invalid queries are rendered as an error in place of the chart.
+func (h *Handler) GetAggregateView(w http.ResponseWriter, r *http.Request) {
+	window, err := parseWindow(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.aggregateService.Aggregate(r.FormValue(templates.AggregateQueryParam), window)
+
+	ctx := r.Context()
+	component := templates.AggregateChart(res, err)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// histogram returns the log volume histogram of the query parameters.
+func (h *Handler) histogram(r *http.Request) (histogram.Histogram, profiler.Window, error) {
+	window, err := parseWindow(r, "")
+	if err != nil {
+		return histogram.Histogram{}, window, err
+	}
+
+	buckets := histogram.DefaultBuckets
+	if value := r.FormValue(templates.HistogramBucketsParam); value != "" {
+		if buckets, err = strconv.Atoi(value); err != nil || buckets <= 0 {
+			return histogram.Histogram{}, window, fmt.Errorf("invalid %s: %q", templates.HistogramBucketsParam, value)
+		}
+	}
+
+	return h.histogramService.GetHistogram(window, buckets), window, nil
+}
+
+// GetHistogram returns the number of logs over time by level as JSON.
+func (h *Handler) GetHistogram(w http.ResponseWriter, r *http.Request) {
+	hist, _, err := h.histogram(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(hist); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetHistogramView renders the log volume histogram strip.
+func (h *Handler) GetHistogramView(w http.ResponseWriter, r *http.Request) {
+	hist, window, err := h.histogram(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	anomalies := h.anomalyService.GetAnomalies(profiler.Window{From: hist.From, To: hist.To})
+
+	ctx := r.Context()
+	component := templates.Histogram(hist, window, anomalies)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetAnomalies returns anomalies within the time range as JSON.
+func (h *Handler) GetAnomalies(w http.ResponseWriter, r *http.Request) {
+	window, err := parseWindow(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(h.anomalyService.GetAnomalies(window)); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetPatterns returns mined patterns of log messages as JSON.
+func (h *Handler) GetPatterns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.patternService.GetPatterns()); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// maxShownPatterns limits patterns rendered in the patterns view.
+const maxShownPatterns = 100
+
+// GetPatternsView renders mined patterns of log messages.
+func (h *Handler) GetPatternsView(w http.ResponseWriter, r *http.Request) {
+	ps := h.patternService.GetPatterns()
+
+	ctx := r.Context()
+	component := templates.Patterns(ps[:min(len(ps), maxShownPatterns)], len(ps))
+	if err := component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// patternLogs returns the pattern of the path parameter and its latest logs.
+func (h *Handler) patternLogs(r *http.Request) (patterns.Pattern, []internal.Log, int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return patterns.Pattern{}, nil, http.StatusBadRequest, errors.New("invalid pattern id")
+	}
+
+	limit := defaultSearchLimit
+	if value := r.FormValue(templates.SearchLimitParam); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			return patterns.Pattern{}, nil, http.StatusBadRequest,
+				fmt.Errorf("invalid %s: %q", templates.SearchLimitParam, value)
+		}
+	}
+
+	p, logs, err := h.patternService.GetPatternLogs(id, min(limit, maxSearchLimit))
+	if err != nil {
+		return p, nil, http.StatusNotFound, err
+	}
+	return p, logs, http.StatusOK, nil
+}
+
+// GetPatternLogs returns the latest logs of a pattern as JSON, newest first.
+func (h *Handler) GetPatternLogs(w http.ResponseWriter, r *http.Request) {
+	_, logs, status, err := h.patternLogs(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(logs); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetPatternLogsView renders rows of the latest logs of a pattern.
+func (h *Handler) GetPatternLogsView(w http.ResponseWriter, r *http.Request) {
+	p, logs, status, err := h.patternLogs(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	ctx := r.Context()
+	component := templates.PatternLogs(p, logs)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetAlerts returns alerts of the configured rules as JSON.
+func (h *Handler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.alertService.GetAlerts()); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetAlertsView renders alerts of the configured rules,
+// open if requested from the open panel.
+func (h *Handler) GetAlertsView(w http.ResponseWriter, r *http.Request) {
+	open := r.FormValue(templates.AlertsOpenParam) == "true"
+
+	ctx := r.Context()
+	component := templates.Alerts(h.alertService.GetAlerts(), open)
+	if err := component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetMetrics returns metrics in the Prometheus text exposition format.
+func (h *Handler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	if err := h.metricsService.WriteMetrics(w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetLogListView() http.Handler {
+	return templ.Handler(templates.LogListView())
+}
+
+func (h *Handler) GetProfilerView() http.Handler {
+	return templ.Handler(templates.ProfilerView())
+}
+
+// parseWindow reads optional window bounds from the query parameters
+// prefix+"from" and prefix+"to".
+func parseWindow(r *http.Request, prefix string) (profiler.Window, error) {
+	var window profiler.Window
+	for param, bound := range map[string]*internal.Timestamp{
+		prefix + templates.TimelineFromParam: &window.From,
+		prefix + templates.TimelineToParam:   &window.To,
+	} {
+		value := r.FormValue(param)
+		if value == "" {
+			continue
+		}
+
+		ts, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return window, fmt.Errorf("invalid %s: %w", param, err)
+		}
+		*bound = internal.Timestamp(ts)
+	}
+	return window, nil
+}
+
+func (h *Handler) GetProfilerTimeline(w http.ResponseWriter, r *http.Request) {
+	window, err := parseWindow(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timeline, err := h.profilerService.GetTimeline(window, r.FormValue(templates.TimelineTraceParam))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get profiler timeline")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if producerID := r.FormValue(templates.TimelineCriticalProducerParam); producerID != "" {
+		seq, err := strconv.Atoi(r.FormValue(templates.TimelineCriticalSequenceParam))
+		if err != nil {
+			http.Error(w, "invalid critical path sequence number", http.StatusBadRequest)
+			return
+		}
+
+		root := internal.LogID{ProducerID: producerID, SequenceNumber: seq}
+		path, err := h.profilerService.GetCriticalPath(root)
+		if err == nil {
+			timeline.HighlightCriticalPath(path)
+		} else if !errors.Is(err, internal.ErrCallNotFound) {
+			h.logger.Error().Err(err).Msg("failed to get critical path")
+		}
+	}
+
+	h.anomalyService.AnnotateTimeline(&timeline)
+
+	ctx := r.Context()
+	component := templates.Timeline(timeline)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetProfilerCriticalPath(w http.ResponseWriter, r *http.Request) {
+	root, err := parseLogID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	path, err := h.profilerService.GetCriticalPath(root)
+	if errors.Is(err, internal.ErrCallNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get critical path")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(path); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetProfilerHealth(w http.ResponseWriter, r *http.Request) {
+	window, err := parseWindow(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.profilerService.GetHealthReport(window)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get profile health report")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := r.Context()
+	component := templates.ProfileHealth(report)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetProfilerDiff(w http.ResponseWriter, r *http.Request) {
+	var sources [2]services.ProfileSource
+	for i, prefix := range []string{templates.ProfileBeforePrefix, templates.ProfileAfterPrefix} {
+		window, err := parseWindow(r, prefix)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sources[i] = services.ProfileSource{
+			Session: r.FormValue(prefix + templates.ProfileSessionParam),
+			Window:  window,
+		}
+	}
+
+	diff, err := h.profilerService.GetProfileDiff(sources[0], sources[1])
+	if errors.Is(err, internal.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get profile diff")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if sortKey := r.FormValue(templates.ProfileSortParam); sortKey != "" {
+		diff.SortBy(profiler.DiffSortKey(sortKey))
+	}
+
+	ctx := r.Context()
+	component := templates.ProfileDiff(diff)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetProfilerSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.profilerService.ListSessions()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to list profiler sessions")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := r.Context()
+	component := templates.ProfileDiffForm(sessions)
+	if err = component.Render(ctx, w); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) PostProfilerSessions(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue(templates.SessionNameInputName)
+	if name == "" {
+		http.Error(w, "empty session name", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.profilerService.SaveSession(name); err != nil {
+		h.logger.Error().Err(err).Msg("failed to save profiler session")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	h.GetProfilerSessions(w, r)
+}
+
+// GetPipeline returns the loaded ingestion pipeline and its metrics.
+func (h *Handler) GetPipeline(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.pipelineService.GetStatus()); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// PostPipelineReload reloads the pipeline config file.
+func (h *Handler) PostPipelineReload(w http.ResponseWriter, r *http.Request) {
+	if err := h.pipelineService.Reload(); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	h.GetPipeline(w, r)
+}
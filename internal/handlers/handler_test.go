@@ -0,0 +1,245 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/services"
+	"github.com/KirilStrezikozin/logcrunch/internal/types"
+	"github.com/KirilStrezikozin/logcrunch/web/templates"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// columnsQuery returns query parameters of columns.
+func columnsQuery(columns ...internal.Column) url.Values {
+	q := url.Values{}
+	for _, c := range columns {
+		q.Add(templates.ColumnParam, c.Path)
+		q.Add(templates.ColumnWidthParam, strconv.Itoa(c.Width))
+	}
+	return q
+}
+
+func TestParseColumns(t *testing.T) {
+	parse := func(q url.Values) ([]internal.Column, error) {
+		return parseColumns(httptest.NewRequest(http.MethodGet, "/?"+q.Encode(), nil))
+	}
+
+	columns, err := parse(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, internal.DefaultColumns, columns)
+
+	q := columnsQuery(
+		internal.Column{Path: "level", Width: 80},
+		internal.Column{Path: "message", Width: internal.MaxColumnWidth + 1000},
+	)
+	q.Add(templates.ColumnParam, "attrs.user")
+	columns, err = parse(q)
+	require.NoError(t, err)
+	assert.Equal(t, []internal.Column{
+		{Path: "level", Width: 80},
+		{Path: "message", Width: internal.MaxColumnWidth},
+		{Path: "attrs.user"},
+	}, columns)
+
+	for _, width := range []string{"-1", "wide"} {
+		q := url.Values{templates.ColumnParam: {"level"}, templates.ColumnWidthParam: {width}}
+		_, err := parse(q)
+		assert.Error(t, err, width)
+	}
+
+	many := make([]internal.Column, internal.MaxColumns+1)
+	for i := range many {
+		many[i].Path = fmt.Sprintf("attrs.a%d", i)
+	}
+	_, err = parse(columnsQuery(many...))
+	assert.Error(t, err)
+}
+
+var columnInputRe = regexp.MustCompile(`name="` + templates.ColumnParam + `" value="([^"]*)"`)
+
+// columnOp requests op on the column at index of columns,
+// returning the status and paths of the rendered columns.
+func columnOp(t *testing.T, op string, index int, path string, columns ...internal.Column) (int, []string) {
+	t.Helper()
+
+	q := columnsQuery(columns...)
+	q.Set(templates.ColumnOpParam, op)
+	q.Set(templates.ColumnIndexParam, strconv.Itoa(index))
+	q.Set(templates.ColumnPathParam, path)
+
+	w := httptest.NewRecorder()
+	(&Handler{}).GetLogColumnsView(w, httptest.NewRequest(http.MethodGet, "/?"+q.Encode(), nil))
+
+	var paths []string
+	for _, m := range columnInputRe.FindAllStringSubmatch(w.Body.String(), -1) {
+		paths = append(paths, m[1])
+	}
+	return w.Code, paths
+}
+
+func TestGetLogColumnsView(t *testing.T) {
+	a, b, c := internal.Column{Path: "a"}, internal.Column{Path: "b"}, internal.Column{Path: "c"}
+
+	tests := []struct {
+		name  string
+		op    string
+		index int
+		path  string
+		want  []string
+	}{
+		{"add", templates.ColumnOpAdd, 1, "attrs.user", []string{"a", "attrs.user", "b", "c"}},
+		{"add last", templates.ColumnOpAdd, 3, "d", []string{"a", "b", "c", "d"}},
+		{"add duplicate", templates.ColumnOpAdd, 0, "b", []string{"a", "b", "c"}},
+		{"add empty", templates.ColumnOpAdd, 0, " ", []string{"a", "b", "c"}},
+		{"remove", templates.ColumnOpRemove, 1, "", []string{"a", "c"}},
+		{"left", templates.ColumnOpLeft, 2, "", []string{"a", "c", "b"}},
+		{"left first", templates.ColumnOpLeft, 0, "", []string{"a", "b", "c"}},
+		{"right", templates.ColumnOpRight, 0, "", []string{"b", "a", "c"}},
+		{"right last", templates.ColumnOpRight, 2, "", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, paths := columnOp(t, tt.op, tt.index, tt.path, a, b, c)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, tt.want, paths)
+		})
+	}
+
+	// The last column is kept.
+	_, paths := columnOp(t, templates.ColumnOpRemove, 0, "", a)
+	assert.Equal(t, []string{"a"}, paths)
+
+	// No more than MaxColumns.
+	full := make([]internal.Column, internal.MaxColumns)
+	for i := range full {
+		full[i].Path = fmt.Sprintf("attrs.a%d", i)
+	}
+	_, paths = columnOp(t, templates.ColumnOpAdd, 0, "extra", full...)
+	assert.Len(t, paths, internal.MaxColumns)
+	assert.NotContains(t, paths, "extra")
+
+	for _, tt := range []struct {
+		op    string
+		index int
+	}{
+		{templates.ColumnOpRemove, 3},
+		{templates.ColumnOpLeft, -1},
+		{templates.ColumnOpAdd, 4},
+		{"rename", 0},
+	} {
+		code, _ := columnOp(t, tt.op, tt.index, "", a, b, c)
+		assert.Equal(t, http.StatusBadRequest, code, tt)
+	}
+}
+
+// newViewsRouter returns a router of saved view endpoints backed by
+// a database in a temporary file.
+func newViewsRouter(t *testing.T) http.Handler {
+	t.Helper()
+	db := internal.NewBoltDBAt(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	h := &Handler{logger: zerolog.Nop(), viewService: services.NewViewService(db, zerolog.Nop())}
+	r := chi.NewRouter()
+	r.Get(types.EndpointGetSavedViewPage, h.GetSavedViewPage)
+	r.Get(types.EndpointGetSavedViews, h.GetSavedViews)
+	r.Post(types.EndpointPostSavedViews, h.PostSavedViews)
+	r.Delete(types.EndpointDeleteSavedView, h.DeleteSavedView)
+	return r
+}
+
+func serve(r http.Handler, method, target string, form url.Values) *httptest.ResponseRecorder {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req := httptest.NewRequest(method, target, body)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSavedViews(t *testing.T) {
+	r := newViewsRouter(t)
+
+	w := serve(r, http.MethodGet, types.GetSavedViewPageEndpoint("slow db"), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	form := columnsQuery(internal.Column{Path: "attrs.user", Width: 120})
+	form.Set(templates.ViewNameInputName, "slow db")
+	form.Set(templates.ViewModeParam, strconv.Itoa(int(types.ViewModeLogList)))
+	form.Set(templates.SearchQueryParam, "source_function:DB.Query")
+	form.Set(templates.TimelineFromParam, "100")
+	w = serve(r, http.MethodPost, types.EndpointPostSavedViews, form)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), types.GetSavedViewPageEndpoint("slow db"))
+
+	w = serve(r, http.MethodGet, types.GetSavedViewPageEndpoint("slow db"), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `value="source_function:DB.Query"`)
+	assert.Contains(t, w.Body.String(), `value="attrs.user"`)
+
+	w = serve(r, http.MethodGet, types.EndpointGetSavedViews, nil)
+	assert.Contains(t, w.Body.String(), types.GetSavedViewPageEndpoint("slow db"))
+
+	w = serve(r, http.MethodDelete, types.GetSavedViewEndpoint("slow db"), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), types.GetSavedViewPageEndpoint("slow db"))
+
+	w = serve(r, http.MethodGet, types.GetSavedViewPageEndpoint("slow db"), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPostSavedViews_Invalid(t *testing.T) {
+	r := newViewsRouter(t)
+
+	valid := func() url.Values {
+		return url.Values{
+			templates.ViewNameInputName: {"errors"},
+			templates.ViewModeParam:     {strconv.Itoa(int(types.ViewModeProfiler))},
+		}
+	}
+
+	for name, edit := range map[string]func(form url.Values){
+		"empty name": func(form url.Values) { form.Set(templates.ViewNameInputName, " ") },
+		"long name": func(form url.Values) {
+			form.Set(templates.ViewNameInputName, strings.Repeat("a", internal.MaxViewNameLength+1))
+		},
+		"mode":  func(form url.Values) { form.Set(templates.ViewModeParam, "7") },
+		"range": func(form url.Values) { form.Set(templates.TimelineFromParam, "yesterday") },
+		"column width": func(form url.Values) {
+			form.Set(templates.ViewModeParam, strconv.Itoa(int(types.ViewModeLogList)))
+			form.Set(templates.ColumnParam, "level")
+			form.Set(templates.ColumnWidthParam, "wide")
+		},
+	} {
+		form := valid()
+		edit(form)
+		w := serve(r, http.MethodPost, types.EndpointPostSavedViews, form)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+
+	w := serve(r, http.MethodGet, types.EndpointGetSavedViews, nil)
+	assert.NotContains(t, w.Body.String(), types.GetSavedViewPageEndpoint("errors"))
+}
@@ -0,0 +1,219 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package histogram counts logs over time by normalized level.
+package histogram
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+const (
+	// Resolution is the width of the narrowest histogram bucket.
+	Resolution = time.Millisecond
+
+	DefaultBuckets = 120
+	MaxBuckets     = 1000
+)
+
+// levelIndex maps normalized levels to their position in [internal.Levels].
+var levelIndex = func() map[string]int {
+	m := make(map[string]int, len(internal.Levels))
+	for i, level := range internal.Levels {
+		m[level] = i
+	}
+	return m
+}()
+
+// Index is a time index of logs in a [internal.Store], counting logs by
+// normalized level in [Resolution]-wide slots. It is an
+// [internal.LogIndex], kept in step with the store it is added to.
+// It is safe for concurrent use.
+type Index struct {
+	mu sync.RWMutex
+
+	// Numbers of slots with logs since the Unix epoch, ascending,
+	// and counts of their logs by level.
+	slots  []int64
+	counts [][]int
+}
+
+func NewIndex() *Index {
+	return &Index{}
+}
+
+// slot returns the number of the slot ts falls into.
+func slot(ts internal.Timestamp) int64 {
+	return int64(math.Floor(float64(ts) * float64(time.Second/Resolution)))
+}
+
+// timestamp returns the start of slot s.
+func timestamp(s int64) internal.Timestamp {
+	return internal.Timestamp(float64(s) / float64(time.Second/Resolution))
+}
+
+// Add counts log. Logs are mostly added in time order, appending slots.
+func (idx *Index) Add(_ int, log *internal.Log) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	s := slot(log.Timestamp)
+	i := len(idx.slots)
+	if i == 0 || idx.slots[i-1] < s {
+		idx.slots = append(idx.slots, s)
+		idx.counts = append(idx.counts, make([]int, len(internal.Levels)))
+	} else if i, _ = slices.BinarySearch(idx.slots, s); idx.slots[i] != s {
+		idx.slots = slices.Insert(idx.slots, i, s)
+		idx.counts = slices.Insert(idx.counts, i, make([]int, len(internal.Levels)))
+	}
+	idx.counts[i][levelIndex[internal.NormalizeLevel(log.Level)]]++
+}
+
+// Remove uncounts log. Logs are mostly removed in time order,
+// dropping the first slots.
+func (idx *Index) Remove(_ int, log *internal.Log) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	i, ok := slices.BinarySearch(idx.slots, slot(log.Timestamp))
+	if !ok {
+		return
+	}
+
+	counts := idx.counts[i]
+	counts[levelIndex[internal.NormalizeLevel(log.Level)]]--
+	if slices.ContainsFunc(counts, func(n int) bool { return n > 0 }) {
+		return
+	}
+
+	if i == 0 {
+		idx.slots[0], idx.counts[0] = 0, nil
+		idx.slots, idx.counts = idx.slots[1:], idx.counts[1:]
+	} else {
+		idx.slots = slices.Delete(idx.slots, i, i+1)
+		idx.counts = slices.Delete(idx.counts, i, i+1)
+	}
+}
+
+// Histogram is the number of logs in time buckets by level.
+type Histogram struct {
+	// Time range of the buckets.
+	From internal.Timestamp `json:"from"`
+	To   internal.Timestamp `json:"to"`
+	// Width of buckets in seconds.
+	Step internal.Timestamp `json:"step"`
+	// Levels counts are ordered by.
+	Levels  []string `json:"levels"`
+	Buckets []Bucket `json:"buckets"`
+	// Largest total of a bucket.
+	Max int `json:"max"`
+}
+
+// Bucket is the number of logs in a time bucket starting at Timestamp.
+type Bucket struct {
+	Timestamp internal.Timestamp `json:"timestamp"`
+	// Counts by level, ordered as [Histogram.Levels].
+	Counts []int `json:"counts"`
+	Total  int   `json:"total"`
+}
+
+// steps are bucket widths in slots, chosen to be easy to read.
+var steps = func() []int64 {
+	const (
+		ms     = int64(time.Millisecond / Resolution)
+		sec    = 1000 * ms
+		minute = 60 * sec
+		hour   = 60 * minute
+	)
+	return []int64{
+		ms, 2 * ms, 5 * ms, 10 * ms, 20 * ms, 50 * ms, 100 * ms, 200 * ms, 500 * ms,
+		sec, 2 * sec, 5 * sec, 10 * sec, 15 * sec, 30 * sec,
+		minute, 2 * minute, 5 * minute, 10 * minute, 15 * minute, 30 * minute,
+		hour, 2 * hour, 3 * hour, 6 * hour, 12 * hour, 24 * hour,
+	}
+}()
+
+// step returns the narrowest of steps splitting width slots into
+// at most n buckets, or a multiple of the widest one.
+func step(width int64, n int) int64 {
+	need := (width + int64(n) - 1) / int64(n)
+	for _, s := range steps {
+		if s >= need {
+			return s
+		}
+	}
+	day := steps[len(steps)-1]
+	return (need + day - 1) / day * day
+}
+
+// Histogram returns the number of logs in at most n buckets within the
+// time range [from, to), aligned to the bucket width. A zero bound spans
+// to the oldest or the newest log.
+func (idx *Index) Histogram(from, to internal.Timestamp, n int) Histogram {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n = min(max(n, 1), MaxBuckets)
+	h := Histogram{Levels: internal.Levels, Buckets: []Bucket{}}
+
+	var first, last int64
+	switch {
+	case from != 0:
+		first = slot(from)
+	case len(idx.slots) != 0:
+		first = idx.slots[0]
+	default:
+		return h
+	}
+	switch {
+	case to != 0:
+		last = slot(to)
+	case len(idx.slots) != 0:
+		last = idx.slots[len(idx.slots)-1] + 1
+	}
+	last = max(last, first+1)
+
+	width := step(last-first, n)
+	first = floorDiv(first, width) * width
+	n = int((last - first + width - 1) / width)
+
+	h.From = timestamp(first)
+	h.To = timestamp(first + int64(n)*width)
+	h.Step = timestamp(width)
+	h.Buckets = make([]Bucket, n)
+	for i := range h.Buckets {
+		h.Buckets[i] = Bucket{
+			Timestamp: timestamp(first + int64(i)*width),
+			Counts:    make([]int, len(internal.Levels)),
+		}
+	}
+
+	lo, _ := slices.BinarySearch(idx.slots, first)
+	hi, _ := slices.BinarySearch(idx.slots, first+int64(n)*width)
+	for i := lo; i < hi; i++ {
+		b := &h.Buckets[(idx.slots[i]-first)/width]
+		for level, count := range idx.counts[i] {
+			b.Counts[level] += count
+			b.Total += count
+		}
+	}
+	for _, b := range h.Buckets {
+		h.Max = max(h.Max, b.Total)
+	}
+	return h
+}
+
+// floorDiv divides a by b, rounding towards negative infinity.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLog(ts float64, level string) *internal.Log {
+	return &internal.Log{Timestamp: internal.Timestamp(ts), Level: level}
+}
+
+func TestIndex_Histogram(t *testing.T) {
+	idx := NewIndex()
+	logs := []*internal.Log{
+		newLog(100.5, "INFO"),
+		newLog(101.2, "error"),
+		newLog(100.7, "warning"), // Out of order.
+		newLog(109.9, "verbose"),
+		newLog(100.5, "info"),
+	}
+	for i, log := range logs {
+		idx.Add(i, log)
+	}
+
+	h := idx.Histogram(0, 0, 5)
+	assert.Equal(t, internal.Timestamp(100), h.From)
+	assert.Equal(t, internal.Timestamp(110), h.To)
+	assert.Equal(t, internal.Timestamp(2), h.Step)
+	assert.Equal(t, internal.Levels, h.Levels)
+	assert.Equal(t, 4, h.Max)
+	require.Len(t, h.Buckets, 5)
+	assert.Equal(t, Bucket{Timestamp: 100, Counts: []int{0, 0, 2, 1, 1, 0, 0}, Total: 4}, h.Buckets[0])
+	assert.Equal(t, Bucket{Timestamp: 108, Counts: []int{0, 0, 0, 0, 0, 0, 1}, Total: 1}, h.Buckets[4])
+
+	h = idx.Histogram(100.6, 101, 1000)
+	assert.Equal(t, internal.Timestamp(0.001), h.Step)
+	require.Len(t, h.Buckets, 400)
+	assert.Equal(t, 1, h.Buckets[100].Total)
+	assert.Equal(t, 1, h.Max)
+
+	for i, log := range logs[:3] {
+		idx.Remove(i, log)
+	}
+	h = idx.Histogram(0, 0, 10)
+	assert.Equal(t, internal.Timestamp(100), h.From)
+	assert.Equal(t, 1, h.Max)
+
+	for i, log := range logs[3:] {
+		idx.Remove(i+3, log)
+	}
+	assert.Empty(t, idx.slots)
+	assert.Empty(t, idx.Histogram(0, 0, 10).Buckets)
+}
+
+func TestStep(t *testing.T) {
+	assert.Equal(t, int64(1), step(1, 100))
+	assert.Equal(t, int64(5000), step(300_000, 100))
+	assert.Equal(t, int64(15_000), step(1_200_000, 100))
+	assert.Equal(t, int64(2*86_400_000), step(100*86_400_000, 60))
+}
@@ -7,6 +7,12 @@ package internal
 import (
 	"encoding/json"
 	"fmt"
+	"iter"
+	"maps"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 type (
@@ -19,6 +25,55 @@ const (
 	LogTypeMetric
 )
 
+// NewTimestamp converts t to a timestamp.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp(float64(t.UnixNano()) / 1e9)
+}
+
+// Time converts a timestamp in fractional seconds since the Unix epoch to [time.Time].
+func (t Timestamp) Time() time.Time {
+	sec, frac := math.Modf(float64(t))
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+// Normalized log levels, from the least to the most severe.
+// Levels not recognized by [NormalizeLevel] are [LevelOther].
+const (
+	LevelTrace = "trace"
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+	LevelFatal = "fatal"
+	LevelOther = "other"
+)
+
+// Levels lists normalized log levels in order of severity,
+// followed by [LevelOther].
+var Levels = []string{
+	LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelOther,
+}
+
+// levelAliases maps lowercase level names to normalized levels.
+var levelAliases = map[string]string{
+	"trace": LevelTrace, "trc": LevelTrace, "finest": LevelTrace,
+	"debug": LevelDebug, "dbg": LevelDebug, "fine": LevelDebug,
+	"info": LevelInfo, "inf": LevelInfo, "information": LevelInfo,
+	"informational": LevelInfo, "notice": LevelInfo,
+	"warn": LevelWarn, "wrn": LevelWarn, "warning": LevelWarn,
+	"error": LevelError, "err": LevelError, "severe": LevelError,
+	"fatal": LevelFatal, "ftl": LevelFatal, "panic": LevelFatal, "critical": LevelFatal,
+	"crit": LevelFatal, "alert": LevelFatal, "emerg": LevelFatal, "emergency": LevelFatal,
+}
+
+// NormalizeLevel maps a log level name, in any case, to one of [Levels].
+func NormalizeLevel(level string) string {
+	if l, ok := levelAliases[strings.ToLower(strings.TrimSpace(level))]; ok {
+		return l
+	}
+	return LevelOther
+}
+
 type LogID struct {
 	ProducerID     string `json:"producer_id"`
 	SequenceNumber int    `json:"sequence_number"`
@@ -44,6 +99,11 @@ type Log struct {
 	// Call stack leading to the log describing a function call.
 	FunctionCallStack []LogID `json:"call_stack,omitempty"`
 
+	// OpenTelemetry trace context the log was generated in.
+	TraceID      string `json:"trace_id,omitempty"`
+	SpanID       string `json:"span_id,omitempty"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
+
 	// Context attributes associated with the log.
 	Attrs map[string]any `json:"attrs,omitempty"`
 
@@ -51,6 +111,21 @@ type Log struct {
 	parsedAttrs map[string]any
 }
 
+// parseErrors counts logs dropped as NewLog failed to parse them.
+var parseErrors atomic.Int64
+
+// CountParseError counts a log dropped as [NewLog] failed to parse it.
+// Failures of callers falling back to other decodings are not counted.
+func CountParseError() {
+	parseErrors.Add(1)
+}
+
+// ParseErrors returns the number of logs dropped as [NewLog] failed
+// to parse them.
+func ParseErrors() int64 {
+	return parseErrors.Load()
+}
+
 func NewLog(data []byte) (Log, error) {
 	var log Log
 	err := json.Unmarshal(data, &log)
@@ -59,6 +134,7 @@ func NewLog(data []byte) (Log, error) {
 	}
 
 	log.parseAttrs()
+	log.parseTraceContext()
 
 	return log, nil
 }
@@ -70,6 +146,125 @@ func (l *Log) Type() LogType {
 	return LogTypeInfo
 }
 
+// Attr returns the value at the given flattened attribute path,
+// e.g. "level" or "attrs.user.name".
+func (l *Log) Attr(path string) (any, bool) {
+	if l.parsedAttrs == nil {
+		l.parseAttrs()
+	}
+	v, ok := l.parsedAttrs[path]
+	return v, ok
+}
+
+// AllAttrs returns an iterator over flattened attribute paths, as
+// accepted by [Log.Attr], and their values.
+func (l *Log) AllAttrs() iter.Seq2[string, any] {
+	if l.parsedAttrs == nil {
+		l.parseAttrs()
+	}
+	return maps.All(l.parsedAttrs)
+}
+
+// SetAttr sets a top-level attribute. Flattened attribute paths are
+// parsed again on the next call to [Log.Attr], so changes made to other
+// fields before SetAttr are picked up as well.
+func (l *Log) SetAttr(key string, value any) {
+	if l.Attrs == nil {
+		l.Attrs = make(map[string]any)
+	}
+	l.Attrs[key] = value
+	l.parsedAttrs = nil
+}
+
+// SetAttrPath sets the attribute at the flattened path, such as
+// "attrs.user.name", creating nested attributes as needed. Attribute maps
+// along the path are copied, as they may be shared with other logs.
+// It returns false if path is not an attribute path.
+func (l *Log) SetAttrPath(path string, value any) bool {
+	keys, ok := attrKeys(path)
+	if !ok {
+		return false
+	}
+
+	l.Attrs = setAttrRecursive(l.Attrs, keys, value)
+	l.parsedAttrs = nil
+	return true
+}
+
+func setAttrRecursive(attrs map[string]any, keys []string, value any) map[string]any {
+	attrs = maps.Clone(attrs)
+	if attrs == nil {
+		attrs = make(map[string]any, 1)
+	}
+
+	if len(keys) == 1 {
+		attrs[keys[0]] = value
+		return attrs
+	}
+
+	nested, _ := attrs[keys[0]].(map[string]any)
+	attrs[keys[0]] = setAttrRecursive(nested, keys[1:], value)
+	return attrs
+}
+
+// DeleteAttrPath removes the attribute at the flattened path, such as
+// "attrs.user.name", and returns its value. Attribute maps along the path
+// are copied, as they may be shared with other logs.
+func (l *Log) DeleteAttrPath(path string) (any, bool) {
+	keys, ok := attrKeys(path)
+	if !ok {
+		return nil, false
+	}
+
+	attrs, value, ok := deleteAttrRecursive(l.Attrs, keys)
+	if ok {
+		l.Attrs = attrs
+		l.parsedAttrs = nil
+	}
+	return value, ok
+}
+
+func deleteAttrRecursive(attrs map[string]any, keys []string) (map[string]any, any, bool) {
+	v, ok := attrs[keys[0]]
+	if !ok {
+		return attrs, nil, false
+	}
+
+	if len(keys) > 1 {
+		nested, isMap := v.(map[string]any)
+		if !isMap {
+			return attrs, nil, false
+		}
+		nested, v, ok = deleteAttrRecursive(nested, keys[1:])
+		if !ok {
+			return attrs, nil, false
+		}
+		attrs = maps.Clone(attrs)
+		attrs[keys[0]] = nested
+		return attrs, v, true
+	}
+
+	attrs = maps.Clone(attrs)
+	delete(attrs, keys[0])
+	return attrs, v, true
+}
+
+// attrKeys splits an attribute path into the keys of nested attributes.
+func attrKeys(path string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(path, "attrs.")
+	if !ok || rest == "" {
+		return nil, false
+	}
+	return strings.Split(rest, "."), true
+}
+
+// InvalidateAttrs drops flattened attribute paths, to be parsed again on
+// the next call to [Log.Attr]. It must be called after fields of l are
+// changed directly.
+func (l *Log) InvalidateAttrs() {
+	l.parsedAttrs = nil
+}
+
 func (l *Log) parseAttrs() {
 	parsed := make(map[string]any)
 
@@ -84,12 +279,53 @@ func (l *Log) parseAttrs() {
 	parsed["function_call_started_at"] = l.FunctionCallStartedAt
 	parsed["function_call_ended_at"] = l.FunctionCallEndedAt
 	parsed["call_stack"] = nil
+	parsed["trace_id"] = l.TraceID
+	parsed["span_id"] = l.SpanID
+	parsed["parent_span_id"] = l.ParentSpanID
+	if l.Type() == LogTypeMetric {
+		// Duration of the function call in seconds.
+		parsed["duration"] = float64(l.FunctionCallEndedAt - l.FunctionCallStartedAt)
+	}
 
 	parseAttrsRecursive(l.Attrs, parsed, "attrs")
 
 	l.parsedAttrs = parsed
 }
 
+// Attribute paths the trace context is read from when it is not
+// set at the top level, following OpenTelemetry conventions.
+var (
+	traceIDAttrPaths      = []string{"attrs.trace_id", "attrs.traceId", "attrs.otel.trace_id"}
+	spanIDAttrPaths       = []string{"attrs.span_id", "attrs.spanId", "attrs.otel.span_id"}
+	parentSpanIDAttrPaths = []string{"attrs.parent_span_id", "attrs.parentSpanId", "attrs.otel.parent_span_id"}
+)
+
+// parseTraceContext fills trace context fields missing at the top level
+// from attributes. It must be called after parseAttrs.
+func (l *Log) parseTraceContext() {
+	for _, f := range []struct {
+		path  string
+		field *string
+		attrs []string
+	}{
+		{"trace_id", &l.TraceID, traceIDAttrPaths},
+		{"span_id", &l.SpanID, spanIDAttrPaths},
+		{"parent_span_id", &l.ParentSpanID, parentSpanIDAttrPaths},
+	} {
+		if *f.field != "" {
+			continue
+		}
+
+		for _, path := range f.attrs {
+			if v, ok := l.parsedAttrs[path].(string); ok && v != "" {
+				*f.field = v
+				l.parsedAttrs[f.path] = v
+				break
+			}
+		}
+	}
+}
+
 func parseAttrsRecursive(attrs map[string]any, dest map[string]any, prefix string) {
 	if attrs == nil {
 		return
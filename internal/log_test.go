@@ -6,13 +6,14 @@ package internal
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNewLog_Unmarshal(t *testing.T) {
 	jsonData := `{
-		"id": 1,
+		"id": {"producer_id": "app", "sequence_number": 1},
 		"timestamp": 123456,
 		"level": "info",
 		"message": "hello world",
@@ -22,7 +23,7 @@ func TestNewLog_Unmarshal(t *testing.T) {
 		"function_call_started_at": 100.0,
 		"function_call_ended_at": 200.0,
 		"function_duration": 100.0,
-		"call_stack": [1234],
+		"call_stack": [{"producer_id": "app", "sequence_number": 1234}],
 		"attrs": {
 			"user": "alice",
 			"count": 42,
@@ -36,7 +37,7 @@ func TestNewLog_Unmarshal(t *testing.T) {
 	log, err := NewLog([]byte(jsonData))
 	assert.NoError(t, err)
 
-	assert.Equal(t, LogID(1), log.ID)
+	assert.Equal(t, LogID{ProducerID: "app", SequenceNumber: 1}, log.ID)
 	assert.Equal(t, Timestamp(123456), log.Timestamp)
 	assert.Equal(t, "info", log.Level)
 	assert.Equal(t, "hello world", log.Message)
@@ -45,7 +46,7 @@ func TestNewLog_Unmarshal(t *testing.T) {
 	assert.Equal(t, "Class.TestFunc", log.SourceFunction)
 	assert.Equal(t, Timestamp(100), log.FunctionCallStartedAt)
 	assert.Equal(t, Timestamp(200), log.FunctionCallEndedAt)
-	assert.Equal(t, []LogID{1234}, log.FunctionCallStack)
+	assert.Equal(t, []LogID{{ProducerID: "app", SequenceNumber: 1234}}, log.FunctionCallStack)
 
 	assert.Equal(t, "alice", log.Attrs["user"])
 	assert.Equal(t, float64(42), log.Attrs["count"])
@@ -53,7 +54,8 @@ func TestNewLog_Unmarshal(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, true, nested["flag"])
 
-	assert.Equal(t, LogID(1), log.parsedAttrs["id"])
+	assert.Equal(t, "app", log.parsedAttrs["id.producer_id"])
+	assert.Equal(t, 1, log.parsedAttrs["id.sequence_number"])
 	assert.Equal(t, "alice", log.parsedAttrs["attrs.user"])
 	assert.Equal(t, float64(42), log.parsedAttrs["attrs.count"])
 	assert.Equal(t, true, log.parsedAttrs["attrs.nested.flag"])
@@ -62,7 +64,7 @@ func TestNewLog_Unmarshal(t *testing.T) {
 
 func TestLog_Type(t *testing.T) {
 	infoLog := Log{
-		ID:        1,
+		ID:        LogID{SequenceNumber: 1},
 		Level:     "info",
 		Message:   "info message",
 		Timestamp: 123,
@@ -70,7 +72,7 @@ func TestLog_Type(t *testing.T) {
 	assert.Equal(t, LogTypeInfo, infoLog.Type())
 
 	metricLog := Log{
-		ID:                    2,
+		ID:                    LogID{SequenceNumber: 2},
 		Level:                 "info",
 		Message:               "metric message",
 		Timestamp:             123,
@@ -83,7 +85,8 @@ func TestLog_Type(t *testing.T) {
 func TestParseAttrsRecursive_Empty(t *testing.T) {
 	var log Log
 	log.parseAttrs()
-	assert.Equal(t, LogID(0), log.parsedAttrs["id"])
+	assert.Equal(t, "", log.parsedAttrs["id.producer_id"])
+	assert.Equal(t, 0, log.parsedAttrs["id.sequence_number"])
 	assert.Equal(t, Timestamp(0), log.parsedAttrs["timestamp"])
 	assert.Nil(t, log.parsedAttrs["call_stack"])
 }
@@ -103,3 +106,152 @@ func TestParseAttrsRecursive_Nested(t *testing.T) {
 	assert.Equal(t, float64(1), log.parsedAttrs["attrs.d"])
 	assert.Equal(t, "str", log.parsedAttrs["attrs.c"])
 }
+
+func TestLog_Attr(t *testing.T) {
+	log := Log{
+		Level: "warn",
+		Attrs: map[string]any{"user": map[string]any{"name": "alice"}},
+	}
+
+	v, ok := log.Attr("attrs.user.name")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", v)
+
+	v, ok = log.Attr("level")
+	assert.True(t, ok)
+	assert.Equal(t, "warn", v)
+
+	_, ok = log.Attr("attrs.missing")
+	assert.False(t, ok)
+
+	_, ok = log.Attr("duration")
+	assert.False(t, ok)
+
+	log.FunctionCallStartedAt, log.FunctionCallEndedAt = 10, 10.25
+	log.InvalidateAttrs()
+	v, ok = log.Attr("duration")
+	assert.True(t, ok)
+	assert.InDelta(t, 0.25, v, 1e-9)
+}
+
+func TestTimestamp_Time(t *testing.T) {
+	ts := Timestamp(1700000000.25)
+	assert.Equal(t, int64(1700000000), ts.Time().Unix())
+	assert.Equal(t, 250*time.Millisecond, time.Duration(ts.Time().Nanosecond()))
+}
+
+func TestNewTimestamp(t *testing.T) {
+	tm := time.Unix(1700000000, int64(500*time.Millisecond))
+	assert.Equal(t, Timestamp(1700000000.5), NewTimestamp(tm))
+}
+
+func TestNewLog_TraceContext(t *testing.T) {
+	t.Run("top level", func(t *testing.T) {
+		log, err := NewLog([]byte(`{
+			"trace_id": "t1", "span_id": "s2", "parent_span_id": "s1",
+			"attrs": {"trace_id": "ignored"}
+		}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "t1", log.TraceID)
+		assert.Equal(t, "s2", log.SpanID)
+		assert.Equal(t, "s1", log.ParentSpanID)
+		assert.Equal(t, "t1", log.parsedAttrs["trace_id"])
+	})
+
+	t.Run("attrs", func(t *testing.T) {
+		log, err := NewLog([]byte(`{
+			"attrs": {"traceId": "t1", "otel": {"span_id": "s2"}, "parent_span_id": "s1"}
+		}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "t1", log.TraceID)
+		assert.Equal(t, "s2", log.SpanID)
+		assert.Equal(t, "s1", log.ParentSpanID)
+		assert.Equal(t, "s2", log.parsedAttrs["span_id"])
+	})
+}
+
+func TestLog_SetAttr(t *testing.T) {
+	log, err := NewLog([]byte(`{"message": "hello"}`))
+	assert.NoError(t, err)
+
+	log.Level = "error"
+	log.SetAttr("stream", "stderr")
+
+	v, ok := log.Attr("attrs.stream")
+	assert.True(t, ok)
+	assert.Equal(t, "stderr", v)
+
+	v, _ = log.Attr("level")
+	assert.Equal(t, "error", v)
+}
+
+func TestLog_SetAttrPath(t *testing.T) {
+	shared := map[string]any{"name": "alice"}
+	a := Log{Attrs: map[string]any{"user": shared}}
+	b := Log{Attrs: map[string]any{"user": shared}}
+
+	assert.True(t, a.SetAttrPath("attrs.user.id", 7.0))
+	assert.True(t, a.SetAttrPath("attrs.env", "prod"))
+	assert.False(t, a.SetAttrPath("level", "error"))
+
+	assert.Equal(t, map[string]any{"user": map[string]any{"name": "alice", "id": 7.0}, "env": "prod"}, a.Attrs)
+	assert.Equal(t, map[string]any{"name": "alice"}, b.Attrs["user"])
+
+	v, ok := a.Attr("attrs.user.id")
+	assert.True(t, ok)
+	assert.Equal(t, 7.0, v)
+
+	// Replaces a value that is not an attribute map.
+	assert.True(t, a.SetAttrPath("attrs.env.name", "prod"))
+	assert.Equal(t, map[string]any{"name": "prod"}, a.Attrs["env"])
+}
+
+func TestLog_DeleteAttrPath(t *testing.T) {
+	shared := map[string]any{"name": "alice", "id": 7.0}
+	a := Log{Attrs: map[string]any{"user": shared}}
+
+	_, ok := a.Attr("attrs.user.id")
+	assert.True(t, ok)
+
+	v, ok := a.DeleteAttrPath("attrs.user.id")
+	assert.True(t, ok)
+	assert.Equal(t, 7.0, v)
+	assert.Equal(t, map[string]any{"name": "alice"}, a.Attrs["user"])
+	assert.Len(t, shared, 2)
+
+	_, ok = a.Attr("attrs.user.id")
+	assert.False(t, ok)
+
+	for _, path := range []string{"attrs.user.id", "attrs.user.name.first", "attrs.missing", "message"} {
+		_, ok = a.DeleteAttrPath(path)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestLog_InvalidateAttrs(t *testing.T) {
+	log := Log{Level: "info"}
+	_, _ = log.Attr("level")
+
+	log.Level = "warn"
+	log.InvalidateAttrs()
+
+	v, _ := log.Attr("level")
+	assert.Equal(t, "warn", v)
+}
+
+func TestNormalizeLevel(t *testing.T) {
+	for level, want := range map[string]string{
+		"TRACE":    LevelTrace,
+		"debug":    LevelDebug,
+		" Info ":   LevelInfo,
+		"notice":   LevelInfo,
+		"WARNING":  LevelWarn,
+		"err":      LevelError,
+		"Critical": LevelFatal,
+		"panic":    LevelFatal,
+		"":         LevelOther,
+		"verbose":  LevelOther,
+	} {
+		assert.Equal(t, want, NormalizeLevel(level), level)
+	}
+}
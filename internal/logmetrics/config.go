@@ -0,0 +1,68 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package logmetrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// Config configures metrics derived from logs, as read from a JSON file:
+//
+//	{
+//		"counters": [
+//			{"name": "app_errors_total", "help": "Errors of app.", "query": "id.producer_id:app level:error"}
+//		],
+//		"histograms": [
+//			{"name": "db_query_duration_seconds", "query": "source_function:DB.Query", "buckets": [0.01, 0.1, 1]}
+//		]
+//	}
+type Config struct {
+	Counters   []CounterConfig   `json:"counters"`
+	Histograms []HistogramConfig `json:"histograms"`
+}
+
+// CounterConfig configures a counter of logs matching a query.
+type CounterConfig struct {
+	Name string `json:"name"`
+	Help string `json:"help"`
+	// Query in the syntax of [search.ParseQuery], all logs are counted
+	// if empty.
+	Query string `json:"query"`
+}
+
+// HistogramConfig configures a histogram of durations, in seconds,
+// of metric logs matching a query.
+type HistogramConfig struct {
+	Name string `json:"name"`
+	Help string `json:"help"`
+	// Query in the syntax of [search.ParseQuery], durations of all
+	// metric logs are observed if empty.
+	Query string `json:"query"`
+	// Upper bounds of buckets in increasing order,
+	// [metrics.DefaultBuckets] if empty.
+	Buckets []float64 `json:"buckets"`
+}
+
+// ParseConfig parses a JSON encoded configuration of metrics.
+func ParseConfig(data []byte) (Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%w: %w", internal.ErrInvalidMetricsConfig, err)
+	}
+	return cfg, nil
+}
+
+// LoadConfig reads the configuration file of metrics at path.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read metrics config: %w", err)
+	}
+	return ParseConfig(data)
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package logmetrics derives metrics from logs: counters of logs matching
+// queries and histograms of durations of matching metric logs.
+package logmetrics
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/metrics"
+	"github.com/KirilStrezikozin/logcrunch/internal/search"
+)
+
+type counter struct {
+	query   search.Query
+	counter *metrics.Counter
+}
+
+type histogram struct {
+	query     search.Query
+	histogram *metrics.Histogram
+}
+
+// Recorder records metrics of logs added to the store, with which it is
+// kept in step as an [internal.LogIndex]. It is safe for concurrent use.
+type Recorder struct {
+	counters   []counter
+	histograms []histogram
+}
+
+// NewRecorder validates cfg and registers its metrics with reg.
+// Nothing is registered if cfg is invalid.
+func NewRecorder(cfg Config, reg *metrics.Registry) (*Recorder, error) {
+	names := make(map[string]bool)
+	validate := func(name string) error {
+		switch {
+		case !metrics.ValidName(name):
+			return fmt.Errorf("%w: invalid metric name %q", internal.ErrInvalidMetricsConfig, name)
+		case names[name] || reg.Has(name):
+			return fmt.Errorf("%w: duplicate metric %s", internal.ErrInvalidMetricsConfig, name)
+		}
+		names[name] = true
+		return nil
+	}
+
+	for _, c := range cfg.Counters {
+		if err := validate(c.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, h := range cfg.Histograms {
+		if err := validate(h.Name); err != nil {
+			return nil, err
+		}
+		if !slices.IsSorted(h.Buckets) {
+			return nil, fmt.Errorf("%w: %s: buckets not in increasing order", internal.ErrInvalidMetricsConfig, h.Name)
+		}
+	}
+
+	r := &Recorder{}
+	for _, c := range cfg.Counters {
+		r.counters = append(r.counters, counter{
+			query:   search.ParseQuery(c.Query),
+			counter: reg.NewCounter(c.Name, c.Help),
+		})
+	}
+	for _, h := range cfg.Histograms {
+		buckets := h.Buckets
+		if len(buckets) == 0 {
+			buckets = metrics.DefaultBuckets
+		}
+		r.histograms = append(r.histograms, histogram{
+			query:     search.ParseQuery(h.Query),
+			histogram: reg.NewHistogram(h.Name, h.Help, buckets),
+		})
+	}
+	return r, nil
+}
+
+// Len returns the number of metrics recorded.
+func (r *Recorder) Len() int {
+	return len(r.counters) + len(r.histograms)
+}
+
+// Add records log in the metrics of queries it matches.
+func (r *Recorder) Add(_ int, log *internal.Log) {
+	for _, c := range r.counters {
+		if c.query.Match(log) {
+			c.counter.Inc()
+		}
+	}
+
+	if log.Type() != internal.LogTypeMetric {
+		return
+	}
+	duration := float64(log.FunctionCallEndedAt - log.FunctionCallStartedAt)
+	for _, h := range r.histograms {
+		if h.query.Match(log) {
+			h.histogram.Observe(duration)
+		}
+	}
+}
+
+// Remove does nothing, metrics keep counting logs removed from the store.
+func (r *Recorder) Remove(int, *internal.Log) {}
@@ -0,0 +1,82 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package logmetrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{
+		"counters": [
+			{"name": "logs_total", "help": "All logs."},
+			{"name": "app_errors_total", "query": "id.producer_id:app level:error"}
+		],
+		"histograms": [
+			{"name": "query_seconds", "query": "source_function:DB.Query", "buckets": [0.1, 1]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	reg := metrics.NewRegistry()
+	r, err := NewRecorder(cfg, reg)
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.Len())
+
+	store := internal.NewStore(2)
+	store.AddIndex(r)
+	store.AddLogs([]internal.Log{
+		{ID: internal.LogID{ProducerID: "app"}, Level: "error"},
+		{ID: internal.LogID{ProducerID: "app", SequenceNumber: 1}, Level: "info"},
+		{ID: internal.LogID{ProducerID: "db"}, Level: "error"},
+		{
+			ID:                    internal.LogID{ProducerID: "db", SequenceNumber: 1},
+			SourceFunction:        "DB.Query",
+			FunctionCallStartedAt: 10,
+			FunctionCallEndedAt:   10.5,
+		},
+		{ID: internal.LogID{ProducerID: "db", SequenceNumber: 2}, SourceFunction: "DB.Query"},
+	})
+
+	var b strings.Builder
+	require.NoError(t, reg.Write(&b))
+	assert.Equal(t, `# HELP logs_total All logs.
+# TYPE logs_total counter
+logs_total 5
+# TYPE app_errors_total counter
+app_errors_total 1
+# TYPE query_seconds histogram
+query_seconds_bucket{le="0.1"} 0
+query_seconds_bucket{le="1"} 1
+query_seconds_bucket{le="+Inf"} 1
+query_seconds_sum 0.5
+query_seconds_count 1
+`, b.String())
+}
+
+func TestRecorder_Invalid(t *testing.T) {
+	_, err := ParseConfig([]byte(`{"counters": {}}`))
+	assert.ErrorIs(t, err, internal.ErrInvalidMetricsConfig)
+
+	reg := metrics.NewRegistry()
+	reg.NewCounter("taken_total", "")
+
+	for _, cfg := range []Config{
+		{Counters: []CounterConfig{{Name: "bad-name"}}},
+		{Counters: []CounterConfig{{Name: "taken_total"}}},
+		{Counters: []CounterConfig{{Name: "a"}}, Histograms: []HistogramConfig{{Name: "a"}}},
+		{Histograms: []HistogramConfig{{Name: "b", Buckets: []float64{1, 0.1}}}},
+	} {
+		_, err := NewRecorder(cfg, reg)
+		assert.ErrorIs(t, err, internal.ErrInvalidMetricsConfig, "%+v", cfg)
+	}
+	assert.False(t, reg.Has("a"))
+}
@@ -0,0 +1,343 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package metrics keeps counters, gauges and histograms and writes them
+// in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metric types.
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// DefaultBuckets are upper bounds of histogram buckets suited to
+// durations in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	nameRe  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// ValidName reports whether name is a valid metric name.
+func ValidName(name string) bool {
+	return nameRe.MatchString(name)
+}
+
+// metric is a family of series of the same name.
+type metric interface {
+	describe() *desc
+	write(b *strings.Builder)
+}
+
+// desc describes a metric.
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d *desc) describe() *desc {
+	return d
+}
+
+// Registry holds metrics and writes them in the order they are created.
+// Creating a metric with an invalid or taken name or invalid label names
+// panics, as names are expected to be constant. It is safe for
+// concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Has reports whether a metric of the given name is registered.
+func (r *Registry) Has(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.names[name]
+}
+
+func (r *Registry) register(m metric) {
+	d := m.describe()
+	if !ValidName(d.name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", d.name))
+	}
+	for _, label := range d.labels {
+		if !labelRe.MatchString(label) || strings.HasPrefix(label, "__") ||
+			(d.typ == TypeHistogram && label == "le") {
+			panic(fmt.Sprintf("metrics: invalid label name %q of %s", label, d.name))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[d.name] {
+		panic(fmt.Sprintf("metrics: duplicate metric %s", d.name))
+	}
+	r.names[d.name] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// NewCounter registers a counter with series of the given label names.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{
+		desc:   desc{name: name, help: help, typ: TypeCounter, labels: labels},
+		series: make(map[string]*series),
+	}
+	r.register(c)
+	return c
+}
+
+// NewHistogram registers a histogram with the given upper bounds of
+// buckets, in increasing order, and series of the given label names.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if !slices.IsSorted(buckets) {
+		panic(fmt.Sprintf("metrics: unsorted buckets of %s", name))
+	}
+	h := &Histogram{
+		desc:    desc{name: name, help: help, typ: TypeHistogram, labels: labels},
+		buckets: slices.Clone(buckets),
+		series:  make(map[string]*histogramSeries),
+	}
+	r.register(h)
+	return h
+}
+
+// NewCounterFunc registers a counter of the value returned by fn,
+// called whenever metrics are written.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc: desc{name: name, help: help, typ: TypeCounter}, fn: fn})
+}
+
+// NewGaugeFunc registers a gauge of the value returned by fn,
+// called whenever metrics are written.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc: desc{name: name, help: help, typ: TypeGauge}, fn: fn})
+}
+
+// Write writes the metrics to w in the text exposition format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+
+	var b strings.Builder
+	for _, m := range metrics {
+		d := m.describe()
+		if d.help != "" {
+			fmt.Fprintf(&b, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+		}
+		fmt.Fprintf(&b, "# TYPE %s %s\n", d.name, d.typ)
+		m.write(&b)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// series is a counter series.
+type series struct {
+	labels []string
+	value  float64
+}
+
+// Counter is a metric that only goes up. Methods of a nil counter
+// do nothing.
+type Counter struct {
+	desc
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// Inc adds one to the series of the given label values.
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add adds v, which must not be negative, to the series of the given
+// label values.
+func (c *Counter) Add(v float64, labels ...string) {
+	if c == nil || v < 0 {
+		return
+	}
+	key := seriesKey(&c.desc, labels)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &series{labels: slices.Clone(labels)}
+		c.series[key] = s
+	}
+	s.value += v
+}
+
+func (c *Counter) write(b *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		writeSample(b, c.name, c.labels, s.labels, "", "", s.value)
+	}
+}
+
+// histogramSeries is a histogram series.
+type histogramSeries struct {
+	labels []string
+	// Number of observations in each bucket, not cumulative,
+	// and beyond the last bucket.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Histogram counts observations in buckets. Methods of a nil histogram
+// do nothing.
+type Histogram struct {
+	desc
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+// Observe adds v to the series of the given label values.
+func (h *Histogram) Observe(v float64, labels ...string) {
+	if h == nil {
+		return
+	}
+	key := seriesKey(&h.desc, labels)
+	i := sort.SearchFloat64s(h.buckets, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labels: slices.Clone(labels), counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+	s.counts[i]++
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) write(b *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(b, h.name+"_bucket", h.labels, s.labels, "le", formatFloat(bound), float64(cumulative))
+		}
+		writeSample(b, h.name+"_bucket", h.labels, s.labels, "le", "+Inf", float64(s.count))
+		writeSample(b, h.name+"_sum", h.labels, s.labels, "", "", s.sum)
+		writeSample(b, h.name+"_count", h.labels, s.labels, "", "", float64(s.count))
+	}
+}
+
+// funcMetric is a metric of a single series read from a function.
+type funcMetric struct {
+	desc
+	fn func() float64
+}
+
+func (m *funcMetric) write(b *strings.Builder) {
+	writeSample(b, m.name, nil, nil, "", "", m.fn())
+}
+
+// seriesKey returns the key of the series of label values, which must
+// be as many as label names of d.
+func seriesKey(d *desc, labels []string) string {
+	if len(labels) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %d label values for %d labels of %s", len(labels), len(d.labels), d.name))
+	}
+	return strings.Join(labels, "\xff")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// writeSample writes a sample line with the given labels and an extra
+// label if extraName is set.
+func writeSample(b *strings.Builder, name string, names, values []string, extraName, extraValue string, v float64) {
+	b.WriteString(name)
+	if len(names) != 0 || extraName != "" {
+		b.WriteByte('{')
+		for i, label := range names {
+			if i != 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, "%s=\"%s\"", label, escapeLabel(values[i]))
+		}
+		if extraName != "" {
+			if len(names) != 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, "%s=\"%s\"", extraName, extraValue)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(v))
+	b.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
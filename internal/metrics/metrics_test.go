@@ -0,0 +1,96 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func write(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	require.NoError(t, r.Write(&b))
+	return b.String()
+}
+
+func TestRegistry_Write(t *testing.T) {
+	r := NewRegistry()
+
+	c := r.NewCounter("ingested_total", "Logs ingested\nby source.", "source")
+	c.Inc("http")
+	c.Add(2, "websocket")
+	c.Add(-1, "http")
+	c.Inc(`a"b\c`)
+
+	h := r.NewHistogram("write_seconds", "Write latency.", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.1)
+	h.Observe(5)
+
+	r.NewGaugeFunc("size", "Logs kept.", func() float64 { return 42 })
+	r.NewCounterFunc("inf_total", "Infinite.", func() float64 { return math.Inf(1) })
+
+	assert.Equal(t, `# HELP ingested_total Logs ingested\nby source.
+# TYPE ingested_total counter
+ingested_total{source="a\"b\\c"} 1
+ingested_total{source="http"} 1
+ingested_total{source="websocket"} 2
+# HELP write_seconds Write latency.
+# TYPE write_seconds histogram
+write_seconds_bucket{le="0.1"} 2
+write_seconds_bucket{le="1"} 2
+write_seconds_bucket{le="+Inf"} 3
+write_seconds_sum 5.15
+write_seconds_count 3
+# HELP size Logs kept.
+# TYPE size gauge
+size 42
+# HELP inf_total Infinite.
+# TYPE inf_total counter
+inf_total +Inf
+`, write(t, r))
+}
+
+func TestHistogram_Labels(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("d", "", []float64{1}, "op")
+	h.Observe(2, "put")
+
+	assert.Equal(t, `# TYPE d histogram
+d_bucket{op="put",le="1"} 0
+d_bucket{op="put",le="+Inf"} 1
+d_sum{op="put"} 2
+d_count{op="put"} 1
+`, write(t, r))
+}
+
+func TestRegistry_Invalid(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("a_total", "")
+	assert.True(t, r.Has("a_total"))
+	assert.False(t, r.Has("b_total"))
+
+	assert.Panics(t, func() { r.NewCounter("a_total", "") })
+	assert.Panics(t, func() { r.NewCounter("1a", "") })
+	assert.Panics(t, func() { r.NewCounter("b", "", "bad-label") })
+	assert.Panics(t, func() { r.NewHistogram("c", "", []float64{1}, "le") })
+	assert.Panics(t, func() { r.NewHistogram("d", "", []float64{2, 1}) })
+
+	c := r.NewCounter("e_total", "", "x")
+	assert.Panics(t, func() { c.Inc() })
+
+	var nilCounter *Counter
+	nilCounter.Inc()
+	var nilHistogram *Histogram
+	nilHistogram.Observe(1)
+
+	assert.True(t, ValidName("logcrunch:x_total"))
+	assert.False(t, ValidName("x-total"))
+}
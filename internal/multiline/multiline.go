@@ -0,0 +1,169 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package multiline stitches logs of plain-text lines, such as the
+// lines of a stack trace, back into single logs before they are stored.
+package multiline
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+const (
+	// DefaultTimeout is how long a log waits for continuation lines.
+	DefaultTimeout = time.Second
+
+	// DefaultMaxLines is the maximum number of lines stitched into a log.
+	DefaultMaxLines = 500
+
+	// StackTrace names the preset continuation pattern of stack traces.
+	StackTrace = "stacktrace"
+
+	// StackAttr is the attribute holding all lines of a stitched log.
+	StackAttr = "stack"
+)
+
+// stackTracePattern matches continuation lines of Go panics,
+// Java stack traces and Python tracebacks.
+const stackTracePattern = `^(\s` + // indented frames
+	`|Caused by: |\.\.\. \d+ (more|common frames omitted)` + // Java
+	`|goroutine \d+ \[|created by |[^\s(]+\(.*\)$` + // Go
+	`|Traceback \(most recent call last\):|[\w.]+(Error|Exception)(: |$))` // Python
+
+// Config are the rules of stitching lines into logs.
+//
+// A line continues the pending log of its source if it matches
+// Continue and does not match Start. Without Continue, every line not
+// matching Start continues the pending log. A pending log is stored once
+// a line does not continue it or no line continues it within Timeout.
+type Config struct {
+	Start    *regexp.Regexp
+	Continue *regexp.Regexp
+	Timeout  time.Duration
+	MaxLines int
+}
+
+// NewConfig returns a config of the start and continuation patterns.
+// The continuation pattern may name the StackTrace preset.
+// Zero timeout means DefaultTimeout.
+func NewConfig(start, cont string, timeout time.Duration) (Config, error) {
+	cfg := Config{
+		Timeout:  timeout,
+		MaxLines: DefaultMaxLines,
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cont == StackTrace {
+		cont = stackTracePattern
+	}
+
+	var err error
+	if start != "" {
+		if cfg.Start, err = regexp.Compile(start); err != nil {
+			return cfg, fmt.Errorf("invalid start pattern %q: %w", start, err)
+		}
+	}
+	if cont != "" {
+		if cfg.Continue, err = regexp.Compile(cont); err != nil {
+			return cfg, fmt.Errorf("invalid continuation pattern %q: %w", cont, err)
+		}
+	}
+	return cfg, nil
+}
+
+func (c *Config) continues(line string) bool {
+	if c.Start != nil && c.Start.MatchString(line) {
+		return false
+	}
+	if c.Continue != nil {
+		return c.Continue.MatchString(line)
+	}
+	return c.Start != nil
+}
+
+// pending is a log waiting for continuation lines.
+type pending struct {
+	log   internal.Log
+	lines []string
+	timer *time.Timer
+}
+
+// Aggregator stitches logs of the same source into one log whose
+// message is the first line and whose StackAttr attribute holds all
+// lines. It is safe for concurrent use.
+type Aggregator struct {
+	cfg  Config
+	emit func(log internal.Log)
+
+	mu      sync.Mutex
+	pending map[string]*pending
+}
+
+// New returns an aggregator passing stitched logs to emit.
+// emit is called with logs in the order they are complete.
+func New(cfg Config, emit func(log internal.Log)) *Aggregator {
+	return &Aggregator{
+		cfg:     cfg,
+		emit:    emit,
+		pending: make(map[string]*pending),
+	}
+}
+
+// Add adds a log of a single line read from source, such as the path
+// of a file. Lines of different sources are never stitched together.
+func (a *Aggregator) Add(source string, log internal.Log) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := source
+	if p, ok := a.pending[key]; ok {
+		if len(p.lines) < a.cfg.MaxLines && a.cfg.continues(log.Message) {
+			p.lines = append(p.lines, log.Message)
+			p.timer.Reset(a.cfg.Timeout)
+			return
+		}
+		a.flush(key, p)
+	}
+
+	p := &pending{log: log, lines: []string{log.Message}}
+	p.timer = time.AfterFunc(a.cfg.Timeout, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		// The log may have been flushed while the timer fired.
+		if a.pending[key] == p {
+			a.flush(key, p)
+		}
+	})
+	a.pending[key] = p
+}
+
+// Flush stores all pending logs, as when their sources end.
+func (a *Aggregator) Flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, key := range slices.Sorted(maps.Keys(a.pending)) {
+		a.flush(key, a.pending[key])
+	}
+}
+
+// flush emits the pending log of key. Must be called with a.mu held.
+func (a *Aggregator) flush(key string, p *pending) {
+	p.timer.Stop()
+	delete(a.pending, key)
+
+	if len(p.lines) > 1 {
+		p.log.SetAttr(StackAttr, strings.Join(p.lines, "\n"))
+	}
+	a.emit(p.log)
+}
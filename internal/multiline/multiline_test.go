@@ -0,0 +1,190 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package multiline
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector collects logs emitted by an aggregator.
+type collector struct {
+	mu   sync.Mutex
+	logs []internal.Log
+}
+
+func (c *collector) emit(log internal.Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs = append(c.logs, log)
+}
+
+func (c *collector) messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var msgs []string
+	for _, log := range c.logs {
+		msgs = append(msgs, log.Message)
+	}
+	return msgs
+}
+
+// stitch adds lines of the producer, read from a source of the same
+// name, and flushes the aggregator.
+func stitch(t *testing.T, cfg Config, producerID string, lines ...string) []internal.Log {
+	t.Helper()
+
+	var c collector
+	a := New(cfg, c.emit)
+	for i, line := range lines {
+		a.Add(producerID, internal.Log{
+			ID:      internal.LogID{ProducerID: producerID, SequenceNumber: i + 1},
+			Message: line,
+		})
+	}
+	a.Flush()
+	return c.logs
+}
+
+func TestAggregator_StackTrace(t *testing.T) {
+	cfg, err := NewConfig("", StackTrace, time.Minute)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name: "go",
+			lines: []string{
+				"panic: boom",
+				"goroutine 1 [running]:",
+				"main.main()",
+				"\t/src/main.go:5 +0x1d",
+				"exit status 2",
+			},
+			want: []string{"panic: boom", "exit status 2"},
+		},
+		{
+			name: "java",
+			lines: []string{
+				`Exception in thread "main" java.lang.IllegalStateException: bad`,
+				"\tat com.example.App.run(App.java:10)",
+				"Caused by: java.io.IOException: closed",
+				"\t... 3 more",
+				"next",
+			},
+			want: []string{`Exception in thread "main" java.lang.IllegalStateException: bad`, "next"},
+		},
+		{
+			name: "python",
+			lines: []string{
+				"request failed",
+				"Traceback (most recent call last):",
+				`  File "app.py", line 3, in <module>`,
+				"ValueError: bad value",
+				"done",
+			},
+			want: []string{"request failed", "done"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := stitch(t, cfg, "app", tt.lines...)
+			require.Len(t, logs, 2)
+
+			assert.Equal(t, tt.want[0], logs[0].Message)
+			assert.Equal(t, internal.LogID{ProducerID: "app", SequenceNumber: 1}, logs[0].ID)
+			stack, ok := logs[0].Attr("attrs." + StackAttr)
+			assert.True(t, ok)
+			assert.Equal(t, strings.Join(tt.lines[:len(tt.lines)-1], "\n"), stack)
+
+			assert.Equal(t, tt.want[1], logs[1].Message)
+			assert.NotContains(t, logs[1].Attrs, StackAttr)
+		})
+	}
+}
+
+func TestAggregator_Start(t *testing.T) {
+	cfg, err := NewConfig(`^\d{4}-`, "", time.Minute)
+	require.NoError(t, err)
+
+	logs := stitch(t, cfg, "app",
+		"2025-03-01 one",
+		"continued",
+		"2025-03-01 two",
+	)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "2025-03-01 one\ncontinued", logs[0].Attrs[StackAttr])
+	assert.Equal(t, "2025-03-01 two", logs[1].Message)
+}
+
+func TestAggregator_MaxLines(t *testing.T) {
+	cfg, err := NewConfig("", `^\s`, time.Minute)
+	require.NoError(t, err)
+	cfg.MaxLines = 2
+
+	logs := stitch(t, cfg, "app", "a", " b", " c")
+	require.Len(t, logs, 2)
+	assert.Equal(t, "a\n b", logs[0].Attrs[StackAttr])
+	assert.Equal(t, " c", logs[1].Message)
+}
+
+func TestAggregator_Sources(t *testing.T) {
+	cfg, err := NewConfig("", `^\s`, time.Minute)
+	require.NoError(t, err)
+
+	// Files of the same name in different directories share a producer id.
+	app := internal.LogID{ProducerID: "app.log"}
+
+	var c collector
+	a := New(cfg, c.emit)
+	a.Add("/a/app.log", internal.Log{ID: app, Message: "a1"})
+	a.Add("/b/app.log", internal.Log{ID: app, Message: "b1"})
+	a.Add("/a/app.log", internal.Log{ID: app, Message: " a2"})
+	a.Add("/b/app.log", internal.Log{ID: app, Message: " b2"})
+	a.Flush()
+
+	assert.Equal(t, []string{"a1", "b1"}, c.messages())
+	assert.Equal(t, "a1\n a2", c.logs[0].Attrs[StackAttr])
+	assert.Equal(t, "b1\n b2", c.logs[1].Attrs[StackAttr])
+}
+
+func TestAggregator_Timeout(t *testing.T) {
+	cfg, err := NewConfig("", `^\s`, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	var c collector
+	a := New(cfg, c.emit)
+	a.Add("", internal.Log{Message: "first"})
+	a.Add("", internal.Log{Message: " more"})
+
+	assert.Eventually(t, func() bool { return len(c.messages()) == 1 }, time.Second, time.Millisecond)
+
+	// Too late to continue the stored log.
+	a.Add("", internal.Log{Message: " late"})
+	a.Flush()
+	assert.Equal(t, []string{"first", " late"}, c.messages())
+}
+
+func TestNewConfig_Invalid(t *testing.T) {
+	_, err := NewConfig("(", "", 0)
+	assert.Error(t, err)
+
+	_, err = NewConfig("", "[", 0)
+	assert.Error(t, err)
+
+	cfg, err := NewConfig("", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, cfg.Timeout)
+}
@@ -0,0 +1,246 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// hexID is a trace or span id. It is encoded as bytes in protobuf,
+// and as a hex string in JSON.
+type hexID []byte
+
+func (id *hexID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("failed to unmarshal id: %w", err)
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("failed to decode id %q: %w", s, err)
+	}
+	*id = b
+	return nil
+}
+
+// String returns the id in lowercase hex, or an empty string
+// if the id is not set or all zero, which OTLP treats as invalid.
+func (id hexID) String() string {
+	for _, b := range id {
+		if b != 0 {
+			return hex.EncodeToString(id)
+		}
+	}
+	return ""
+}
+
+// jsonInt is a 64-bit integer, encoded as a decimal string
+// or a number in JSON.
+type jsonInt[T int64 | uint64] struct {
+	v T
+}
+
+func (i *jsonInt[T]) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+
+	var err error
+	switch v := any(&i.v).(type) {
+	case *int64:
+		*v, err = strconv.ParseInt(s, 10, 64)
+	case *uint64:
+		*v, err = strconv.ParseUint(s, 10, 64)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse integer %s: %w", data, err)
+	}
+	return nil
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+func (kv *keyValue) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) (err error) {
+		switch field {
+		case 1:
+			kv.Key, err = d.string(typ)
+		case 2:
+			err = d.message(typ, &kv.Value)
+		default:
+			err = d.skip(typ)
+		}
+		return err
+	})
+}
+
+type keyValueList struct {
+	Values []keyValue `json:"values"`
+}
+
+func (l *keyValueList) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		if field == 1 {
+			return repeated(d, typ, &l.Values)
+		}
+		return d.skip(typ)
+	})
+}
+
+type arrayValue struct {
+	Values []anyValue `json:"values"`
+}
+
+func (a *arrayValue) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		if field == 1 {
+			return repeated(d, typ, &a.Values)
+		}
+		return d.skip(typ)
+	})
+}
+
+// anyValue holds one of its fields, or none for an empty value.
+type anyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *jsonInt[int64] `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *arrayValue     `json:"arrayValue,omitempty"`
+	KvlistValue *keyValueList   `json:"kvlistValue,omitempty"`
+	BytesValue  []byte          `json:"bytesValue,omitempty"`
+}
+
+func (v *anyValue) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		switch field {
+		case 1:
+			s, err := d.string(typ)
+			v.StringValue = &s
+			return err
+		case 2:
+			n, err := d.varint(typ)
+			b := n != 0
+			v.BoolValue = &b
+			return err
+		case 3:
+			n, err := d.varint(typ)
+			v.IntValue = &jsonInt[int64]{int64(n)}
+			return err
+		case 4:
+			f, err := d.double(typ)
+			v.DoubleValue = &f
+			return err
+		case 5:
+			v.ArrayValue = &arrayValue{}
+			return d.message(typ, v.ArrayValue)
+		case 6:
+			v.KvlistValue = &keyValueList{}
+			return d.message(typ, v.KvlistValue)
+		case 7:
+			b, err := d.bytes(typ)
+			v.BytesValue = append([]byte{}, b...)
+			return err
+		default:
+			return d.skip(typ)
+		}
+	})
+}
+
+// value converts v, nested depth values deep, to the representation
+// of JSON decoded log attributes: numbers are float64, bytes are base64
+// encoded strings.
+func (v *anyValue) value(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: values nested over %d deep", internal.ErrValueTooDeep, maxDepth)
+	}
+
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue, nil
+	case v.BoolValue != nil:
+		return *v.BoolValue, nil
+	case v.IntValue != nil:
+		return float64(v.IntValue.v), nil
+	case v.DoubleValue != nil:
+		if math.IsNaN(*v.DoubleValue) || math.IsInf(*v.DoubleValue, 0) {
+			// Not representable in JSON.
+			return strconv.FormatFloat(*v.DoubleValue, 'g', -1, 64), nil
+		}
+		return *v.DoubleValue, nil
+	case v.ArrayValue != nil:
+		res := make([]any, len(v.ArrayValue.Values))
+		for i := range v.ArrayValue.Values {
+			var err error
+			if res[i], err = v.ArrayValue.Values[i].value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case v.KvlistValue != nil:
+		return attributes(v.KvlistValue.Values, depth+1)
+	case v.BytesValue != nil:
+		return base64.StdEncoding.EncodeToString(v.BytesValue), nil
+	default:
+		return nil, nil
+	}
+}
+
+// attributes converts kvs, nested depth values deep, to log attributes.
+func attributes(kvs []keyValue, depth int) (map[string]any, error) {
+	res := make(map[string]any, len(kvs))
+	for i := range kvs {
+		v, err := kvs[i].Value.value(depth)
+		if err != nil {
+			return nil, err
+		}
+		res[kvs[i].Key] = v
+	}
+	return res, nil
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+func (r *resource) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		if field == 1 {
+			return repeated(d, typ, &r.Attributes)
+		}
+		return d.skip(typ)
+	})
+}
+
+type instrumentationScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func (s *instrumentationScope) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) (err error) {
+		switch field {
+		case 1:
+			s.Name, err = d.string(typ)
+		case 2:
+			s.Version, err = d.string(typ)
+		default:
+			err = d.skip(typ)
+		}
+		return err
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package otlp
+
+import (
+	"encoding/json"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+type exportLogsServiceRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+func (r *exportLogsServiceRequest) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		if field == 1 {
+			return repeated(d, typ, &r.ResourceLogs)
+		}
+		return d.skip(typ)
+	})
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+func (r *resourceLogs) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		switch field {
+		case 1:
+			return d.message(typ, &r.Resource)
+		case 2:
+			return repeated(d, typ, &r.ScopeLogs)
+		default:
+			return d.skip(typ)
+		}
+	})
+}
+
+type scopeLogs struct {
+	Scope      instrumentationScope `json:"scope"`
+	LogRecords []logRecord          `json:"logRecords"`
+}
+
+func (s *scopeLogs) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		switch field {
+		case 1:
+			return d.message(typ, &s.Scope)
+		case 2:
+			return repeated(d, typ, &s.LogRecords)
+		default:
+			return d.skip(typ)
+		}
+	})
+}
+
+type logRecord struct {
+	TimeUnixNano         jsonInt[uint64] `json:"timeUnixNano"`
+	ObservedTimeUnixNano jsonInt[uint64] `json:"observedTimeUnixNano"`
+	SeverityNumber       int             `json:"severityNumber"`
+	SeverityText         string          `json:"severityText"`
+	Body                 anyValue        `json:"body"`
+	Attributes           []keyValue      `json:"attributes"`
+	TraceID              hexID           `json:"traceId"`
+	SpanID               hexID           `json:"spanId"`
+}
+
+func (r *logRecord) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		switch field {
+		case 1:
+			v, err := d.fixed64(typ)
+			r.TimeUnixNano.v = v
+			return err
+		case 2:
+			v, err := d.varint(typ)
+			r.SeverityNumber = int(v)
+			return err
+		case 3:
+			v, err := d.string(typ)
+			r.SeverityText = v
+			return err
+		case 5:
+			return d.message(typ, &r.Body)
+		case 6:
+			return repeated(d, typ, &r.Attributes)
+		case 9:
+			v, err := d.bytes(typ)
+			r.TraceID = append(hexID{}, v...)
+			return err
+		case 10:
+			v, err := d.bytes(typ)
+			r.SpanID = append(hexID{}, v...)
+			return err
+		case 11:
+			v, err := d.fixed64(typ)
+			r.ObservedTimeUnixNano.v = v
+			return err
+		default:
+			return d.skip(typ)
+		}
+	})
+}
+
+// message returns the body of r as a log message.
+// Structured bodies are encoded as JSON.
+func (r *logRecord) message() (string, error) {
+	v, err := r.Body.value(0)
+	if err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", nil
+	}
+	return string(data), nil
+}
+
+// DecodeLogs decodes an OTLP logs export request to info logs.
+func DecodeLogs(data []byte, f Format, nextID IDFunc) ([]internal.Log, error) {
+	var req exportLogsServiceRequest
+	if err := decode(data, f, &req); err != nil {
+		return nil, err
+	}
+
+	var logs []internal.Log
+	for i := range req.ResourceLogs {
+		rl := &req.ResourceLogs[i]
+		producerID := rl.Resource.producerID()
+
+		for j := range rl.ScopeLogs {
+			for k := range rl.ScopeLogs[j].LogRecords {
+				r := &rl.ScopeLogs[j].LogRecords[k]
+
+				log, err := newLog(nextID(producerID), &rl.Resource, r.Attributes)
+				if err != nil {
+					return nil, err
+				}
+				log.Timestamp = timestamp(r.TimeUnixNano.v)
+				if r.TimeUnixNano.v == 0 {
+					log.Timestamp = timestamp(r.ObservedTimeUnixNano.v)
+				}
+				log.Level = severityLevel(r.SeverityNumber, r.SeverityText)
+				if log.Message, err = r.message(); err != nil {
+					return nil, err
+				}
+				log.TraceID = r.TraceID.String()
+				log.SpanID = r.SpanID.String()
+
+				logs = append(logs, log)
+			}
+		}
+	}
+	return logs, nil
+}
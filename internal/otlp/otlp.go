@@ -0,0 +1,178 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package otlp decodes OTLP/HTTP export requests into logs.
+//
+// Log records become info logs and spans become metric logs,
+// carrying their trace context. Both protobuf and JSON encodings
+// are supported. Only fields logcrunch has a use for are decoded.
+package otlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+type Format int
+
+const (
+	FormatProtobuf Format = iota + 1
+	FormatJSON
+)
+
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+
+	// DefaultProducerID is the producer of logs from resources
+	// with no service name.
+	DefaultProducerID = "otlp"
+
+	// MaxRequestSize is the maximum size of a decompressed export request.
+	MaxRequestSize = 16 << 20 // 16MB
+)
+
+// FormatOf returns the encoding of a request with the given content type.
+func FormatOf(contentType string) (Format, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0, false
+	}
+
+	switch mediaType {
+	case ContentTypeProtobuf:
+		return FormatProtobuf, true
+	case ContentTypeJSON:
+		return FormatJSON, true
+	default:
+		return 0, false
+	}
+}
+
+// ContentType returns the content type of requests and responses in f.
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return ContentTypeJSON
+	}
+	return ContentTypeProtobuf
+}
+
+// EmptyResponse returns the encoding of an export response
+// with no partial success to report.
+func (f Format) EmptyResponse() []byte {
+	if f == FormatJSON {
+		return []byte("{}")
+	}
+	return nil
+}
+
+// IDFunc returns the id of the next log of a producer.
+// OTLP has no notion of log ids, they are assigned on receipt.
+type IDFunc func(producerID string) internal.LogID
+
+func decode(data []byte, f Format, m protoMessage) error {
+	if f == FormatJSON {
+		if err := json.Unmarshal(data, m); err != nil {
+			return fmt.Errorf("failed to unmarshal OTLP JSON: %w", err)
+		}
+		return nil
+	}
+	return m.unmarshalProto(data, 0)
+}
+
+// producerID returns the service name of r.
+func (r *resource) producerID() string {
+	for i := range r.Attributes {
+		kv := &r.Attributes[i]
+		if kv.Key == "service.name" && kv.Value.StringValue != nil && *kv.Value.StringValue != "" {
+			return *kv.Value.StringValue
+		}
+	}
+	return DefaultProducerID
+}
+
+// Attribute keys of the source code location, current and deprecated.
+var (
+	codeFileKeys     = []string{"code.file.path", "code.filepath"}
+	codeLineKeys     = []string{"code.line.number", "code.lineno"}
+	codeFunctionKeys = []string{"code.function.name", "code.function"}
+)
+
+// newLog returns a log with attributes attrs of a record of res,
+// filling its source code location from attrs.
+func newLog(id internal.LogID, res *resource, attrs []keyValue) (internal.Log, error) {
+	log := internal.Log{ID: id}
+
+	if len(attrs) > 0 {
+		var err error
+		if log.Attrs, err = attributes(attrs, 0); err != nil {
+			return log, err
+		}
+	}
+	if len(res.Attributes) > 0 {
+		if log.Attrs == nil {
+			log.Attrs = make(map[string]any, 1)
+		}
+		if _, ok := log.Attrs["resource"]; !ok {
+			resAttrs, err := attributes(res.Attributes, 0)
+			if err != nil {
+				return log, err
+			}
+			log.Attrs["resource"] = resAttrs
+		}
+	}
+
+	for _, key := range codeFileKeys {
+		if v, ok := log.Attrs[key].(string); ok {
+			log.SourceFile = v
+			break
+		}
+	}
+	for _, key := range codeLineKeys {
+		if v, ok := log.Attrs[key].(float64); ok {
+			log.SourceLine = int(v)
+			break
+		}
+	}
+	for _, key := range codeFunctionKeys {
+		if v, ok := log.Attrs[key].(string); ok {
+			log.SourceFunction = v
+			break
+		}
+	}
+
+	return log, nil
+}
+
+// timestamp converts nanoseconds since the Unix epoch to a timestamp.
+func timestamp(ns uint64) internal.Timestamp {
+	return internal.Timestamp(float64(ns) / 1e9)
+}
+
+// severityLevel maps an OTLP severity to a log level,
+// preferring the severity number.
+func severityLevel(number int, text string) string {
+	switch {
+	case number >= 21:
+		return "fatal"
+	case number >= 17:
+		return "error"
+	case number >= 13:
+		return "warn"
+	case number >= 9:
+		return "info"
+	case number >= 5:
+		return "debug"
+	case number >= 1:
+		return "trace"
+	case text != "":
+		return strings.ToLower(text)
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package otlp
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Minimal protobuf encoding helpers to build test requests.
+
+func tag(field int, typ wireType) []byte {
+	return binary.AppendUvarint(nil, uint64(field)<<3|uint64(typ))
+}
+
+func varintField(field int, v uint64) []byte {
+	return binary.AppendUvarint(tag(field, wireVarint), v)
+}
+
+func fixed64Field(field int, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(tag(field, wireFixed64), v)
+}
+
+func bytesField(field int, parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	res := binary.AppendUvarint(tag(field, wireBytes), uint64(len(b)))
+	return append(res, b...)
+}
+
+func stringField(field int, s string) []byte {
+	return bytesField(field, []byte(s))
+}
+
+func kv(key string, value []byte) []byte {
+	return append(stringField(1, key), bytesField(2, value)...)
+}
+
+func sequentialIDs() IDFunc {
+	seq := make(map[string]int)
+	return func(producerID string) internal.LogID {
+		seq[producerID]++
+		return internal.LogID{ProducerID: producerID, SequenceNumber: seq[producerID]}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	f, ok := FormatOf("application/x-protobuf")
+	assert.True(t, ok)
+	assert.Equal(t, FormatProtobuf, f)
+
+	f, ok = FormatOf("application/json; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, FormatJSON, f)
+
+	_, ok = FormatOf("text/plain")
+	assert.False(t, ok)
+}
+
+func TestDecodeLogs_Protobuf(t *testing.T) {
+	resource := bytesField(1,
+		bytesField(1, kv("service.name", stringField(1, "checkout"))),
+	)
+	record := bytesField(2,
+		fixed64Field(1, 1_500_000_000),
+		varintField(2, 13),
+		stringField(3, "WARNING"),
+		bytesField(5, stringField(1, "low stock")),
+		bytesField(6, kv("count", varintField(3, 3))),
+		bytesField(6, kv("ratio", fixed64Field(4, math.Float64bits(0.5)))),
+		bytesField(6, kv("code.function.name", stringField(1, "Reserve"))),
+		bytesField(9, []byte{0xab, 0xcd}),
+		bytesField(10, []byte{0, 0}),
+		// Unknown fields are skipped.
+		varintField(99, 1),
+	)
+	scope := bytesField(1, stringField(1, "inventory"))
+	req := bytesField(1, resource, bytesField(2, scope, record))
+
+	logs, err := DecodeLogs(req, FormatProtobuf, sequentialIDs())
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+
+	log := logs[0]
+	assert.Equal(t, internal.LogID{ProducerID: "checkout", SequenceNumber: 1}, log.ID)
+	assert.Equal(t, internal.Timestamp(1.5), log.Timestamp)
+	assert.Equal(t, "warn", log.Level)
+	assert.Equal(t, "low stock", log.Message)
+	assert.Equal(t, "Reserve", log.SourceFunction)
+	assert.Equal(t, float64(3), log.Attrs["count"])
+	assert.Equal(t, 0.5, log.Attrs["ratio"])
+	assert.Equal(t, map[string]any{"service.name": "checkout"}, log.Attrs["resource"])
+	assert.Equal(t, "abcd", log.TraceID)
+	assert.Empty(t, log.SpanID, "all zero ids are invalid")
+	assert.Equal(t, internal.LogTypeInfo, log.Type())
+}
+
+func TestDecodeLogs_JSON(t *testing.T) {
+	data := `{"resourceLogs": [{
+		"resource": {"attributes": []},
+		"scopeLogs": [{"logRecords": [{
+			"observedTimeUnixNano": "2000000000",
+			"severityText": "Debug",
+			"body": {"kvlistValue": {"values": [{"key": "user", "value": {"stringValue": "alice"}}]}},
+			"attributes": [
+				{"key": "id", "value": {"intValue": "42"}},
+				{"key": "tags", "value": {"arrayValue": {"values": [{"boolValue": true}]}}}
+			],
+			"traceId": "5b8efff798038103d269b633813fc60c",
+			"spanId": "eee19b7ec3c1b174"
+		}]}]
+	}]}`
+
+	logs, err := DecodeLogs([]byte(data), FormatJSON, sequentialIDs())
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+
+	log := logs[0]
+	assert.Equal(t, internal.LogID{ProducerID: DefaultProducerID, SequenceNumber: 1}, log.ID)
+	assert.Equal(t, internal.Timestamp(2), log.Timestamp)
+	assert.Equal(t, "debug", log.Level)
+	assert.Equal(t, `{"user":"alice"}`, log.Message)
+	assert.Equal(t, float64(42), log.Attrs["id"])
+	assert.Equal(t, []any{true}, log.Attrs["tags"])
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", log.TraceID)
+	assert.Equal(t, "eee19b7ec3c1b174", log.SpanID)
+}
+
+func TestDecodeTraces(t *testing.T) {
+	span := func(spanID, parentSpanID byte, name string, start, end uint64, statusCode uint64) []byte {
+		s := [][]byte{
+			bytesField(1, []byte{1, 2, 3, 4}),
+			bytesField(2, []byte{spanID}),
+			stringField(5, name),
+			fixed64Field(7, start),
+			fixed64Field(8, end),
+			bytesField(15, stringField(2, "boom"), varintField(3, statusCode)),
+		}
+		if parentSpanID != 0 {
+			s = append(s, bytesField(4, []byte{parentSpanID}))
+		}
+		return bytesField(2, s...)
+	}
+	req := bytesField(1, bytesField(2,
+		span(1, 0, "GET /", 1e9, 3e9, 0),
+		span(2, 1, "query", 15e8, 2e9, statusCodeError),
+	))
+
+	logs, err := DecodeTraces(req, FormatProtobuf, sequentialIDs())
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+
+	root, child := logs[0], logs[1]
+	assert.Equal(t, internal.LogTypeMetric, root.Type())
+	assert.Equal(t, "GET /", root.SourceFunction)
+	assert.Equal(t, "info", root.Level)
+	assert.Equal(t, internal.Timestamp(1), root.FunctionCallStartedAt)
+	assert.Equal(t, internal.Timestamp(3), root.FunctionCallEndedAt)
+	assert.Equal(t, "01020304", root.TraceID)
+	assert.Empty(t, root.ParentSpanID)
+
+	assert.Equal(t, 2, child.ID.SequenceNumber)
+	assert.Equal(t, "error", child.Level)
+	assert.Equal(t, "query: boom", child.Message)
+	assert.Equal(t, root.SpanID, child.ParentSpanID)
+}
+
+func TestDecode_Malformed(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"truncated length": {0x0a, 0x05, 0x01},
+		"bad varint":       {0x0a, 0xff},
+		"wrong wire type":  varintField(1, 1),
+		"field zero":       {0x00},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := DecodeLogs(data, FormatProtobuf, sequentialIDs())
+			assert.ErrorIs(t, err, internal.ErrMalformedProtobuf)
+		})
+	}
+
+	_, err := DecodeTraces([]byte(`{"resourceSpans": [{"scopeSpans": [{"spans": [{"traceId": "xyz"}]}]}]}`),
+		FormatJSON, sequentialIDs())
+	assert.Error(t, err)
+}
+
+func TestDecodeLogs_Nested(t *testing.T) {
+	// nested returns a request with a body of arrays nested n deep.
+	nested := func(n int) []byte {
+		v := stringField(1, "deep")
+		for range n {
+			v = bytesField(5, bytesField(1, v))
+		}
+		return bytesField(1, bytesField(2, bytesField(2, bytesField(5, v))))
+	}
+
+	logs, err := DecodeLogs(nested(10), FormatProtobuf, sequentialIDs())
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, strings.Repeat("[", 10)+`"deep"`+strings.Repeat("]", 10), logs[0].Message)
+
+	_, err = DecodeLogs(nested(10_000), FormatProtobuf, sequentialIDs())
+	assert.ErrorIs(t, err, internal.ErrMalformedProtobuf)
+	assert.ErrorIs(t, err, internal.ErrValueTooDeep)
+
+	n := maxDepth + 1
+	data := `{"resourceLogs": [{"scopeLogs": [{"logRecords": [{"body": ` +
+		strings.Repeat(`{"arrayValue": {"values": [`, n) + `{"stringValue": "deep"}` + strings.Repeat(`]}}`, n) +
+		`}]}]}]}`
+	_, err = DecodeLogs([]byte(data), FormatJSON, sequentialIDs())
+	assert.ErrorIs(t, err, internal.ErrValueTooDeep)
+}
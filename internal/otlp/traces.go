@@ -0,0 +1,177 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package otlp
+
+import (
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// statusCodeError is the status code of spans that failed.
+const statusCodeError = 2
+
+type exportTraceServiceRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+func (r *exportTraceServiceRequest) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		if field == 1 {
+			return repeated(d, typ, &r.ResourceSpans)
+		}
+		return d.skip(typ)
+	})
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+func (r *resourceSpans) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		switch field {
+		case 1:
+			return d.message(typ, &r.Resource)
+		case 2:
+			return repeated(d, typ, &r.ScopeSpans)
+		default:
+			return d.skip(typ)
+		}
+	})
+}
+
+type scopeSpans struct {
+	Scope instrumentationScope `json:"scope"`
+	Spans []span               `json:"spans"`
+}
+
+func (s *scopeSpans) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		switch field {
+		case 1:
+			return d.message(typ, &s.Scope)
+		case 2:
+			return repeated(d, typ, &s.Spans)
+		default:
+			return d.skip(typ)
+		}
+	})
+}
+
+type span struct {
+	TraceID           hexID           `json:"traceId"`
+	SpanID            hexID           `json:"spanId"`
+	ParentSpanID      hexID           `json:"parentSpanId"`
+	Name              string          `json:"name"`
+	StartTimeUnixNano jsonInt[uint64] `json:"startTimeUnixNano"`
+	EndTimeUnixNano   jsonInt[uint64] `json:"endTimeUnixNano"`
+	Attributes        []keyValue      `json:"attributes"`
+	Status            status          `json:"status"`
+}
+
+func (s *span) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		switch field {
+		case 1:
+			v, err := d.bytes(typ)
+			s.TraceID = append(hexID{}, v...)
+			return err
+		case 2:
+			v, err := d.bytes(typ)
+			s.SpanID = append(hexID{}, v...)
+			return err
+		case 4:
+			v, err := d.bytes(typ)
+			s.ParentSpanID = append(hexID{}, v...)
+			return err
+		case 5:
+			v, err := d.string(typ)
+			s.Name = v
+			return err
+		case 7:
+			v, err := d.fixed64(typ)
+			s.StartTimeUnixNano.v = v
+			return err
+		case 8:
+			v, err := d.fixed64(typ)
+			s.EndTimeUnixNano.v = v
+			return err
+		case 9:
+			return repeated(d, typ, &s.Attributes)
+		case 15:
+			return d.message(typ, &s.Status)
+		default:
+			return d.skip(typ)
+		}
+	})
+}
+
+type status struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+func (s *status) unmarshalProto(b []byte, depth int) error {
+	return decodeMessage(b, depth, func(d *decoder, field int, typ wireType) error {
+		switch field {
+		case 2:
+			v, err := d.string(typ)
+			s.Message = v
+			return err
+		case 3:
+			v, err := d.varint(typ)
+			s.Code = int(v)
+			return err
+		default:
+			return d.skip(typ)
+		}
+	})
+}
+
+// DecodeTraces decodes an OTLP traces export request to metric logs,
+// one per span. Failed spans are logged at the error level.
+func DecodeTraces(data []byte, f Format, nextID IDFunc) ([]internal.Log, error) {
+	var req exportTraceServiceRequest
+	if err := decode(data, f, &req); err != nil {
+		return nil, err
+	}
+
+	var logs []internal.Log
+	for i := range req.ResourceSpans {
+		rs := &req.ResourceSpans[i]
+		producerID := rs.Resource.producerID()
+
+		for j := range rs.ScopeSpans {
+			for k := range rs.ScopeSpans[j].Spans {
+				s := &rs.ScopeSpans[j].Spans[k]
+
+				log, err := newLog(nextID(producerID), &rs.Resource, s.Attributes)
+				if err != nil {
+					return nil, err
+				}
+				log.Timestamp = timestamp(s.EndTimeUnixNano.v)
+				log.Level = "info"
+				log.Message = s.Name
+				if s.Status.Code == statusCodeError {
+					log.Level = "error"
+					if s.Status.Message != "" {
+						log.Message = s.Name + ": " + s.Status.Message
+					}
+				}
+				if log.SourceFunction == "" {
+					log.SourceFunction = s.Name
+				}
+				log.FunctionCallStartedAt = timestamp(s.StartTimeUnixNano.v)
+				log.FunctionCallEndedAt = timestamp(s.EndTimeUnixNano.v)
+				log.TraceID = s.TraceID.String()
+				log.SpanID = s.SpanID.String()
+				log.ParentSpanID = s.ParentSpanID.String()
+
+				logs = append(logs, log)
+			}
+		}
+	}
+	return logs, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package otlp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// Protobuf wire types.
+type wireType int
+
+const (
+	wireVarint  wireType = 0
+	wireFixed64 wireType = 1
+	wireBytes   wireType = 2
+	wireFixed32 wireType = 5
+)
+
+// maxDepth limits how deeply messages and attribute values nest.
+// They are decoded recursively, and arbitrarily deep nesting would
+// exhaust the stack.
+const maxDepth = 100
+
+// decoder reads fields of a single protobuf message.
+// Only the subset of the wire format used by OTLP is supported.
+type decoder struct {
+	buf []byte
+	// Nesting depth of the message, 0 for the top-level one.
+	depth int
+}
+
+// decodeMessage calls fn for every field of the message in b,
+// nested depth messages deep. fn must consume the field value,
+// or skip it.
+func decodeMessage(b []byte, depth int, fn func(d *decoder, field int, typ wireType) error) error {
+	if depth > maxDepth {
+		return fmt.Errorf("%w: %w: messages nested over %d deep",
+			internal.ErrMalformedProtobuf, internal.ErrValueTooDeep, maxDepth)
+	}
+
+	d := &decoder{buf: b, depth: depth}
+	for len(d.buf) > 0 {
+		tag, err := d.rawVarint()
+		if err != nil {
+			return err
+		}
+
+		field, typ := int(tag>>3), wireType(tag&7)
+		if field <= 0 {
+			return fmt.Errorf("%w: invalid field number %d", internal.ErrMalformedProtobuf, field)
+		}
+		if err := fn(d, field, typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decoder) rawVarint() (uint64, error) {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: invalid varint", internal.ErrMalformedProtobuf)
+	}
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+func (d *decoder) expect(typ, want wireType) error {
+	if typ != want {
+		return fmt.Errorf("%w: unexpected wire type %d, want %d", internal.ErrMalformedProtobuf, typ, want)
+	}
+	return nil
+}
+
+func (d *decoder) varint(typ wireType) (uint64, error) {
+	if err := d.expect(typ, wireVarint); err != nil {
+		return 0, err
+	}
+	return d.rawVarint()
+}
+
+func (d *decoder) fixed64(typ wireType) (uint64, error) {
+	if err := d.expect(typ, wireFixed64); err != nil {
+		return 0, err
+	}
+	if len(d.buf) < 8 {
+		return 0, fmt.Errorf("%w: truncated fixed64", internal.ErrMalformedProtobuf)
+	}
+	v := binary.LittleEndian.Uint64(d.buf)
+	d.buf = d.buf[8:]
+	return v, nil
+}
+
+func (d *decoder) double(typ wireType) (float64, error) {
+	v, err := d.fixed64(typ)
+	return math.Float64frombits(v), err
+}
+
+// bytes returns a length-delimited value, sharing memory with the message.
+func (d *decoder) bytes(typ wireType) ([]byte, error) {
+	if err := d.expect(typ, wireBytes); err != nil {
+		return nil, err
+	}
+	n, err := d.rawVarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.buf)) {
+		return nil, fmt.Errorf("%w: truncated length-delimited field", internal.ErrMalformedProtobuf)
+	}
+	v := d.buf[:n]
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+func (d *decoder) string(typ wireType) (string, error) {
+	v, err := d.bytes(typ)
+	return string(v), err
+}
+
+// message decodes an embedded message with m.
+func (d *decoder) message(typ wireType, m protoMessage) error {
+	b, err := d.bytes(typ)
+	if err != nil {
+		return err
+	}
+	return m.unmarshalProto(b, d.depth+1)
+}
+
+func (d *decoder) skip(typ wireType) error {
+	var n int
+	switch typ {
+	case wireVarint:
+		_, err := d.rawVarint()
+		return err
+	case wireBytes:
+		_, err := d.bytes(typ)
+		return err
+	case wireFixed64:
+		n = 8
+	case wireFixed32:
+		n = 4
+	default:
+		// Groups are deprecated and never used by OTLP.
+		return fmt.Errorf("%w: unsupported wire type %d", internal.ErrMalformedProtobuf, typ)
+	}
+
+	if len(d.buf) < n {
+		return fmt.Errorf("%w: truncated field", internal.ErrMalformedProtobuf)
+	}
+	d.buf = d.buf[n:]
+	return nil
+}
+
+type protoMessage interface {
+	// unmarshalProto decodes the message in b, nested depth messages deep.
+	unmarshalProto(b []byte, depth int) error
+}
+
+// repeated decodes an embedded message appended to s.
+func repeated[T any, PT interface {
+	*T
+	protoMessage
+}](d *decoder, typ wireType, s *[]T) error {
+	var m T
+	if err := d.message(typ, PT(&m)); err != nil {
+		return err
+	}
+	*s = append(*s, m)
+	return nil
+}
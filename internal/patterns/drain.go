@@ -0,0 +1,138 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package patterns groups log messages into templates with variable
+// slots, using the Drain algorithm.
+//
+// Messages are split into tokens by whitespace. Tokens that look like
+// numbers, identifiers or addresses are masked as [Wildcard] first.
+// Messages are routed through a fixed-depth tree by their token count
+// and first tokens to a leaf of candidate patterns, and join the most
+// similar one, replacing tokens that differ with [Wildcard]. Messages
+// too different from all candidates start a new pattern.
+package patterns
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Wildcard is a variable slot of a template.
+	Wildcard = "<*>"
+
+	// Similarity is the fraction of tokens a message must share with
+	// a pattern to join it.
+	Similarity = 0.4
+	// Depth is the number of leading tokens routing messages in the
+	// tree. Later tokens are often variable without looking like one.
+	Depth = 1
+	// MaxChildren limits children of a tree node, tokens beyond that are
+	// routed as a wildcard.
+	MaxChildren = 100
+)
+
+// variableRe matches tokens masked as wildcards: numbers with optional
+// units, hexadecimal identifiers, UUIDs and IP addresses with a port.
+var variableRe = regexp.MustCompile(`^(?:` +
+	`[-+]?\d+(?:[.,:/]\d+)*[a-zA-Zµ%]{0,3}` +
+	`|(?:0x)?[0-9a-fA-F]{8,}` +
+	`|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}` +
+	`|\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?` +
+	`)$`)
+
+// tokenize splits message into tokens, masking variables.
+func tokenize(message string) []string {
+	tokens := strings.Fields(message)
+	for i, tok := range tokens {
+		if variableRe.MatchString(strings.Trim(tok, `,;()[]{}"'`)) {
+			tokens[i] = Wildcard
+		}
+	}
+	return tokens
+}
+
+func hasDigit(s string) bool {
+	return strings.ContainsFunc(s, unicode.IsDigit)
+}
+
+// node is a node of the parse tree. Inner nodes route messages by
+// a token, leaves hold candidate clusters.
+type node struct {
+	children map[string]*node
+	clusters []*cluster
+}
+
+// leaf returns the leaf of tokens, creating it if missing.
+func (n *node) leaf(tokens []string) *node {
+	for _, tok := range tokens[:min(Depth, len(tokens))] {
+		if hasDigit(tok) {
+			tok = Wildcard
+		}
+
+		child, ok := n.children[tok]
+		if !ok {
+			if len(n.children) >= MaxChildren {
+				tok = Wildcard
+			}
+			if child, ok = n.children[tok]; !ok {
+				child = &node{children: make(map[string]*node)}
+				n.children[tok] = child
+			}
+		}
+		n = child
+	}
+	return n
+}
+
+// match returns the cluster of n most similar to tokens, or nil if none
+// is similar enough.
+func (n *node) match(tokens []string) *cluster {
+	var best *cluster
+	bestSim, bestParams := -1.0, -1
+
+	for _, c := range n.clusters {
+		sim, params := similarity(c.tokens, tokens)
+		if sim > bestSim || (sim == bestSim && params > bestParams) {
+			best, bestSim, bestParams = c, sim, params
+		}
+	}
+
+	if len(tokens) > 0 && bestSim < Similarity {
+		return nil
+	}
+	return best
+}
+
+// similarity returns the fraction of tokens equal to those of template,
+// not counting wildcards, and the number of wildcards of template.
+func similarity(template, tokens []string) (float64, int) {
+	if len(tokens) == 0 {
+		return 1, 0
+	}
+
+	var equal, params int
+	for i, tok := range template {
+		if tok == Wildcard {
+			params++
+		} else if tok == tokens[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(tokens)), params
+}
+
+// merge replaces tokens of template differing from tokens with
+// wildcards, reporting whether template changed.
+func merge(template, tokens []string) bool {
+	changed := false
+	for i, tok := range template {
+		if tok != Wildcard && tok != tokens[i] {
+			template[i] = Wildcard
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,217 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package patterns
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+const (
+	// MaxPatterns limits patterns mined. Messages unlike all of them
+	// are not clustered once the limit is reached.
+	MaxPatterns = 10_000
+
+	// NewPatternAge is how long a pattern is flagged as new after
+	// it first appears.
+	NewPatternAge = 10 * time.Minute
+	// WarmUp is the time after the first log patterns are not flagged
+	// as new for, since all of them appear for the first time.
+	WarmUp = time.Minute
+)
+
+// Pattern is a template of log messages.
+type Pattern struct {
+	ID       int    `json:"id"`
+	Template string `json:"template"`
+	// Number of logs matched, and of those still in the store.
+	Count int `json:"count"`
+	Logs  int `json:"logs"`
+	// Timestamps of the first and the last log matched.
+	FirstSeen internal.Timestamp `json:"first_seen"`
+	LastSeen  internal.Timestamp `json:"last_seen"`
+	// When the pattern first appeared, and whether that was recent.
+	CreatedAt time.Time `json:"created_at"`
+	New       bool      `json:"new"`
+}
+
+// cluster is a mined pattern.
+type cluster struct {
+	id        int
+	tokens    []string
+	count     int
+	firstSeen internal.Timestamp
+	lastSeen  internal.Timestamp
+	createdAt time.Time
+	new       bool
+
+	// Positions of logs in the store, ascending.
+	positions []int
+}
+
+func (c *cluster) pattern(now time.Time) Pattern {
+	return Pattern{
+		ID:        c.id,
+		Template:  strings.Join(c.tokens, " "),
+		Count:     c.count,
+		Logs:      len(c.positions),
+		FirstSeen: c.firstSeen,
+		LastSeen:  c.lastSeen,
+		CreatedAt: c.createdAt,
+		New:       c.new && now.Sub(c.createdAt) < NewPatternAge,
+	}
+}
+
+// Miner mines patterns of messages of logs in a [internal.Store].
+// It is an [internal.LogIndex], kept in step with the store it is added
+// to. It is safe for concurrent use.
+type Miner struct {
+	mu sync.RWMutex
+
+	// Parse tree roots by token count.
+	roots    map[int]*node
+	clusters []*cluster
+
+	// Ids of clusters of logs in the store by their position
+	// from base, or -1 for logs not clustered.
+	owners []int
+	base   int
+
+	startedAt time.Time
+	dropped   int64
+
+	onNew func(Pattern)
+	now   func() time.Time
+}
+
+// NewMiner returns a miner calling onNew, if not nil, with patterns
+// flagged as new when they first appear. onNew must not call methods
+// of the miner.
+func NewMiner(onNew func(Pattern)) *Miner {
+	return &Miner{
+		roots: make(map[int]*node),
+		onNew: onNew,
+		now:   time.Now,
+	}
+}
+
+// Add clusters the message of log at position pos.
+func (m *Miner) Add(pos int, log *internal.Log) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if m.startedAt.IsZero() {
+		m.startedAt = now
+	}
+	if len(m.owners) == 0 {
+		m.base = pos
+	}
+
+	tokens := tokenize(log.Message)
+	root, ok := m.roots[len(tokens)]
+	if !ok {
+		root = &node{children: make(map[string]*node)}
+		m.roots[len(tokens)] = root
+	}
+	leaf := root.leaf(tokens)
+
+	c := leaf.match(tokens)
+	switch {
+	case c != nil:
+		merge(c.tokens, tokens)
+		c.firstSeen = min(c.firstSeen, log.Timestamp)
+		c.lastSeen = max(c.lastSeen, log.Timestamp)
+	case len(m.clusters) < MaxPatterns:
+		c = &cluster{
+			id:        len(m.clusters),
+			tokens:    tokens,
+			firstSeen: log.Timestamp,
+			lastSeen:  log.Timestamp,
+			createdAt: now,
+			new:       now.Sub(m.startedAt) >= WarmUp,
+		}
+		leaf.clusters = append(leaf.clusters, c)
+		m.clusters = append(m.clusters, c)
+
+		if c.new && m.onNew != nil {
+			m.onNew(c.pattern(now))
+		}
+	default:
+		m.dropped++
+		m.owners = append(m.owners, -1)
+		return
+	}
+
+	c.count++
+	c.positions = append(c.positions, pos)
+	m.owners = append(m.owners, c.id)
+}
+
+// Remove forgets the log at position pos. Patterns are kept.
+func (m *Miner) Remove(pos int, _ *internal.Log) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := pos - m.base
+	if i != 0 || len(m.owners) == 0 {
+		return
+	}
+
+	if id := m.owners[0]; id >= 0 {
+		c := m.clusters[id]
+		if len(c.positions) > 0 && c.positions[0] == pos {
+			c.positions = c.positions[1:]
+		}
+	}
+	m.owners = m.owners[1:]
+	m.base++
+}
+
+// Dropped returns the number of logs not clustered once
+// [MaxPatterns] patterns were mined.
+func (m *Miner) Dropped() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.dropped
+}
+
+// Patterns returns mined patterns, those matching the most logs first.
+func (m *Miner) Patterns() []Pattern {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.now()
+	res := make([]Pattern, len(m.clusters))
+	for i, c := range m.clusters {
+		res[i] = c.pattern(now)
+	}
+
+	slices.SortFunc(res, func(a, b Pattern) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return res
+}
+
+// Pattern returns the pattern with the given id and positions of its
+// logs in the store, ascending.
+func (m *Miner) Pattern(id int) (Pattern, []int, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if id < 0 || id >= len(m.clusters) {
+		return Pattern{}, nil, false
+	}
+	c := m.clusters[id]
+	return c.pattern(m.now()), slices.Clone(c.positions), true
+}
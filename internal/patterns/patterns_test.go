@@ -0,0 +1,121 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package patterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t,
+		[]string{"GET", "/api/users", "took", Wildcard, "from", Wildcard, "id=x", Wildcard, Wildcard},
+		tokenize("GET /api/users took 3.5ms from 10.0.0.1:8080 id=x 0xdeadbeef (550e8400-e29b-41d4-a716-446655440000)"))
+	assert.Empty(t, tokenize("  "))
+}
+
+func newLog(ts float64, message string) *internal.Log {
+	return &internal.Log{Timestamp: internal.Timestamp(ts), Message: message}
+}
+
+func templates(m *Miner) []string {
+	var res []string
+	for _, p := range m.Patterns() {
+		res = append(res, p.Template)
+	}
+	return res
+}
+
+func TestMiner(t *testing.T) {
+	m := NewMiner(nil)
+	for i, msg := range []string{
+		"connected to db in 12ms",
+		"user alice logged in",
+		"connected to db in 7ms",
+		"user bob logged in",
+		"user carol logged out",
+		"connected to cache in 3ms",
+		"",
+		"shutting down",
+		"user dave logged in",
+	} {
+		m.Add(i, newLog(float64(100+i), msg))
+	}
+
+	assert.Equal(t, []string{
+		"user <*> logged <*>",
+		"connected to <*> in <*>",
+		"",
+		"shutting down",
+	}, templates(m))
+
+	p, positions, ok := m.Pattern(1)
+	require.True(t, ok)
+	assert.Equal(t, "user <*> logged <*>", p.Template)
+	assert.Equal(t, 4, p.Count)
+	assert.Equal(t, 4, p.Logs)
+	assert.Equal(t, internal.Timestamp(101), p.FirstSeen)
+	assert.Equal(t, internal.Timestamp(108), p.LastSeen)
+	assert.Equal(t, []int{1, 3, 4, 8}, positions)
+
+	// Logs are removed oldest first, patterns are kept.
+	for i := range 5 {
+		m.Remove(i, nil)
+	}
+	p, positions, _ = m.Pattern(1)
+	assert.Equal(t, 4, p.Count)
+	assert.Equal(t, 1, p.Logs)
+	assert.Equal(t, []int{8}, positions)
+
+	m.Add(9, newLog(109, "user erin logged in"))
+	_, positions, _ = m.Pattern(1)
+	assert.Equal(t, []int{8, 9}, positions)
+
+	_, _, ok = m.Pattern(42)
+	assert.False(t, ok)
+}
+
+func TestMiner_Similarity(t *testing.T) {
+	m := NewMiner(nil)
+	m.Add(0, newLog(0, "request failed with status timeout"))
+	m.Add(1, newLog(1, "request completed quickly and without errors"))
+	m.Add(2, newLog(2, "cache miss for key alpha beta"))
+	m.Add(3, newLog(3, "request failed with status refused"))
+
+	assert.Equal(t, []string{
+		"request failed with status <*>",
+		"request completed quickly and without errors",
+		"cache miss for key alpha beta",
+	}, templates(m))
+}
+
+func TestMiner_New(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	var flagged []string
+	m := NewMiner(func(p Pattern) { flagged = append(flagged, p.Template) })
+	m.now = func() time.Time { return now }
+
+	m.Add(0, newLog(0, "starting worker"))
+	now = now.Add(WarmUp)
+	m.Add(1, newLog(1, "starting worker"))
+	m.Add(2, newLog(2, "disk almost full"))
+	assert.Equal(t, []string{"disk almost full"}, flagged)
+
+	isNew := func() map[string]bool {
+		res := make(map[string]bool)
+		for _, p := range m.Patterns() {
+			res[p.Template] = p.New
+		}
+		return res
+	}
+	assert.Equal(t, map[string]bool{"starting worker": false, "disk almost full": true}, isNew())
+
+	now = now.Add(NewPatternAge)
+	assert.Equal(t, map[string]bool{"starting worker": false, "disk almost full": false}, isNew())
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// Stage types.
+const (
+	StageDrop      = "drop"
+	StageRename    = "rename"
+	StageSetLevel  = "set_level"
+	StageAddAttrs  = "add_attrs"
+	StageSample    = "sample"
+	StageParseJSON = "parse_json"
+	StageRedact    = "redact"
+)
+
+// Config is a pipeline configuration, as read from a JSON file:
+//
+//	{
+//		"stages": [
+//			{"type": "redact", "patterns": ["ssn=\\d+"], "paths": ["attrs.password", "attrs.*.token"]},
+//			{"type": "drop", "when": [{"field": "attrs.path", "regex": "^/health"}]},
+//			{"type": "rename", "from": "attrs.usr", "to": "attrs.user.id"},
+//			{"type": "set_level", "field": "attrs.severity", "levels": {"warning": "warn"}},
+//			{"type": "add_attrs", "attrs": {"environment": "prod"}, "source_attr": "source"},
+//			{"type": "sample", "percent": 10, "when": [{"field": "level", "equals": "debug"}]},
+//			{"type": "parse_json", "field": "attrs.payload"}
+//		]
+//	}
+type Config struct {
+	Stages []StageConfig `json:"stages"`
+}
+
+// StageConfig configures a stage. Fields other than Name, Type
+// and When are used by the stage types they are documented for.
+type StageConfig struct {
+	// Name of the stage in metrics, defaults to its type and position.
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Conditions all of which a log must match to be processed by the stage.
+	// The stage processes all logs if there are none.
+	When []Condition `json:"when"`
+
+	// Attribute paths to move an attribute between, for rename.
+	// To is also the path parsed JSON is set at, for parse_json.
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Path of the value to process, for set_level and parse_json.
+	Field string `json:"field"`
+
+	// Map of lower case values to levels, for set_level.
+	// Values not in the map are used as levels in lower case.
+	Levels map[string]string `json:"levels"`
+
+	// Attributes to set, keyed by attribute path relative to attrs, for
+	// add_attrs. SourceAttr is the attribute to set to the name of the
+	// source the log is ingested from, such as "otlp" or "syslog".
+	Attrs      map[string]any `json:"attrs"`
+	SourceAttr string         `json:"source_attr"`
+
+	// Percent of logs to keep, for sample.
+	Percent float64 `json:"percent"`
+
+	// Built-in detectors of sensitive values, all of them if omitted,
+	// custom regular expressions matching sensitive values, flattened
+	// attribute paths of values redacted whole, where "*" matches a single
+	// key, and the replacement of sensitive values, for redact.
+	Detectors   []string `json:"detectors"`
+	Patterns    []string `json:"patterns"`
+	Paths       []string `json:"paths"`
+	Replacement string   `json:"replacement"`
+}
+
+// Condition matches logs by the value at a flattened attribute path,
+// such as "level" or "attrs.user.name". Values are compared as strings.
+type Condition struct {
+	Field string `json:"field"`
+
+	// Whether the value is present.
+	Exists *bool `json:"exists"`
+	// Value the value equals.
+	Equals any `json:"equals"`
+	// Regular expression the value matches.
+	Regex string `json:"regex"`
+
+	// Negates the condition.
+	Not bool `json:"not"`
+}
+
+// ParseConfig parses a JSON encoded pipeline configuration.
+func ParseConfig(data []byte) (Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%w: %w", internal.ErrInvalidPipelineConfig, err)
+	}
+	return cfg, nil
+}
+
+// LoadConfig reads the pipeline configuration file at path.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read pipeline config: %w", err)
+	}
+	return ParseConfig(data)
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package pipeline processes logs on their way into the store with
+// declarative stages that drop, transform and enrich them.
+package pipeline
+
+import (
+	"sync/atomic"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// Metrics are counters of a pipeline since it was loaded.
+type Metrics struct {
+	In      int64          `json:"in"`
+	Out     int64          `json:"out"`
+	Dropped int64          `json:"dropped"`
+	Stages  []StageMetrics `json:"stages"`
+}
+
+// Pipeline runs logs through stages in order. It is safe for concurrent use.
+type Pipeline struct {
+	stages []*stage
+
+	in, out, dropped atomic.Int64
+}
+
+// New returns a pipeline of the configured stages.
+func New(cfg Config) (*Pipeline, error) {
+	p := &Pipeline{}
+	for i, sc := range cfg.Stages {
+		s, err := newStage(i, sc)
+		if err != nil {
+			return nil, err
+		}
+		p.stages = append(p.stages, s)
+	}
+	return p, nil
+}
+
+// Process runs log, ingested from the named source, through the stages.
+// It returns false if a stage drops log. A stage failing to process log
+// passes it on unchanged.
+func (p *Pipeline) Process(source string, log *internal.Log) bool {
+	p.in.Add(1)
+
+	for _, s := range p.stages {
+		s.in.Add(1)
+		if !s.matches(log) {
+			continue
+		}
+		s.matched.Add(1)
+
+		// Stages change logs through copies of attribute maps,
+		// so a failed stage leaves no partial changes behind.
+		res := *log
+		keep, err := s.apply(source, &res)
+		if err != nil {
+			s.fail(err)
+			continue
+		}
+		if !keep {
+			s.dropped.Add(1)
+			p.dropped.Add(1)
+			return false
+		}
+		*log = res
+	}
+
+	p.out.Add(1)
+	return true
+}
+
+// Metrics returns the counters of the pipeline and its stages.
+func (p *Pipeline) Metrics() Metrics {
+	m := Metrics{
+		In:      p.in.Load(),
+		Out:     p.out.Load(),
+		Dropped: p.dropped.Load(),
+		Stages:  make([]StageMetrics, 0, len(p.stages)),
+	}
+	for _, s := range p.stages {
+		m.Stages = append(m.Stages, s.metrics())
+	}
+	return m
+}
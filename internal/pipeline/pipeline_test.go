@@ -0,0 +1,176 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package pipeline
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPipeline(t *testing.T, config string) *Pipeline {
+	t.Helper()
+
+	cfg, err := ParseConfig([]byte(config))
+	require.NoError(t, err)
+	p, err := New(cfg)
+	require.NoError(t, err)
+	return p
+}
+
+func newLog(t *testing.T, data string) *internal.Log {
+	t.Helper()
+
+	log, err := internal.NewLog([]byte(data))
+	require.NoError(t, err)
+	return &log
+}
+
+func TestPipeline_Drop(t *testing.T) {
+	p := newPipeline(t, `{"stages": [
+		{"name": "health", "type": "drop", "when": [
+			{"field": "attrs.path", "regex": "^/health"},
+			{"field": "attrs.status", "equals": 200}
+		]}
+	]}`)
+
+	assert.False(t, p.Process("ws", newLog(t, `{"attrs": {"path": "/healthz", "status": 200}}`)))
+	assert.True(t, p.Process("ws", newLog(t, `{"attrs": {"path": "/healthz", "status": 500}}`)))
+	assert.True(t, p.Process("ws", newLog(t, `{"attrs": {"path": "/api"}}`)))
+
+	m := p.Metrics()
+	assert.Equal(t, Metrics{
+		In: 3, Out: 2, Dropped: 1,
+		Stages: []StageMetrics{{Name: "health", Type: StageDrop, In: 3, Matched: 1, Dropped: 1}},
+	}, m)
+}
+
+func TestPipeline_Transform(t *testing.T) {
+	p := newPipeline(t, `{"stages": [
+		{"type": "rename", "from": "attrs.usr", "to": "attrs.user.id"},
+		{"type": "set_level", "field": "attrs.severity", "levels": {"WARNING": "warn"}},
+		{"type": "add_attrs", "attrs": {"environment": "prod", "deploy.region": "eu"}, "source_attr": "source"},
+		{"type": "parse_json", "field": "attrs.payload"},
+		{"type": "parse_json", "field": "message", "to": "attrs.body"}
+	]}`)
+
+	log := newLog(t, `{
+		"level": "info",
+		"message": "[1, 2]",
+		"attrs": {"usr": "alice", "severity": "Warning", "payload": "{\"order\": 7}"}
+	}`)
+	require.True(t, p.Process("syslog", log))
+
+	assert.Equal(t, "warn", log.Level)
+	assert.Equal(t, map[string]any{
+		"user":        map[string]any{"id": "alice"},
+		"severity":    "Warning",
+		"payload":     map[string]any{"order": 7.0},
+		"body":        []any{1.0, 2.0},
+		"environment": "prod",
+		"deploy":      map[string]any{"region": "eu"},
+		"source":      "syslog",
+	}, log.Attrs)
+
+	for path, want := range map[string]any{
+		"level":               "warn",
+		"attrs.user.id":       "alice",
+		"attrs.payload.order": 7.0,
+		"attrs.source":        "syslog",
+	} {
+		v, ok := log.Attr(path)
+		assert.True(t, ok, path)
+		assert.Equal(t, want, v, path)
+	}
+}
+
+func TestPipeline_StageError(t *testing.T) {
+	p := newPipeline(t, `{"stages": [
+		{"type": "parse_json", "field": "attrs.payload"},
+		{"type": "add_attrs", "attrs": {"seen": true}}
+	]}`)
+
+	log := newLog(t, `{"attrs": {"payload": "{broken"}}`)
+	require.True(t, p.Process("ws", log))
+	assert.Equal(t, map[string]any{"payload": "{broken", "seen": true}, log.Attrs)
+
+	m := p.Metrics().Stages[0]
+	assert.Equal(t, "parse_json-0", m.Name)
+	assert.Equal(t, int64(1), m.Errors)
+	assert.Contains(t, m.LastError, "attrs.payload")
+}
+
+func TestPipeline_Sample(t *testing.T) {
+	p := newPipeline(t, `{"stages": [
+		{"type": "sample", "percent": 25, "when": [{"field": "level", "equals": "debug"}]}
+	]}`)
+
+	kept := 0
+	for i := range 1000 {
+		log := newLog(t, fmt.Sprintf(`{"id": {"producer_id": "app", "sequence_number": %d}, "level": "debug"}`, i))
+		if p.Process("ws", log) {
+			kept++
+		}
+	}
+	assert.InDelta(t, 250, kept, 50)
+
+	// Decided by the log id.
+	data := `{"id": {"producer_id": "app", "sequence_number": 1}, "level": "debug"}`
+	assert.Equal(t, p.Process("ws", newLog(t, data)), p.Process("ws", newLog(t, data)))
+
+	assert.True(t, p.Process("ws", newLog(t, `{"level": "info"}`)))
+
+	assert.NotEqual(t,
+		hashID(internal.LogID{ProducerID: "a1", SequenceNumber: 2}),
+		hashID(internal.LogID{ProducerID: "a", SequenceNumber: 12}))
+}
+
+func TestCondition(t *testing.T) {
+	log := newLog(t, `{"level": "error", "attrs": {"user": "alice"}}`)
+	yes, no := true, false
+
+	tests := []struct {
+		cond Condition
+		want bool
+	}{
+		{Condition{Field: "attrs.user", Exists: &yes}, true},
+		{Condition{Field: "attrs.user", Exists: &no}, false},
+		{Condition{Field: "attrs.missing", Exists: &no}, true},
+		{Condition{Field: "level", Equals: "error"}, true},
+		{Condition{Field: "level", Equals: "error", Not: true}, false},
+		{Condition{Field: "attrs.missing", Equals: "<nil>"}, false},
+		{Condition{Field: "attrs.user", Regex: "^al"}, true},
+		{Condition{Field: "attrs.user", Regex: "^bo"}, false},
+	}
+	for _, tt := range tests {
+		c, err := newCondition(tt.cond)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, c.matches(log), tt.cond)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, config := range []string{
+		`{"stages": [{"type": "explode"}]}`,
+		`{"stages": [{"type": "drop", "when": [{"regex": "x"}]}]}`,
+		`{"stages": [{"type": "drop", "when": [{"field": "level", "regex": "("}]}]}`,
+		`{"stages": [{"type": "rename", "from": "level", "to": "attrs.level"}]}`,
+		`{"stages": [{"type": "set_level"}]}`,
+		`{"stages": [{"type": "add_attrs"}]}`,
+		`{"stages": [{"type": "sample", "percent": 101}]}`,
+		`{"stages": [{"type": "parse_json", "field": "message"}]}`,
+	} {
+		cfg, err := ParseConfig([]byte(config))
+		require.NoError(t, err)
+		_, err = New(cfg)
+		assert.ErrorIs(t, err, internal.ErrInvalidPipelineConfig, config)
+	}
+
+	_, err := ParseConfig([]byte(`{"stages": {}}`))
+	assert.ErrorIs(t, err, internal.ErrInvalidPipelineConfig)
+}
@@ -0,0 +1,257 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package pipeline
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// Built-in detectors of sensitive values, for redact.
+const (
+	DetectJWT        = "jwt"
+	DetectBearer     = "bearer"
+	DetectEmail      = "email"
+	DetectCreditCard = "credit_card"
+	DetectAWSKey     = "aws_key"
+)
+
+const (
+	// DefaultReplacement replaces redacted values.
+	DefaultReplacement = "[REDACTED]"
+
+	// RedactedAttr lists paths of the values of a log that were redacted.
+	RedactedAttr = "redacted"
+)
+
+// detector finds sensitive values in strings.
+type detector struct {
+	re *regexp.Regexp
+	// Reports whether a match is sensitive, all matches are if nil.
+	valid func(match string) bool
+	// Prefix of a match kept in place, such as the scheme of a token.
+	keep *regexp.Regexp
+}
+
+var detectors = map[string][]detector{
+	DetectJWT: {{
+		re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	}},
+	DetectBearer: {{
+		re:   regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+		keep: regexp.MustCompile(`(?i)^bearer\s+`),
+	}},
+	DetectEmail: {{
+		re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	}},
+	DetectCreditCard: {{
+		re:    regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		valid: luhn,
+	}},
+	DetectAWSKey: {
+		{re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+		{
+			re:   regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`),
+			keep: regexp.MustCompile(`(?i)^aws_?secret_?access_?key["']?\s*[:=]\s*["']?`),
+		},
+	},
+}
+
+// luhn reports whether the digits of s pass the Luhn checksum
+// of payment card numbers.
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+func (d *detector) redact(s, replacement string) (string, bool) {
+	redacted := false
+	s = d.re.ReplaceAllStringFunc(s, func(match string) string {
+		if d.valid != nil && !d.valid(match) {
+			return match
+		}
+		redacted = true
+
+		if d.keep != nil {
+			return d.keep.FindString(match) + replacement
+		}
+		return replacement
+	})
+	return s, redacted
+}
+
+type redactor struct {
+	detectors   []detector
+	paths       []string
+	replacement string
+}
+
+// redactStage returns a stage redacting sensitive values of the message
+// and attributes of logs, found by the named built-in detectors, all of
+// them if names is nil, and by custom regular expressions. Values at
+// attribute paths matching paths, where "*" matches a single key, are
+// redacted whole.
+func redactStage(names, patterns, paths []string, replacement string) (applyFunc, error) {
+	r := &redactor{replacement: cmp.Or(replacement, DefaultReplacement)}
+
+	if names == nil {
+		names = slices.Sorted(maps.Keys(detectors))
+	}
+	for _, name := range names {
+		ds, ok := detectors[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown detector %q", internal.ErrInvalidPipelineConfig, name)
+		}
+		r.detectors = append(r.detectors, ds...)
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid pattern %q: %w", internal.ErrInvalidPipelineConfig, pattern, err)
+		}
+		r.detectors = append(r.detectors, detector{re: re})
+	}
+
+	for _, p := range paths {
+		if _, err := path.Match(slashed(p), ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid path %q: %w", internal.ErrInvalidPipelineConfig, p, err)
+		}
+		r.paths = append(r.paths, slashed(p))
+	}
+
+	if len(r.detectors) == 0 && len(r.paths) == 0 {
+		return nil, fmt.Errorf("%w: redact needs detectors, patterns or paths", internal.ErrInvalidPipelineConfig)
+	}
+	return r.apply, nil
+}
+
+// slashed returns a flattened attribute path with keys separated
+// by slashes, to be matched with [path.Match].
+func slashed(p string) string {
+	return strings.ReplaceAll(p, ".", "/")
+}
+
+func (r *redactor) apply(_ string, log *internal.Log) (bool, error) {
+	var redacted []string
+
+	if v, ok := r.value("message", log.Message, &redacted); ok {
+		log.Message = v.(string)
+	}
+	if attrs, ok := r.attrs("attrs", log.Attrs, &redacted); ok {
+		log.Attrs = attrs
+	}
+
+	if len(redacted) == 0 {
+		return true, nil
+	}
+
+	marked, _ := log.Attrs[RedactedAttr].([]any)
+	marked = slices.Clone(marked)
+	for _, p := range redacted {
+		if !slices.Contains(marked, any(p)) {
+			marked = append(marked, p)
+		}
+	}
+	log.SetAttr(RedactedAttr, marked)
+	return true, nil
+}
+
+func (r *redactor) matchesPath(p string) bool {
+	for _, pattern := range r.paths {
+		if ok, _ := path.Match(pattern, slashed(p)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns v, at path p, with sensitive values redacted, and whether
+// any are. Paths of redacted values are appended to redacted.
+func (r *redactor) value(p string, v any, redacted *[]string) (any, bool) {
+	if r.matchesPath(p) {
+		*redacted = append(*redacted, p)
+		return r.replacement, true
+	}
+
+	switch v := v.(type) {
+	case string:
+		found := false
+		for _, d := range r.detectors {
+			var ok bool
+			v, ok = d.redact(v, r.replacement)
+			found = found || ok
+		}
+		if found {
+			*redacted = append(*redacted, p)
+		}
+		return v, found
+
+	case map[string]any:
+		return r.attrs(p, v, redacted)
+
+	case []any:
+		// Elements are not addressed by paths, the array is marked.
+		var res []any
+		for i, elem := range v {
+			if elem, ok := r.value(p, elem, redacted); ok {
+				if res == nil {
+					res = slices.Clone(v)
+				}
+				res[i] = elem
+			}
+		}
+		return res, res != nil
+	}
+	return v, false
+}
+
+// attrs returns attrs, at path prefix, with sensitive values redacted,
+// and whether any are. Attribute maps are copied rather than changed,
+// as they may be shared with other logs.
+func (r *redactor) attrs(prefix string, attrs map[string]any, redacted *[]string) (map[string]any, bool) {
+	var res map[string]any
+	for _, k := range slices.Sorted(maps.Keys(attrs)) {
+		if prefix == "attrs" && k == RedactedAttr {
+			continue
+		}
+
+		v, ok := r.value(prefix+"."+k, attrs[k], redacted)
+		if !ok {
+			continue
+		}
+		if res == nil {
+			res = maps.Clone(attrs)
+		}
+		res[k] = v
+	}
+
+	if res == nil {
+		return attrs, false
+	}
+	return res, true
+}
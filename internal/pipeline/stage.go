@@ -0,0 +1,282 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package pipeline
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// applyFunc applies a stage to a log of the named source.
+// It returns false if the log is dropped.
+type applyFunc func(source string, log *internal.Log) (keep bool, err error)
+
+// StageMetrics are counters of a stage since the pipeline was loaded.
+type StageMetrics struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Logs reaching the stage.
+	In int64 `json:"in"`
+	// Logs matching the conditions of the stage.
+	Matched int64 `json:"matched"`
+	// Logs dropped by the stage.
+	Dropped int64 `json:"dropped"`
+	// Logs the stage failed to process. They are passed on unchanged.
+	Errors    int64  `json:"errors"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+type stage struct {
+	name  string
+	typ   string
+	when  []condition
+	apply applyFunc
+
+	in, matched, dropped, errors atomic.Int64
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+func (s *stage) matches(log *internal.Log) bool {
+	for _, c := range s.when {
+		if !c.matches(log) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *stage) fail(err error) {
+	s.errors.Add(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+}
+
+func (s *stage) metrics() StageMetrics {
+	m := StageMetrics{
+		Name:    s.name,
+		Type:    s.typ,
+		In:      s.in.Load(),
+		Matched: s.matched.Load(),
+		Dropped: s.dropped.Load(),
+		Errors:  s.errors.Load(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastErr != nil {
+		m.LastError = s.lastErr.Error()
+	}
+	return m
+}
+
+type condition struct {
+	field  string
+	exists *bool
+	equals *string
+	regex  *regexp.Regexp
+	not    bool
+}
+
+func newCondition(cfg Condition) (condition, error) {
+	c := condition{field: cfg.Field, exists: cfg.Exists, not: cfg.Not}
+	if c.field == "" {
+		return c, fmt.Errorf("%w: condition without field", internal.ErrInvalidPipelineConfig)
+	}
+
+	if cfg.Equals != nil {
+		v := fmt.Sprint(cfg.Equals)
+		c.equals = &v
+	}
+	if cfg.Regex != "" {
+		var err error
+		if c.regex, err = regexp.Compile(cfg.Regex); err != nil {
+			return c, fmt.Errorf("%w: invalid regex of %s: %w", internal.ErrInvalidPipelineConfig, c.field, err)
+		}
+	}
+	return c, nil
+}
+
+func (c *condition) matches(log *internal.Log) bool {
+	v, ok := log.Attr(c.field)
+	s := fmt.Sprint(v)
+
+	res := true
+	switch {
+	case c.exists != nil && ok != *c.exists:
+		res = false
+	case c.equals != nil && (!ok || s != *c.equals):
+		res = false
+	case c.regex != nil && (!ok || !c.regex.MatchString(s)):
+		res = false
+	}
+	return res != c.not
+}
+
+func newStage(i int, cfg StageConfig) (*stage, error) {
+	s := &stage{
+		name: cmp.Or(cfg.Name, cfg.Type+"-"+strconv.Itoa(i)),
+		typ:  cfg.Type,
+	}
+
+	for _, c := range cfg.When {
+		cond, err := newCondition(c)
+		if err != nil {
+			return nil, fmt.Errorf("stage %s: %w", s.name, err)
+		}
+		s.when = append(s.when, cond)
+	}
+
+	var err error
+	switch cfg.Type {
+	case StageDrop:
+		s.apply = drop
+	case StageRename:
+		s.apply, err = rename(cfg.From, cfg.To)
+	case StageSetLevel:
+		s.apply, err = setLevel(cfg.Field, cfg.Levels)
+	case StageAddAttrs:
+		s.apply, err = addAttrs(cfg.Attrs, cfg.SourceAttr)
+	case StageSample:
+		s.apply, err = sample(cfg.Percent)
+	case StageParseJSON:
+		s.apply, err = parseJSON(cfg.Field, cfg.To)
+	case StageRedact:
+		s.apply, err = redactStage(cfg.Detectors, cfg.Patterns, cfg.Paths, cfg.Replacement)
+	default:
+		err = fmt.Errorf("%w: unknown stage type %q", internal.ErrInvalidPipelineConfig, cfg.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stage %s: %w", s.name, err)
+	}
+	return s, nil
+}
+
+func isAttrPath(path string) bool {
+	rest, ok := strings.CutPrefix(path, "attrs.")
+	return ok && rest != ""
+}
+
+func drop(string, *internal.Log) (bool, error) {
+	return false, nil
+}
+
+func rename(from, to string) (applyFunc, error) {
+	if !isAttrPath(from) || !isAttrPath(to) {
+		return nil, fmt.Errorf("%w: rename needs attribute paths, got %q and %q",
+			internal.ErrInvalidPipelineConfig, from, to)
+	}
+
+	return func(_ string, log *internal.Log) (bool, error) {
+		if v, ok := log.DeleteAttrPath(from); ok {
+			log.SetAttrPath(to, v)
+		}
+		return true, nil
+	}, nil
+}
+
+func setLevel(field string, levels map[string]string) (applyFunc, error) {
+	if field == "" {
+		return nil, fmt.Errorf("%w: set_level needs a field", internal.ErrInvalidPipelineConfig)
+	}
+
+	lower := make(map[string]string, len(levels))
+	for k, v := range levels {
+		lower[strings.ToLower(k)] = v
+	}
+
+	return func(_ string, log *internal.Log) (bool, error) {
+		v, ok := log.Attr(field)
+		if !ok {
+			return true, nil
+		}
+
+		level := strings.ToLower(fmt.Sprint(v))
+		if mapped, ok := lower[level]; ok {
+			level = mapped
+		}
+		log.Level = level
+		log.InvalidateAttrs()
+		return true, nil
+	}, nil
+}
+
+func addAttrs(attrs map[string]any, sourceAttr string) (applyFunc, error) {
+	if len(attrs) == 0 && sourceAttr == "" {
+		return nil, fmt.Errorf("%w: add_attrs needs attrs or source_attr", internal.ErrInvalidPipelineConfig)
+	}
+
+	return func(source string, log *internal.Log) (bool, error) {
+		for k, v := range attrs {
+			log.SetAttrPath("attrs."+k, v)
+		}
+		if sourceAttr != "" {
+			log.SetAttrPath("attrs."+sourceAttr, source)
+		}
+		return true, nil
+	}, nil
+}
+
+func sample(percent float64) (applyFunc, error) {
+	if percent < 0 || percent > 100 {
+		return nil, fmt.Errorf("%w: sample percent %v out of range", internal.ErrInvalidPipelineConfig, percent)
+	}
+
+	// Logs are kept by the hash of their id, so that a log sent
+	// again is kept or dropped the same way.
+	threshold := uint32(percent * 100)
+	return func(_ string, log *internal.Log) (bool, error) {
+		return hashID(log.ID)%10000 < threshold, nil
+	}, nil
+}
+
+// hashID hashes id, its parts separated so that ids of a producer id
+// ending in digits do not collide with others.
+func hashID(id internal.LogID) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(id.ProducerID))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(id.SequenceNumber)))
+	return h.Sum32()
+}
+
+func parseJSON(field, to string) (applyFunc, error) {
+	to = cmp.Or(to, field)
+	if field == "" || !isAttrPath(to) {
+		return nil, fmt.Errorf("%w: parse_json needs a field and an attribute path to set, got %q and %q",
+			internal.ErrInvalidPipelineConfig, field, to)
+	}
+
+	return func(_ string, log *internal.Log) (bool, error) {
+		v, ok := log.Attr(field)
+		if !ok {
+			return true, nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return true, fmt.Errorf("%s is not a string", field)
+		}
+
+		var parsed any
+		if err := json.Unmarshal([]byte(s), &parsed); err != nil {
+			return true, fmt.Errorf("failed to parse %s: %w", field, err)
+		}
+		log.SetAttrPath(to, parsed)
+		return true, nil
+	}, nil
+}
@@ -0,0 +1,237 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// Call is a function call described by a metric log,
+// linked to its caller and callees.
+type Call struct {
+	Log      *internal.Log
+	Parent   *Call
+	Children []*Call
+
+	// Depth of the call in its tree, roots have depth 0.
+	Depth int
+}
+
+func (c *Call) Name() string {
+	if c.Log.SourceFunction != "" {
+		return c.Log.SourceFunction
+	}
+	return c.Log.Message
+}
+
+func (c *Call) Start() internal.Timestamp {
+	return c.Log.FunctionCallStartedAt
+}
+
+func (c *Call) End() internal.Timestamp {
+	return c.Log.FunctionCallEndedAt
+}
+
+func (c *Call) Duration() internal.Timestamp {
+	return c.End() - c.Start()
+}
+
+// SelfDuration returns the time spent in the call itself,
+// excluding the time covered by any of its children.
+// Overlapping concurrent children are only counted once.
+func (c *Call) SelfDuration() internal.Timestamp {
+	covered := internal.Timestamp(0)
+	cursor := c.Start()
+
+	// Children are sorted by their start time.
+	for _, child := range c.Children {
+		start := max(child.Start(), cursor)
+		end := min(child.End(), c.End())
+		if end > start {
+			covered += end - start
+			cursor = end
+		}
+	}
+
+	return max(c.Duration()-covered, 0)
+}
+
+// CallTree is a forest of function calls reconstructed
+// from the call stacks of metric logs.
+type CallTree struct {
+	Roots []*Call
+	calls map[internal.LogID]*Call
+}
+
+// BuildCallTree reconstructs call trees from metric logs.
+// Info logs are ignored.
+//
+// A call is attached to the nearest ancestor in its call stack
+// that is present among logs or, when there is none, to the call
+// of its parent span. Calls with no known parent become roots,
+// and so does one call of every cycle, cut loose from its parent.
+func BuildCallTree(logs []internal.Log) *CallTree {
+	t := &CallTree{calls: make(map[internal.LogID]*Call)}
+	spans := make(map[spanKey]internal.LogID)
+
+	for i := range logs {
+		log := &logs[i]
+		if log.Type() != internal.LogTypeMetric {
+			continue
+		}
+		t.calls[log.ID] = &Call{Log: log}
+		if log.SpanID != "" {
+			spans[spanKey{log.TraceID, log.SpanID}] = log.ID
+		}
+	}
+
+	// Visit calls in a stable order, so that the same logs always
+	// build the same tree, whichever call of a cycle is cut loose.
+	calls := slices.SortedFunc(maps.Values(t.calls), compareIDs)
+
+	for _, call := range calls {
+		parentID, ok := parentCall(call.Log, func(id internal.LogID) bool {
+			_, ok := t.calls[id]
+			return ok
+		}, spans)
+		if ok {
+			call.Parent = t.calls[parentID]
+		}
+	}
+
+	// Walk down from calls without a parent to assign depths.
+	// Calls left unvisited are on a cycle or descend from one.
+	visited := make(map[*Call]bool, len(t.calls))
+	var walk func(c *Call, depth int)
+	walk = func(c *Call, depth int) {
+		visited[c] = true
+		c.Depth = depth
+		for _, child := range c.Children {
+			walk(child, depth+1)
+		}
+	}
+
+	for _, call := range calls {
+		if call.Parent != nil {
+			call.Parent.Children = append(call.Parent.Children, call)
+		}
+	}
+	for _, call := range calls {
+		if call.Parent == nil {
+			t.Roots = append(t.Roots, call)
+			walk(call, 0)
+		}
+	}
+	for _, call := range calls {
+		if visited[call] {
+			continue
+		}
+
+		// Follow parents up to a call repeating on the cycle,
+		// and cut it loose.
+		seen := make(map[*Call]bool)
+		c := call
+		for !seen[c] {
+			seen[c] = true
+			c = c.Parent
+		}
+		c.Parent.Children = slices.DeleteFunc(c.Parent.Children, func(child *Call) bool {
+			return child == c
+		})
+		c.Parent = nil
+		t.Roots = append(t.Roots, c)
+		walk(c, 0)
+	}
+
+	sortCalls(t.Roots)
+	for _, call := range calls {
+		sortCalls(call.Children)
+	}
+
+	return t
+}
+
+// Call returns the call described by the metric log with the given id.
+func (t *CallTree) Call(id internal.LogID) (*Call, bool) {
+	c, ok := t.calls[id]
+	return c, ok
+}
+
+func (t *CallTree) Len() int {
+	return len(t.calls)
+}
+
+// Walk visits calls depth-first, in order of their start time.
+// Children of a call are skipped when fn returns false.
+func (t *CallTree) Walk(fn func(c *Call) bool) {
+	var walk func(c *Call)
+	walk = func(c *Call) {
+		if !fn(c) {
+			return
+		}
+		for _, child := range c.Children {
+			walk(child)
+		}
+	}
+
+	for _, root := range t.Roots {
+		walk(root)
+	}
+}
+
+// nearestAncestor returns the last id in the call stack of log
+// for which known reports true, skipping the log itself.
+func nearestAncestor(log *internal.Log, known func(id internal.LogID) bool) (internal.LogID, bool) {
+	stack := log.FunctionCallStack
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] != log.ID && known(stack[i]) {
+			return stack[i], true
+		}
+	}
+	return internal.LogID{}, false
+}
+
+// spanKey identifies a span, span ids are only unique within a trace.
+type spanKey struct {
+	traceID string
+	spanID  string
+}
+
+// parentCall returns the id of the caller of log: the nearest known
+// ancestor in its call stack or, failing that, the call of its parent span.
+func parentCall(log *internal.Log, known func(id internal.LogID) bool, spans map[spanKey]internal.LogID) (internal.LogID, bool) {
+	if id, ok := nearestAncestor(log, known); ok {
+		return id, true
+	}
+	if log.ParentSpanID == "" {
+		return internal.LogID{}, false
+	}
+
+	id, ok := spans[spanKey{log.TraceID, log.ParentSpanID}]
+	if !ok || id == log.ID {
+		return internal.LogID{}, false
+	}
+	return id, true
+}
+
+func sortCalls(calls []*Call) {
+	slices.SortFunc(calls, func(a, b *Call) int {
+		if c := cmp.Compare(a.Start(), b.Start()); c != 0 {
+			return c
+		}
+		return compareIDs(a, b)
+	})
+}
+
+func compareIDs(a, b *Call) int {
+	if c := cmp.Compare(a.Log.ID.ProducerID, b.Log.ID.ProducerID); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Log.ID.SequenceNumber, b.Log.ID.SequenceNumber)
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func id(seq int) internal.LogID {
+	return internal.LogID{ProducerID: "app", SequenceNumber: seq}
+}
+
+func metricLog(seq int, name string, start, end internal.Timestamp, stack ...int) internal.Log {
+	log := internal.Log{
+		ID:                    id(seq),
+		Timestamp:             end,
+		SourceFunction:        name,
+		FunctionCallStartedAt: start,
+		FunctionCallEndedAt:   end,
+	}
+	for _, s := range stack {
+		log.FunctionCallStack = append(log.FunctionCallStack, id(s))
+	}
+	return log
+}
+
+func infoLog(seq int, ts internal.Timestamp, msg string) internal.Log {
+	return internal.Log{ID: id(seq), Timestamp: ts, Level: "info", Message: msg}
+}
+
+func callNames(calls []*Call) []string {
+	names := make([]string, 0, len(calls))
+	for _, c := range calls {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+func TestBuildCallTree(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(2, "child2", 14, 18, 1),
+		metricLog(3, "grandchild", 11, 12, 1, 4),
+		infoLog(5, 11, "ignored"),
+		metricLog(4, "child1", 10, 13, 1),
+		metricLog(1, "root", 10, 20),
+	}
+
+	tree := BuildCallTree(logs)
+	assert.Equal(t, 4, tree.Len())
+	assert.Equal(t, []string{"root"}, callNames(tree.Roots))
+
+	root := tree.Roots[0]
+	assert.Equal(t, 0, root.Depth)
+	assert.Equal(t, internal.Timestamp(10), root.Duration())
+	assert.Equal(t, []string{"child1", "child2"}, callNames(root.Children))
+
+	grandchild, ok := tree.Call(id(3))
+	assert.True(t, ok)
+	assert.Equal(t, 2, grandchild.Depth)
+	assert.Equal(t, "child1", grandchild.Parent.Name())
+
+	_, ok = tree.Call(id(5))
+	assert.False(t, ok)
+
+	var walked []string
+	tree.Walk(func(c *Call) bool {
+		walked = append(walked, c.Name())
+		return true
+	})
+	assert.Equal(t, []string{"root", "child1", "grandchild", "child2"}, walked)
+}
+
+func TestBuildCallTree_MissingParent(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "root", 1, 10),
+		// Immediate parent 9 was never received, attach to root.
+		metricLog(2, "orphan", 2, 3, 1, 9),
+		// No known ancestor at all.
+		metricLog(3, "lost", 4, 5, 8),
+	}
+
+	tree := BuildCallTree(logs)
+	assert.Equal(t, []string{"root", "lost"}, callNames(tree.Roots))
+
+	orphan, _ := tree.Call(id(2))
+	assert.Equal(t, "root", orphan.Parent.Name())
+	assert.Equal(t, 1, orphan.Depth)
+}
+
+func TestBuildCallTree_SpanParent(t *testing.T) {
+	span := func(log internal.Log, traceID, spanID, parentSpanID string) internal.Log {
+		log.TraceID, log.SpanID, log.ParentSpanID = traceID, spanID, parentSpanID
+		return log
+	}
+
+	logs := []internal.Log{
+		span(metricLog(1, "handler", 1, 10), "t1", "s1", ""),
+		span(metricLog(2, "query", 2, 5), "t1", "s2", "s1"),
+		// Call stack takes precedence over the parent span.
+		span(metricLog(3, "scan", 3, 4, 2), "t1", "s3", "s1"),
+		// Same span id in another trace is unrelated.
+		span(metricLog(4, "other", 6, 7), "t2", "s4", "s2"),
+	}
+
+	tree := BuildCallTree(logs)
+	assert.Equal(t, []string{"handler", "other"}, callNames(tree.Roots))
+
+	query, _ := tree.Call(id(2))
+	assert.Equal(t, "handler", query.Parent.Name())
+	assert.Equal(t, []string{"scan"}, callNames(query.Children))
+}
+
+func TestBuildCallTree_Cycle(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "a", 1, 10, 2),
+		metricLog(2, "b", 2, 9, 1),
+	}
+
+	tree := BuildCallTree(logs)
+	assert.Len(t, tree.Roots, 1)
+
+	var count int
+	tree.Walk(func(*Call) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 2, count)
+
+	// The call cut loose does not depend on map iteration order.
+	for range 20 {
+		tree := BuildCallTree(logs)
+		assert.Equal(t, []string{"a"}, callNames(tree.Roots))
+		assert.Equal(t, []string{"b"}, callNames(tree.Roots[0].Children))
+	}
+}
+
+func TestBuildCallTree_CycleDescendant(t *testing.T) {
+	// A call sorting before the calls of the cycle it descends from.
+	logs := []internal.Log{
+		metricLog(1, "child", 3, 4, 2),
+		metricLog(2, "b", 2, 9, 3),
+		metricLog(3, "d", 1, 10, 2),
+	}
+
+	tree := BuildCallTree(logs)
+	require.Equal(t, []string{"b"}, callNames(tree.Roots))
+	assert.Equal(t, []string{"d", "child"}, callNames(tree.Roots[0].Children))
+
+	child, ok := tree.Call(id(1))
+	require.True(t, ok)
+	assert.Equal(t, "b", child.Parent.Name())
+	assert.Equal(t, 1, child.Depth)
+}
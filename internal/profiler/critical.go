@@ -0,0 +1,91 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"slices"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// CriticalPath is the chain of calls that determined
+// the wall-clock duration of its root call.
+type CriticalPath struct {
+	Root     internal.LogID     `json:"root"`
+	Duration internal.Timestamp `json:"duration"`
+
+	// Calls on the path in call order, starting with the root.
+	Spans []Span `json:"spans"`
+}
+
+// Contains reports whether the call with the given id is on the path.
+func (p *CriticalPath) Contains(id internal.LogID) bool {
+	return slices.ContainsFunc(p.Spans, func(s Span) bool {
+		return s.ID == id
+	})
+}
+
+// CriticalPath computes the critical path of the call with the given id.
+//
+// Walking back from the end of a call, the child that finished last
+// is the one the call waited for. Children running concurrently with it
+// did not delay the call and are skipped. The walk then continues from
+// the start of that child, and recurses into it.
+func (t *CallTree) CriticalPath(root internal.LogID) (CriticalPath, bool) {
+	c, ok := t.calls[root]
+	if !ok {
+		return CriticalPath{}, false
+	}
+
+	var spans []Span
+	var walk func(c *Call)
+	walk = func(c *Call) {
+		spans = append(spans, Span{
+			ID:    c.Log.ID,
+			Name:  c.Name(),
+			Level: c.Log.Level,
+			Start: c.Start(),
+			End:   c.End(),
+			Depth: c.Depth,
+		})
+
+		for _, child := range criticalChildren(c) {
+			walk(child)
+		}
+	}
+	walk(c)
+
+	return CriticalPath{Root: root, Duration: c.Duration(), Spans: spans}, true
+}
+
+// criticalChildren returns children of c on its critical path,
+// in order of their start time.
+func criticalChildren(c *Call) []*Call {
+	var res []*Call
+	cursor := c.End()
+
+	for {
+		var next *Call
+		for _, child := range c.Children {
+			end := min(child.End(), c.End())
+			if end > cursor || child.Start() >= cursor {
+				continue
+			}
+			if next == nil || end > min(next.End(), c.End()) ||
+				(end == min(next.End(), c.End()) && child.Start() < next.Start()) {
+				next = child
+			}
+		}
+
+		if next == nil {
+			break
+		}
+		res = append(res, next)
+		cursor = next.Start()
+	}
+
+	slices.Reverse(res)
+	return res
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func spanNames(spans []Span) []string {
+	names := make([]string, 0, len(spans))
+	for _, s := range spans {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestCallTree_CriticalPath(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "handler", 10, 30),
+		metricLog(2, "auth", 11, 13, 1),
+		// fetch and cache run concurrently, fetch finishes last.
+		metricLog(3, "fetch", 14, 25, 1),
+		metricLog(4, "cache", 14, 18, 1),
+		metricLog(5, "dial", 14.5, 20, 1, 3),
+		metricLog(6, "read", 20, 24, 1, 3),
+		metricLog(7, "render", 26, 29, 1),
+	}
+
+	tree := BuildCallTree(logs)
+	path, ok := tree.CriticalPath(id(1))
+	assert.True(t, ok)
+	assert.Equal(t, id(1), path.Root)
+	assert.Equal(t, internal.Timestamp(20), path.Duration)
+	assert.Equal(t, []string{"handler", "auth", "fetch", "dial", "read", "render"}, spanNames(path.Spans))
+	assert.True(t, path.Contains(id(3)))
+	assert.False(t, path.Contains(id(4)))
+
+	_, ok = tree.CriticalPath(id(9))
+	assert.False(t, ok)
+}
+
+func TestCallTree_CriticalPath_Overlapping(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "root", 10, 20),
+		metricLog(2, "a", 11, 16, 1),
+		// b overlaps a and ends last, a only counts up to where b starts.
+		metricLog(3, "b", 15, 19, 1),
+		metricLog(4, "c", 12, 14, 1),
+	}
+
+	path, _ := BuildCallTree(logs).CriticalPath(id(1))
+	assert.Equal(t, []string{"root", "c", "b"}, spanNames(path.Spans))
+}
+
+func TestTimeline_HighlightCriticalPath(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "root", 10, 20),
+		metricLog(2, "slow", 11, 19, 1),
+		metricLog(3, "fast", 11, 12, 1),
+	}
+
+	path, _ := BuildCallTree(logs).CriticalPath(id(1))
+	tl := BuildTimeline(logs, Window{})
+	tl.HighlightCriticalPath(path)
+
+	assert.Equal(t, id(1), *tl.CriticalRoot)
+	critical := make(map[string]bool)
+	for _, s := range tl.Lanes[0].Spans {
+		critical[s.Name] = s.Critical
+	}
+	assert.Equal(t, map[string]bool{"root": true, "slow": true, "fast": false}, critical)
+}
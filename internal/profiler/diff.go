@@ -0,0 +1,162 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// FunctionDiff compares stats of a function between two profiles.
+// Stats are zero in a profile where the function was not called.
+type FunctionDiff struct {
+	Name   string
+	Before FunctionStats
+	After  FunctionStats
+}
+
+func (d FunctionDiff) CallsDelta() int {
+	return d.After.Calls - d.Before.Calls
+}
+
+func (d FunctionDiff) TotalDelta() internal.Timestamp {
+	return d.After.Total - d.Before.Total
+}
+
+func (d FunctionDiff) SelfDelta() internal.Timestamp {
+	return d.After.Self - d.Before.Self
+}
+
+func (d FunctionDiff) P95Delta() internal.Timestamp {
+	return d.After.P95 - d.Before.P95
+}
+
+// FlameDiffNode is a node of a differential flame graph.
+type FlameDiffNode struct {
+	Name     string
+	Before   internal.Timestamp
+	After    internal.Timestamp
+	Children []*FlameDiffNode
+}
+
+func (n *FlameDiffNode) Delta() internal.Timestamp {
+	return n.After - n.Before
+}
+
+// DiffSortKey selects the delta function diffs are sorted by.
+type DiffSortKey string
+
+const (
+	DiffSortByTotal DiffSortKey = "total"
+	DiffSortBySelf  DiffSortKey = "self"
+	DiffSortByCalls DiffSortKey = "calls"
+	DiffSortByP95   DiffSortKey = "p95"
+)
+
+type ProfileDiff struct {
+	Functions []FunctionDiff
+	Flame     *FlameDiffNode
+}
+
+// DiffProfiles compares function calls described by metric logs
+// of two captures, e.g. before and after a change.
+// Functions are sorted by [DiffSortByTotal].
+func DiffProfiles(before, after []internal.Log) ProfileDiff {
+	beforeTree := BuildCallTree(before)
+	afterTree := BuildCallTree(after)
+
+	functions := make(map[string]*FunctionDiff)
+	diff := func(name string) *FunctionDiff {
+		d, ok := functions[name]
+		if !ok {
+			d = &FunctionDiff{Name: name}
+			functions[name] = d
+		}
+		return d
+	}
+
+	for _, s := range ComputeFunctionStats(beforeTree) {
+		diff(s.Name).Before = s
+	}
+	for _, s := range ComputeFunctionStats(afterTree) {
+		diff(s.Name).After = s
+	}
+
+	res := ProfileDiff{
+		Functions: make([]FunctionDiff, 0, len(functions)),
+		Flame:     diffFlameNodes(BuildFlameGraph(beforeTree), BuildFlameGraph(afterTree)),
+	}
+	for _, d := range functions {
+		res.Functions = append(res.Functions, *d)
+	}
+
+	res.SortBy(DiffSortByTotal)
+	return res
+}
+
+// SortBy sorts functions by the absolute value of the delta selected by key,
+// largest change first.
+func (d *ProfileDiff) SortBy(key DiffSortKey) {
+	delta := func(f FunctionDiff) float64 {
+		switch key {
+		case DiffSortBySelf:
+			return float64(f.SelfDelta())
+		case DiffSortByCalls:
+			return float64(f.CallsDelta())
+		case DiffSortByP95:
+			return float64(f.P95Delta())
+		case DiffSortByTotal:
+		}
+		return float64(f.TotalDelta())
+	}
+
+	slices.SortFunc(d.Functions, func(a, b FunctionDiff) int {
+		da, db := delta(a), delta(b)
+		return cmp.Or(cmp.Compare(max(db, -db), max(da, -da)), cmp.Compare(a.Name, b.Name))
+	})
+}
+
+// diffFlameNodes merges two flame graphs by call path.
+// Either node may be nil.
+func diffFlameNodes(before, after *FlameNode) *FlameDiffNode {
+	n := &FlameDiffNode{}
+	children := make(map[string][2]*FlameNode)
+	var names []string
+
+	collect := func(node *FlameNode, i int) {
+		if node == nil {
+			return
+		}
+
+		n.Name = node.Name
+		for _, c := range node.Children {
+			pair, ok := children[c.Name]
+			if !ok {
+				names = append(names, c.Name)
+			}
+			pair[i] = c
+			children[c.Name] = pair
+		}
+	}
+
+	collect(before, 0)
+	collect(after, 1)
+
+	if before != nil {
+		n.Before = before.Total
+	}
+	if after != nil {
+		n.After = after.Total
+	}
+
+	slices.Sort(names)
+	for _, name := range names {
+		pair := children[name]
+		n.Children = append(n.Children, diffFlameNodes(pair[0], pair[1]))
+	}
+	return n
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCall_SelfDuration(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "root", 10, 20),
+		metricLog(2, "a", 11, 14, 1),
+		// Overlaps with a, counted once.
+		metricLog(3, "b", 12, 15, 1),
+		// Exceeds the parent, clipped.
+		metricLog(4, "c", 18, 25, 1),
+	}
+
+	tree := BuildCallTree(logs)
+	root, _ := tree.Call(id(1))
+	assert.Equal(t, internal.Timestamp(4), root.SelfDuration())
+}
+
+func TestComputeFunctionStats(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "handler", 10, 20),
+		metricLog(2, "query", 11, 13, 1),
+		metricLog(3, "query", 14, 18, 1),
+		metricLog(4, "handler", 30, 32),
+	}
+
+	stats := ComputeFunctionStats(BuildCallTree(logs))
+	assert.Equal(t, []FunctionStats{
+		{Name: "handler", Calls: 2, Total: 12, Self: 6, P95: 10},
+		{Name: "query", Calls: 2, Total: 6, Self: 6, P95: 4},
+	}, stats)
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, internal.Timestamp(0), Percentile(nil, 95))
+	assert.Equal(t, internal.Timestamp(1), Percentile([]internal.Timestamp{1}, 95))
+
+	values := make([]internal.Timestamp, 0, 100)
+	for i := 100; i > 0; i-- {
+		values = append(values, internal.Timestamp(i))
+	}
+	assert.Equal(t, internal.Timestamp(95), Percentile(values, 95))
+	assert.Equal(t, internal.Timestamp(50), Percentile(values, 50))
+	assert.Equal(t, internal.Timestamp(1), Percentile(values, 0))
+}
+
+func TestBuildFlameGraph(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "handler", 10, 20),
+		metricLog(2, "query", 11, 13, 1),
+		metricLog(3, "handler", 30, 32),
+		metricLog(4, "query", 30.5, 31.5, 3),
+	}
+
+	root := BuildFlameGraph(BuildCallTree(logs))
+	assert.Equal(t, FlameGraphRootName, root.Name)
+	assert.Equal(t, internal.Timestamp(12), root.Total)
+	assert.Len(t, root.Children, 1)
+
+	handler := root.Children[0]
+	assert.Equal(t, 2, handler.Calls)
+	assert.Equal(t, internal.Timestamp(9), handler.Self)
+	assert.Len(t, handler.Children, 1)
+	assert.Equal(t, internal.Timestamp(3), handler.Children[0].Total)
+}
+
+func TestDiffProfiles(t *testing.T) {
+	before := []internal.Log{
+		metricLog(1, "handler", 10, 20),
+		metricLog(2, "query", 11, 13, 1),
+		metricLog(3, "render", 14, 15, 1),
+	}
+	after := []internal.Log{
+		metricLog(1, "handler", 10, 25),
+		metricLog(2, "query", 11, 19, 1),
+		metricLog(3, "cache", 19, 20, 1),
+	}
+
+	diff := DiffProfiles(before, after)
+	names := make([]string, 0, len(diff.Functions))
+	for _, f := range diff.Functions {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"query", "handler", "cache", "render"}, names)
+
+	query := diff.Functions[0]
+	assert.Equal(t, internal.Timestamp(6), query.TotalDelta())
+	assert.Equal(t, 0, query.CallsDelta())
+
+	render := diff.Functions[3]
+	assert.Equal(t, -1, render.CallsDelta())
+	assert.Equal(t, 0, render.After.Calls)
+
+	diff.SortBy(DiffSortByCalls)
+	assert.Equal(t, "cache", diff.Functions[0].Name)
+
+	assert.Equal(t, FlameGraphRootName, diff.Flame.Name)
+	assert.Equal(t, internal.Timestamp(5), diff.Flame.Delta())
+	handler := diff.Flame.Children[0]
+	assert.Equal(t, "handler", handler.Name)
+	assert.Len(t, handler.Children, 3)
+	assert.Equal(t, "cache", handler.Children[0].Name)
+	assert.Equal(t, internal.Timestamp(0), handler.Children[0].Before)
+	assert.Equal(t, internal.Timestamp(1), handler.Children[0].After)
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+type FindingKind int
+
+const (
+	// A call stack refers to logs that were never received.
+	FindingMissingLog FindingKind = iota + 1
+	// A call starts before or ends after its parent call.
+	FindingOutsideParent
+	// A call ends before it starts.
+	FindingNegativeDuration
+	// Calls are their own ancestors.
+	FindingCycle
+)
+
+func (k FindingKind) String() string {
+	switch k {
+	case FindingMissingLog:
+		return "missing log"
+	case FindingOutsideParent:
+		return "outside parent"
+	case FindingNegativeDuration:
+		return "negative duration"
+	case FindingCycle:
+		return "cycle"
+	}
+	return "unknown"
+}
+
+// Finding is an inconsistency found in a metric log.
+type Finding struct {
+	Kind FindingKind
+	ID   internal.LogID
+
+	// Logs involved in the finding: missing logs, the parent call,
+	// or other calls in the cycle.
+	Related []internal.LogID
+}
+
+// HealthReport lists inconsistencies in call stacks of metric logs
+// that make reconstructed call trees unreliable.
+type HealthReport struct {
+	Calls    int
+	Findings []Finding
+}
+
+func (r *HealthReport) Healthy() bool {
+	return len(r.Findings) == 0
+}
+
+// Count returns the number of findings of the given kind.
+func (r *HealthReport) Count(kind FindingKind) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// CheckHealth validates call stacks of metric logs within w. Logs of any
+// type can be referred to by call stacks, but only metric logs are parents.
+// Ancestors and parents are looked up among all logs, within w or not.
+func CheckHealth(logs []internal.Log, w Window) HealthReport {
+	var report HealthReport
+	received := make(map[internal.LogID]bool, len(logs))
+	calls := make(map[internal.LogID]*internal.Log)
+	spans := make(map[spanKey]internal.LogID)
+
+	for i := range logs {
+		log := &logs[i]
+		received[log.ID] = true
+		if log.Type() == internal.LogTypeMetric {
+			calls[log.ID] = log
+			if log.SpanID != "" {
+				spans[spanKey{log.TraceID, log.SpanID}] = log.ID
+			}
+		}
+	}
+	reported := func(id internal.LogID) bool {
+		log, ok := calls[id]
+		return ok && w.Contains(logRange(log))
+	}
+
+	parents := make(map[internal.LogID]internal.LogID, len(calls))
+	for id, log := range calls {
+		parentID, hasParent := parentCall(log, func(id internal.LogID) bool {
+			_, ok := calls[id]
+			return ok
+		}, spans)
+		if hasParent {
+			parents[id] = parentID
+		}
+		if !reported(id) {
+			continue
+		}
+		report.Calls++
+
+		var missing []internal.LogID
+		for _, ancestor := range log.FunctionCallStack {
+			if !received[ancestor] {
+				missing = append(missing, ancestor)
+			}
+		}
+		if len(missing) > 0 {
+			report.Findings = append(report.Findings, Finding{Kind: FindingMissingLog, ID: id, Related: missing})
+		}
+
+		if log.FunctionCallEndedAt < log.FunctionCallStartedAt {
+			report.Findings = append(report.Findings, Finding{Kind: FindingNegativeDuration, ID: id})
+		}
+
+		if slices.Contains(log.FunctionCallStack, id) {
+			report.Findings = append(report.Findings, Finding{Kind: FindingCycle, ID: id, Related: []internal.LogID{id}})
+		}
+
+		if !hasParent {
+			continue
+		}
+
+		parent := calls[parentID]
+		if log.FunctionCallStartedAt < parent.FunctionCallStartedAt ||
+			log.FunctionCallEndedAt > parent.FunctionCallEndedAt {
+			report.Findings = append(report.Findings, Finding{
+				Kind: FindingOutsideParent, ID: id, Related: []internal.LogID{parentID},
+			})
+		}
+	}
+
+	for _, f := range findCycles(parents) {
+		if reported(f.ID) || slices.ContainsFunc(f.Related, reported) {
+			report.Findings = append(report.Findings, f)
+		}
+	}
+
+	slices.SortFunc(report.Findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.ID.ProducerID, b.ID.ProducerID),
+			cmp.Compare(a.ID.SequenceNumber, b.ID.SequenceNumber),
+		)
+	})
+	return report
+}
+
+// findCycles reports every cycle in the call to parent mapping once.
+func findCycles(parents map[internal.LogID]internal.LogID) []Finding {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	var findings []Finding
+	state := make(map[internal.LogID]int, len(parents))
+
+	for start := range parents {
+		var path []internal.LogID
+		id, ok := start, true
+		for ok && state[id] == unvisited {
+			state[id] = visiting
+			path = append(path, id)
+			id, ok = parents[id]
+		}
+
+		if ok && state[id] == visiting {
+			i := slices.Index(path, id)
+			cycle := slices.Clone(path[i:])
+			slices.SortFunc(cycle, func(a, b internal.LogID) int {
+				return cmp.Or(
+					cmp.Compare(a.ProducerID, b.ProducerID),
+					cmp.Compare(a.SequenceNumber, b.SequenceNumber),
+				)
+			})
+			findings = append(findings, Finding{Kind: FindingCycle, ID: cycle[0], Related: cycle[1:]})
+		}
+
+		for _, id := range path {
+			state[id] = done
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHealth_Healthy(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "root", 10, 20),
+		metricLog(2, "child", 11, 19, 1),
+		infoLog(3, 12, "hello"),
+		// Info logs can be referred to, e.g. a request log.
+		metricLog(4, "grandchild", 12, 13, 3, 1, 2),
+	}
+
+	report := CheckHealth(logs, Window{})
+	assert.True(t, report.Healthy())
+	assert.Equal(t, 3, report.Calls)
+}
+
+func TestCheckHealth(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "root", 10, 20),
+		metricLog(2, "missing", 11, 12, 1, 9),
+		metricLog(3, "outside", 15, 25, 1),
+		metricLog(4, "negative", 18, 16, 1),
+		metricLog(5, "a", 30, 40, 6),
+		metricLog(6, "b", 31, 39, 5),
+		metricLog(7, "self", 50, 51, 7),
+	}
+
+	report := CheckHealth(logs, Window{})
+	assert.Equal(t, []Finding{
+		{Kind: FindingMissingLog, ID: id(2), Related: []internal.LogID{id(9)}},
+		{Kind: FindingOutsideParent, ID: id(3), Related: []internal.LogID{id(1)}},
+		{Kind: FindingOutsideParent, ID: id(5), Related: []internal.LogID{id(6)}},
+		{Kind: FindingNegativeDuration, ID: id(4)},
+		{Kind: FindingCycle, ID: id(5), Related: []internal.LogID{id(6)}},
+		{Kind: FindingCycle, ID: id(7), Related: []internal.LogID{id(7)}},
+	}, report.Findings)
+	assert.Equal(t, 2, report.Count(FindingCycle))
+	assert.False(t, report.Healthy())
+}
+
+func TestCheckHealth_Window(t *testing.T) {
+	logs := []internal.Log{
+		infoLog(1, 5, "request"),
+		metricLog(2, "root", 10, 40, 1),
+		metricLog(3, "missing", 21, 22, 1, 2, 9),
+		metricLog(4, "outside", 35, 45, 1, 2),
+		metricLog(5, "a", 50, 60, 6),
+		metricLog(6, "b", 51, 59, 5),
+	}
+
+	// The request log is before the window, but it is not missing.
+	report := CheckHealth(logs, Window{From: 20, To: 30})
+	assert.Equal(t, 2, report.Calls)
+	assert.Equal(t, []Finding{
+		{Kind: FindingMissingLog, ID: id(3), Related: []internal.LogID{id(9)}},
+	}, report.Findings)
+
+	// Cycles are reported if any of their calls is within the window.
+	report = CheckHealth(logs, Window{From: 50, To: 50})
+	assert.Equal(t, 1, report.Calls)
+	assert.Equal(t, []Finding{
+		{Kind: FindingOutsideParent, ID: id(5), Related: []internal.LogID{id(6)}},
+		{Kind: FindingCycle, ID: id(5), Related: []internal.LogID{id(6)}},
+	}, report.Findings)
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// FunctionStats aggregates all calls of a single function.
+type FunctionStats struct {
+	Name  string
+	Calls int
+	Total internal.Timestamp
+	Self  internal.Timestamp
+	P95   internal.Timestamp
+}
+
+// ComputeFunctionStats aggregates calls in tree per function name,
+// sorted by total time spent in descending order.
+func ComputeFunctionStats(tree *CallTree) []FunctionStats {
+	stats := make(map[string]*FunctionStats)
+	durations := make(map[string][]internal.Timestamp)
+
+	tree.Walk(func(c *Call) bool {
+		name := c.Name()
+		s, ok := stats[name]
+		if !ok {
+			s = &FunctionStats{Name: name}
+			stats[name] = s
+		}
+
+		s.Calls++
+		s.Total += c.Duration()
+		s.Self += c.SelfDuration()
+		durations[name] = append(durations[name], c.Duration())
+		return true
+	})
+
+	res := make([]FunctionStats, 0, len(stats))
+	for name, s := range stats {
+		s.P95 = Percentile(durations[name], 95)
+		res = append(res, *s)
+	}
+
+	slices.SortFunc(res, func(a, b FunctionStats) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), cmp.Compare(a.Name, b.Name))
+	})
+	return res
+}
+
+// Percentile returns the p-th percentile of values using the nearest-rank method.
+// The values are sorted in place.
+func Percentile(values []internal.Timestamp, p float64) internal.Timestamp {
+	if len(values) == 0 {
+		return 0
+	}
+
+	slices.Sort(values)
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	return values[min(max(rank, 1), len(values))-1]
+}
+
+// FlameNode is a node of a flame graph,
+// aggregating calls that share the same call path.
+type FlameNode struct {
+	Name     string
+	Calls    int
+	Total    internal.Timestamp
+	Self     internal.Timestamp
+	Children []*FlameNode
+}
+
+func (n *FlameNode) child(name string) *FlameNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	c := &FlameNode{Name: name}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// FlameGraphRootName is the name of the synthetic root of a flame graph.
+const FlameGraphRootName = "all"
+
+// BuildFlameGraph merges calls in tree by their call path.
+// Children of every node are sorted by name.
+func BuildFlameGraph(tree *CallTree) *FlameNode {
+	root := &FlameNode{Name: FlameGraphRootName}
+
+	var add func(parent *FlameNode, c *Call)
+	add = func(parent *FlameNode, c *Call) {
+		n := parent.child(c.Name())
+		n.Calls++
+		n.Total += c.Duration()
+		n.Self += c.SelfDuration()
+		for _, child := range c.Children {
+			add(n, child)
+		}
+	}
+
+	for _, c := range tree.Roots {
+		add(root, c)
+		root.Calls++
+		root.Total += c.Duration()
+	}
+
+	sortFlameNodes(root)
+	return root
+}
+
+func sortFlameNodes(n *FlameNode) {
+	slices.SortFunc(n.Children, func(a, b *FlameNode) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	for _, c := range n.Children {
+		sortFlameNodes(c)
+	}
+}
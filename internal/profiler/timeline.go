@@ -0,0 +1,271 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// ThreadAttrPaths are attribute paths checked, in order,
+// to split a producer's timeline into per-thread lanes.
+var ThreadAttrPaths = []string{
+	"attrs.thread",
+	"attrs.thread_id",
+	"attrs.goroutine",
+}
+
+// Window is a time range of the timeline.
+// A zero bound leaves that side of the range open.
+type Window struct {
+	From internal.Timestamp
+	To   internal.Timestamp
+}
+
+func (w Window) Width() internal.Timestamp {
+	return w.To - w.From
+}
+
+// Contains reports whether the range [start, end] overlaps the window.
+func (w Window) Contains(start, end internal.Timestamp) bool {
+	if w.From != 0 && end < w.From {
+		return false
+	}
+	if w.To != 0 && start > w.To {
+		return false
+	}
+	return true
+}
+
+// Zoom scales the window by factor around its center.
+// A factor below 1 zooms in.
+func (w Window) Zoom(factor float64) Window {
+	center := w.From + w.Width()/2
+	half := w.Width() / 2 * internal.Timestamp(factor)
+	return Window{From: center - half, To: center + half}
+}
+
+// Pan shifts the window by a fraction of its width.
+// A negative fraction pans to earlier times.
+func (w Window) Pan(fraction float64) Window {
+	shift := w.Width() * internal.Timestamp(fraction)
+	return Window{From: w.From + shift, To: w.To + shift}
+}
+
+// FilterLogs returns logs within w.
+// Metric logs are included if their function call overlaps w.
+func FilterLogs(logs []internal.Log, w Window) []internal.Log {
+	res := make([]internal.Log, 0, len(logs))
+	for i := range logs {
+		start, end := logRange(&logs[i])
+		if w.Contains(start, end) {
+			res = append(res, logs[i])
+		}
+	}
+	return res
+}
+
+func logRange(log *internal.Log) (start, end internal.Timestamp) {
+	if log.Type() == internal.LogTypeMetric {
+		return log.FunctionCallStartedAt, log.FunctionCallEndedAt
+	}
+	return log.Timestamp, log.Timestamp
+}
+
+// Span is a function call drawn as a bar on the timeline.
+type Span struct {
+	ID    internal.LogID     `json:"id"`
+	Name  string             `json:"name"`
+	Level string             `json:"level,omitempty"`
+	Start internal.Timestamp `json:"start"`
+	End   internal.Timestamp `json:"end"`
+	Depth int                `json:"depth"`
+
+	// Whether the span is on the highlighted critical path.
+	Critical bool `json:"-"`
+	// Description of the flag on the span, such as an anomaly, if any.
+	Flag string `json:"-"`
+}
+
+// Tick is an info log drawn as a mark on the timeline.
+type Tick struct {
+	ID        internal.LogID
+	Level     string
+	Message   string
+	Timestamp internal.Timestamp
+}
+
+// Marker flags a time on the lanes of a producer, such as an anomaly.
+type Marker struct {
+	Timestamp internal.Timestamp
+	Title     string
+}
+
+// Lane groups spans and ticks of a single producer thread.
+type Lane struct {
+	ProducerID string
+	Thread     string
+	Spans      []Span
+	Ticks      []Tick
+	Markers    []Marker
+	MaxDepth   int
+}
+
+func (l *Lane) Name() string {
+	if l.Thread == "" {
+		return l.ProducerID
+	}
+	return l.ProducerID + "/" + l.Thread
+}
+
+type Timeline struct {
+	Window Window
+	Lanes  []Lane
+
+	// Root of the highlighted critical path, if any.
+	CriticalRoot *internal.LogID
+	// Trace the timeline is narrowed down to, if any.
+	TraceID string
+}
+
+// HighlightCriticalPath marks spans on p as critical.
+func (tl *Timeline) HighlightCriticalPath(p CriticalPath) {
+	tl.CriticalRoot = &p.Root
+	for i := range tl.Lanes {
+		for j := range tl.Lanes[i].Spans {
+			span := &tl.Lanes[i].Spans[j]
+			span.Critical = p.Contains(span.ID)
+		}
+	}
+}
+
+// Mark adds m to the lanes of the producer if it is within the window.
+func (tl *Timeline) Mark(producerID string, m Marker) {
+	if !tl.Window.Contains(m.Timestamp, m.Timestamp) {
+		return
+	}
+	for i := range tl.Lanes {
+		if tl.Lanes[i].ProducerID == producerID {
+			tl.Lanes[i].Markers = append(tl.Lanes[i].Markers, m)
+		}
+	}
+}
+
+// FlagSpan flags the span of the log with the given id, if any.
+func (tl *Timeline) FlagSpan(id internal.LogID, flag string) {
+	for i := range tl.Lanes {
+		for j := range tl.Lanes[i].Spans {
+			if span := &tl.Lanes[i].Spans[j]; span.ID == id {
+				span.Flag = flag
+			}
+		}
+	}
+}
+
+type laneKey struct {
+	producerID string
+	thread     string
+}
+
+// BuildTimeline lays out logs in lanes per producer and thread.
+// Metric logs become spans nested by their call depth,
+// info logs become ticks. Only logs within w are included.
+//
+// If w has open bounds, they are set to fit included logs.
+func BuildTimeline(logs []internal.Log, w Window) Timeline {
+	tree := BuildCallTree(logs)
+	lanes := make(map[laneKey]*Lane)
+
+	var bounds Window
+	extend := func(start, end internal.Timestamp) {
+		if bounds.From == 0 || start < bounds.From {
+			bounds.From = start
+		}
+		if end > bounds.To {
+			bounds.To = end
+		}
+	}
+
+	lane := func(log *internal.Log) *Lane {
+		key := laneKey{producerID: log.ID.ProducerID, thread: logThread(log)}
+		l, ok := lanes[key]
+		if !ok {
+			l = &Lane{ProducerID: key.producerID, Thread: key.thread}
+			lanes[key] = l
+		}
+		return l
+	}
+
+	for i := range logs {
+		log := &logs[i]
+
+		if call, ok := tree.Call(log.ID); ok && call.Log == log {
+			if !w.Contains(call.Start(), call.End()) {
+				continue
+			}
+
+			l := lane(log)
+			l.Spans = append(l.Spans, Span{
+				ID:    log.ID,
+				Name:  call.Name(),
+				Level: log.Level,
+				Start: call.Start(),
+				End:   call.End(),
+				Depth: call.Depth,
+			})
+			l.MaxDepth = max(l.MaxDepth, call.Depth)
+			extend(call.Start(), call.End())
+			continue
+		}
+
+		if !w.Contains(log.Timestamp, log.Timestamp) {
+			continue
+		}
+
+		l := lane(log)
+		l.Ticks = append(l.Ticks, Tick{
+			ID:        log.ID,
+			Level:     log.Level,
+			Message:   log.Message,
+			Timestamp: log.Timestamp,
+		})
+		extend(log.Timestamp, log.Timestamp)
+	}
+
+	tl := Timeline{Window: w, Lanes: make([]Lane, 0, len(lanes))}
+	if tl.Window.From == 0 {
+		tl.Window.From = bounds.From
+	}
+	if tl.Window.To == 0 {
+		tl.Window.To = bounds.To
+	}
+
+	for _, l := range lanes {
+		slices.SortFunc(l.Spans, func(a, b Span) int {
+			return cmp.Or(cmp.Compare(a.Depth, b.Depth), cmp.Compare(a.Start, b.Start))
+		})
+		slices.SortFunc(l.Ticks, func(a, b Tick) int {
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		})
+		tl.Lanes = append(tl.Lanes, *l)
+	}
+	slices.SortFunc(tl.Lanes, func(a, b Lane) int {
+		return cmp.Or(cmp.Compare(a.ProducerID, b.ProducerID), cmp.Compare(a.Thread, b.Thread))
+	})
+
+	return tl
+}
+
+func logThread(log *internal.Log) string {
+	for _, path := range ThreadAttrPaths {
+		if v, ok := log.Attr(path); ok && v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package profiler
+
+import (
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	worker := metricLog(10, "work", 12, 14)
+	worker.ID.ProducerID = "worker"
+	worker.Attrs = map[string]any{"thread": "t1"}
+
+	logs := []internal.Log{
+		metricLog(2, "child", 11, 15, 1),
+		infoLog(3, 13, "hello"),
+		metricLog(1, "root", 10, 20),
+		worker,
+	}
+
+	tl := BuildTimeline(logs, Window{})
+	assert.Equal(t, Window{From: 10, To: 20}, tl.Window)
+	assert.Len(t, tl.Lanes, 2)
+
+	app := tl.Lanes[0]
+	assert.Equal(t, "app", app.Name())
+	assert.Equal(t, 1, app.MaxDepth)
+	assert.Equal(t, []Span{
+		{ID: id(1), Name: "root", Start: 10, End: 20, Depth: 0},
+		{ID: id(2), Name: "child", Start: 11, End: 15, Depth: 1},
+	}, app.Spans)
+	assert.Equal(t, []Tick{{ID: id(3), Level: "info", Message: "hello", Timestamp: 13}}, app.Ticks)
+
+	assert.Equal(t, "worker/t1", tl.Lanes[1].Name())
+	assert.Len(t, tl.Lanes[1].Spans, 1)
+}
+
+func TestBuildTimeline_Window(t *testing.T) {
+	logs := []internal.Log{
+		metricLog(1, "early", 1, 5),
+		metricLog(2, "overlapping", 8, 12),
+		infoLog(3, 9, "inside"),
+		infoLog(4, 20, "outside"),
+	}
+
+	tl := BuildTimeline(logs, Window{From: 10, To: 15})
+	assert.Equal(t, Window{From: 10, To: 15}, tl.Window)
+	assert.Len(t, tl.Lanes, 1)
+	assert.Len(t, tl.Lanes[0].Spans, 1)
+	assert.Equal(t, "overlapping", tl.Lanes[0].Spans[0].Name)
+	assert.Empty(t, tl.Lanes[0].Ticks)
+}
+
+func TestTimeline_MarkFlagSpan(t *testing.T) {
+	worker := infoLog(3, 14, "tick")
+	worker.ID.ProducerID = "worker"
+	logs := []internal.Log{
+		metricLog(1, "root", 10, 20),
+		metricLog(2, "child", 11, 15, 1),
+		worker,
+	}
+
+	tl := BuildTimeline(logs, Window{})
+	tl.Mark("app", Marker{Timestamp: 12, Title: "burst"})
+	tl.Mark("app", Marker{Timestamp: 30, Title: "outside"})
+	tl.FlagSpan(id(2), "slow")
+
+	assert.Equal(t, []Marker{{Timestamp: 12, Title: "burst"}}, tl.Lanes[0].Markers)
+	assert.Empty(t, tl.Lanes[1].Markers)
+	assert.Empty(t, tl.Lanes[0].Spans[0].Flag)
+	assert.Equal(t, "slow", tl.Lanes[0].Spans[1].Flag)
+}
+
+func TestWindow_ZoomPan(t *testing.T) {
+	w := Window{From: 10, To: 20}
+	assert.Equal(t, Window{From: 12.5, To: 17.5}, w.Zoom(0.5))
+	assert.Equal(t, Window{From: 5, To: 25}, w.Zoom(2))
+	assert.Equal(t, Window{From: 12.5, To: 22.5}, w.Pan(0.25))
+	assert.Equal(t, Window{From: 7.5, To: 17.5}, w.Pan(-0.25))
+}
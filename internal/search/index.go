@@ -0,0 +1,200 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package search indexes logs for full-text queries.
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// Index is an in-memory inverted index of tokens of log messages and
+// attribute values to positions of logs in a [internal.Store].
+// It is an [internal.LogIndex], kept in step with the store it is added
+// to. It is safe for concurrent use.
+type Index struct {
+	mu sync.Mutex
+
+	// Positions of logs with a token, ascending.
+	postings map[string][]int
+
+	// Tokens sorted for prefix queries, and tokens added since.
+	// Tokens no longer in postings are dropped when merged.
+	sorted []string
+	added  []string
+}
+
+func NewIndex() *Index {
+	return &Index{postings: make(map[string][]int)}
+}
+
+// terms calls fn with the tokens of log, possibly more than once.
+func terms(log *internal.Log, fn func(term string)) {
+	fields(log, func(_, value string) {
+		for _, tok := range Tokenize(value) {
+			fn(tok)
+		}
+	})
+}
+
+// Add adds the log at position pos. Positions must be added in
+// ascending order.
+func (idx *Index) Add(pos int, log *internal.Log) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	terms(log, func(term string) {
+		p := idx.postings[term]
+		if len(p) > 0 && p[len(p)-1] == pos {
+			return
+		}
+		if len(p) == 0 {
+			idx.added = append(idx.added, term)
+		}
+		idx.postings[term] = append(p, pos)
+	})
+}
+
+// Remove removes the log at position pos. Logs must be removed
+// oldest first.
+func (idx *Index) Remove(pos int, log *internal.Log) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	terms(log, func(term string) {
+		p := idx.postings[term]
+		if len(p) == 0 || p[0] != pos {
+			return
+		}
+		if len(p) == 1 {
+			delete(idx.postings, term)
+			return
+		}
+		idx.postings[term] = p[1:]
+	})
+}
+
+// Len returns the number of distinct tokens in the index.
+func (idx *Index) Len() int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return len(idx.postings)
+}
+
+// Search returns positions of logs that may match q, ascending,
+// and whether they all do. Logs found for phrases and field clauses
+// must be matched with [Query.Match]. Search returns nil for an empty query.
+func (idx *Index) Search(q Query) ([]int, bool) {
+	if q.Empty() {
+		return nil, true
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	lists := make([][]int, 0, len(q.clauses))
+	for _, c := range q.clauses {
+		for i, term := range c.terms {
+			if c.prefix && i == len(c.terms)-1 {
+				lists = append(lists, idx.prefix(term))
+			} else {
+				lists = append(lists, idx.postings[term])
+			}
+		}
+	}
+
+	// Intersect the shortest lists first.
+	slices.SortFunc(lists, func(a, b []int) int { return len(a) - len(b) })
+	res := slices.Clone(lists[0])
+	for _, list := range lists[1:] {
+		if len(res) == 0 {
+			break
+		}
+		res = intersect(res, list)
+	}
+	return res, q.exact()
+}
+
+// prefix returns positions of logs with tokens starting with p.
+// Must be called with idx.mu held.
+func (idx *Index) prefix(p string) []int {
+	idx.merge()
+
+	i := sort.SearchStrings(idx.sorted, p)
+	var lists [][]int
+	for ; i < len(idx.sorted) && strings.HasPrefix(idx.sorted[i], p); i++ {
+		lists = append(lists, idx.postings[idx.sorted[i]])
+	}
+
+	switch len(lists) {
+	case 0:
+		return nil
+	case 1:
+		return lists[0]
+	}
+
+	var res []int
+	for _, list := range lists {
+		res = append(res, list...)
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+// merge merges added tokens into sorted tokens, dropping tokens
+// no longer indexed. Must be called with idx.mu held.
+func (idx *Index) merge() {
+	if len(idx.added) == 0 && len(idx.sorted) <= 2*len(idx.postings) {
+		return
+	}
+
+	slices.Sort(idx.added)
+	added := slices.Compact(idx.added)
+
+	merged := make([]string, 0, len(idx.postings))
+	keep := func(term string) {
+		if _, ok := idx.postings[term]; ok && (len(merged) == 0 || merged[len(merged)-1] != term) {
+			merged = append(merged, term)
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(idx.sorted) || j < len(added) {
+		if j == len(added) || (i < len(idx.sorted) && idx.sorted[i] <= added[j]) {
+			keep(idx.sorted[i])
+			i++
+		} else {
+			keep(added[j])
+			j++
+		}
+	}
+
+	idx.sorted = merged
+	idx.added = added[:0]
+}
+
+// intersect returns positions in both ascending lists a and b,
+// reusing a.
+func intersect(a, b []int) []int {
+	res := a[:0]
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			res = append(res, a[i])
+			i++
+			j++
+		}
+	}
+	return res
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package search
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// clause matches logs with a value containing terms in a row,
+// the value at field if it is set. If prefix is set, the last term
+// matches tokens it is a prefix of.
+type clause struct {
+	field  string
+	terms  []string
+	prefix bool
+}
+
+func (c *clause) phrase() bool {
+	return len(c.terms) > 1
+}
+
+// fieldPrefix matches the field a clause is restricted to.
+var fieldPrefix = regexp.MustCompile(`^[A-Za-z_][\w.@-]*:`)
+
+// Query is a full-text query matching logs all clauses of which match
+// their message or attribute values.
+type Query struct {
+	clauses []clause
+}
+
+// ParseQuery parses a query of space separated clauses:
+//
+//	timeout            a term
+//	"connection reset" a phrase, terms in a row
+//	time*              a prefix of a term
+//	level:error        a term, phrase or prefix in the value of a field,
+//	                   a flattened attribute path such as attrs.user.name
+//
+// Words are tokenized as indexed values are, so user-42 is the phrase
+// of "user" and "42". Matching is case insensitive.
+func ParseQuery(s string) Query {
+	var q Query
+	for s != "" {
+		s = strings.TrimLeft(s, " \t\n")
+		if s == "" {
+			break
+		}
+
+		var field string
+		if m := fieldPrefix.FindString(s); m != "" {
+			field, s = m[:len(m)-1], s[len(m):]
+		}
+
+		var word string
+		quoted := s != "" && s[0] == '"'
+		if quoted {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				word, s = s[1:], ""
+			} else {
+				word, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(s, " \t\n")
+			if end < 0 {
+				end = len(s)
+			}
+			word, s = s[:end], s[end:]
+		}
+
+		c := clause{field: field, prefix: !quoted && strings.HasSuffix(word, "*")}
+		c.terms = Tokenize(word)
+		if len(c.terms) == 0 {
+			continue
+		}
+		if c.prefix && c.phrase() {
+			// Only the last term of a prefix word matches prefixes.
+			for _, term := range c.terms[:len(c.terms)-1] {
+				q.clauses = append(q.clauses, clause{field: field, terms: []string{term}})
+			}
+			c.terms = c.terms[len(c.terms)-1:]
+		}
+		q.clauses = append(q.clauses, c)
+	}
+	return q
+}
+
+// Empty reports whether q has no clauses and matches all logs.
+func (q Query) Empty() bool {
+	return len(q.clauses) == 0
+}
+
+// exact reports whether all logs found in an index for q match q.
+// Phrases and field clauses are found by their terms and must be
+// matched against logs.
+func (q Query) exact() bool {
+	return !slices.ContainsFunc(q.clauses, func(c clause) bool {
+		return c.phrase() || c.field != ""
+	})
+}
+
+// Match reports whether log matches q.
+func (q Query) Match(log *internal.Log) bool {
+	var values [][]string
+	for _, c := range q.clauses {
+		if c.field != "" {
+			v, _ := log.Attr(c.field)
+			s, ok := Format(v)
+			if !ok || !c.match(Tokenize(s)) {
+				return false
+			}
+			continue
+		}
+
+		if values == nil {
+			fields(log, func(_, value string) {
+				values = append(values, Tokenize(value))
+			})
+		}
+		if !slices.ContainsFunc(values, c.match) {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether tokens of a value contain the terms of c in a row.
+func (c *clause) match(tokens []string) bool {
+	n := len(c.terms)
+	for i := 0; i+n <= len(tokens); i++ {
+		ok := true
+		for j, term := range c.terms {
+			tok := tokens[i+j]
+			if c.prefix && j == n-1 {
+				ok = strings.HasPrefix(tok, term)
+			} else {
+				ok = tok == term
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"get", "api", "users", "42", "took", "3ms", "größe"},
+		Tokenize("GET /api/users/42 took 3ms, Größe"))
+	assert.Empty(t, Tokenize(" -- "))
+
+	long := strings.Repeat("é", MaxTokenLength)
+	tok := Tokenize(long)[0]
+	assert.LessOrEqual(t, len(tok), MaxTokenLength)
+	assert.True(t, strings.HasPrefix(long, tok))
+}
+
+func TestParseQuery(t *testing.T) {
+	q := ParseQuery(`  Timeout "connection  Reset" user-42 req* api/us* level:Error attrs.user.name:"Al Smi" path:/api/* "unterminated`)
+	assert.Equal(t, []clause{
+		{terms: []string{"timeout"}},
+		{terms: []string{"connection", "reset"}},
+		{terms: []string{"user", "42"}},
+		{terms: []string{"req"}, prefix: true},
+		{terms: []string{"api"}},
+		{terms: []string{"us"}, prefix: true},
+		{field: "level", terms: []string{"error"}},
+		{field: "attrs.user.name", terms: []string{"al", "smi"}},
+		{field: "path", terms: []string{"api"}, prefix: true},
+		{terms: []string{"unterminated"}},
+	}, q.clauses)
+
+	assert.True(t, ParseQuery(` * "" `).Empty())
+}
+
+func newLog(seq int, message string, attrs map[string]any) internal.Log {
+	return internal.Log{
+		ID:      internal.LogID{ProducerID: "app", SequenceNumber: seq},
+		Level:   "info",
+		Message: message,
+		Attrs:   attrs,
+	}
+}
+
+func TestQuery_Match(t *testing.T) {
+	log := newLog(1, "Connection reset by peer", map[string]any{
+		"user": map[string]any{"name": "Alice Smith"},
+		"code": 42.0,
+	})
+
+	for query, want := range map[string]bool{
+		"":                    true,
+		"reset":               true,
+		"RESET peer":          true,
+		`"connection reset"`:  true,
+		`"reset connection"`:  false,
+		"conn*":               true,
+		`"alice smi*"`:        false, // quoted words are not prefixes
+		"smi*":                true,
+		"info":                true,
+		"42":                  true,
+		"attrs.code:42":       true,
+		"level:info":          true,
+		"level:inf*":          true,
+		"level:reset":         false,
+		`message:"by peer"`:   true,
+		"attrs.user.name:al*": true,
+		"attrs.missing:x":     false,
+		"reset bob":           false,
+	} {
+		q := ParseQuery(query)
+		assert.Equal(t, want, q.Match(&log), query)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	s := internal.NewStore(4)
+	idx := NewIndex()
+	s.AddIndex(idx)
+
+	s.AddLogs([]internal.Log{
+		newLog(1, "connection reset by peer", nil),
+		newLog(2, "reset the connection", nil),
+		newLog(3, "request timeout", map[string]any{"path": "/api/users"}),
+		newLog(4, "requested user", nil),
+	})
+
+	search := func(query string) ([]int, bool) {
+		t.Helper()
+		return idx.Search(ParseQuery(query))
+	}
+
+	positions, exact := search("connection reset")
+	assert.Equal(t, []int{0, 1}, positions)
+	assert.True(t, exact)
+
+	positions, exact = search(`"connection reset"`)
+	assert.Equal(t, []int{0, 1}, positions)
+	assert.False(t, exact)
+
+	positions, _ = search("req*")
+	assert.Equal(t, []int{2, 3}, positions)
+	positions, _ = search("user*")
+	assert.Equal(t, []int{2, 3}, positions)
+	positions, _ = search("api users")
+	assert.Equal(t, []int{2}, positions)
+	positions, _ = search("missing")
+	assert.Empty(t, positions)
+	positions, _ = search("")
+	assert.Nil(t, positions)
+
+	// Evicting logs removes them from the index.
+	s.AddLogs([]internal.Log{
+		newLog(5, "reset again", nil),
+		newLog(6, "requeue", nil),
+	})
+	positions, _ = search("connection")
+	assert.Empty(t, positions)
+	positions, _ = search("reset")
+	assert.Equal(t, []int{4}, positions)
+	positions, _ = search("req*")
+	assert.Equal(t, []int{2, 3, 5}, positions)
+	positions, _ = search("peer*")
+	assert.Empty(t, positions)
+
+	logs := s.GetLogsAt([]int{4, 5})
+	require.Len(t, logs, 2)
+	assert.Equal(t, "reset again", logs[0].Message)
+}
+
+func TestIndex_Len(t *testing.T) {
+	idx := NewIndex()
+	log := newLog(1, "a b a", nil)
+	idx.Add(0, &log)
+	assert.Equal(t, 5, idx.Len()) // a, b, the level, producer id and sequence number
+
+	idx.Remove(0, &log)
+	assert.Zero(t, idx.Len())
+}
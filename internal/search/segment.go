@@ -0,0 +1,329 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package search
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/types"
+)
+
+// SegmentSize is the number of logs in an on-disk segment.
+const SegmentSize = 10_000
+
+// Segment describes an on-disk segment of logs evicted from the store.
+type Segment struct {
+	ID    uint64             `json:"id"`
+	From  internal.Timestamp `json:"from"`
+	To    internal.Timestamp `json:"to"`
+	Count int                `json:"count"`
+}
+
+// overlaps reports whether logs of the segment may be within
+// from and to, zero meaning unbounded.
+func (s Segment) overlaps(from, to internal.Timestamp) bool {
+	return (from == 0 || s.To >= from) && (to == 0 || s.From <= to)
+}
+
+// Archive keeps logs evicted from the store in on-disk segments of the
+// database, each holding the logs and an inverted index of their tokens
+// to offsets of logs in the segment, so that evicted logs can still be
+// searched. It is an [internal.LogIndex]: logs removed from the store
+// are buffered and written to segments by [Archive.Flush]. At most
+// maxSegments segments are kept, the oldest are deleted first.
+// It is safe for concurrent use.
+type Archive struct {
+	db          internal.DBReadWriter
+	maxSegments int
+
+	// flushMu serializes writes of segments.
+	flushMu sync.Mutex
+
+	mu      sync.Mutex
+	pending []internal.Log
+	// Segments in the database, oldest first.
+	segments []Segment
+}
+
+// NewArchive returns an archive of segments in db, loading the
+// segments already written.
+func NewArchive(db internal.DBReadWriter, maxSegments int) (*Archive, error) {
+	a := &Archive{db: db, maxSegments: maxSegments}
+
+	err := db.ForEach(types.GetSearchSegmentsBucketName(), func(_, value []byte) error {
+		var s Segment
+		if err := json.Unmarshal(value, &s); err != nil {
+			return err
+		}
+		a.segments = append(a.segments, s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load search segments: %w", err)
+	}
+	return a, nil
+}
+
+func segmentKey(id uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, id)
+}
+
+// Add does nothing, logs are archived once removed from the store.
+func (a *Archive) Add(int, *internal.Log) {}
+
+// Remove buffers log to be written to a segment.
+func (a *Archive) Remove(_ int, log *internal.Log) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending = append(a.pending, *log)
+}
+
+// Segments returns the segments in the database, oldest first.
+func (a *Archive) Segments() []Segment {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.segments)
+}
+
+// Pending returns the number of logs not written to a segment yet.
+func (a *Archive) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.pending)
+}
+
+// Flush writes buffered logs to segments of [SegmentSize] logs.
+// If all is set, the logs left over are written to a smaller segment.
+func (a *Archive) Flush(all bool) error {
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+
+	for {
+		a.mu.Lock()
+		n := min(len(a.pending), SegmentSize)
+		if n == 0 || (n < SegmentSize && !all) {
+			a.mu.Unlock()
+			return nil
+		}
+		logs := a.pending[:n:n]
+		var id uint64
+		if len(a.segments) > 0 {
+			id = a.segments[len(a.segments)-1].ID + 1
+		}
+		a.mu.Unlock()
+
+		s, err := a.write(id, logs)
+		if err != nil {
+			return err
+		}
+
+		a.mu.Lock()
+		a.pending = a.pending[n:]
+		a.segments = append(a.segments, s)
+		var stale []Segment
+		if a.maxSegments > 0 && len(a.segments) > a.maxSegments {
+			stale = slices.Clone(a.segments[:len(a.segments)-a.maxSegments])
+			a.segments = a.segments[len(stale):]
+		}
+		a.mu.Unlock()
+
+		for _, s := range stale {
+			if err := a.delete(s.ID); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// write writes logs to the segment id. The segment is only listed
+// once its logs and index are written.
+func (a *Archive) write(id uint64, logs []internal.Log) (Segment, error) {
+	idx := NewIndex()
+	s := Segment{ID: id, From: logs[0].Timestamp, To: logs[0].Timestamp, Count: len(logs)}
+	for i := range logs {
+		idx.Add(i, &logs[i])
+		s.From = min(s.From, logs[i].Timestamp)
+		s.To = max(s.To, logs[i].Timestamp)
+	}
+
+	key := segmentKey(id)
+	for _, record := range []struct {
+		bucket []byte
+		value  any
+	}{
+		{types.GetSearchSegmentLogsBucketName(), logs},
+		{types.GetSearchSegmentIndexBucketName(), idx.postings},
+		{types.GetSearchSegmentsBucketName(), s},
+	} {
+		data, err := json.Marshal(record.value)
+		if err != nil {
+			return s, fmt.Errorf("failed to marshal search segment: %w", err)
+		}
+		if err := a.db.Put(record.bucket, key, data); err != nil {
+			return s, fmt.Errorf("failed to write search segment: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// delete deletes the segment id, unlisting it first.
+func (a *Archive) delete(id uint64) error {
+	key := segmentKey(id)
+	for _, bucket := range [][]byte{
+		types.GetSearchSegmentsBucketName(),
+		types.GetSearchSegmentIndexBucketName(),
+		types.GetSearchSegmentLogsBucketName(),
+	} {
+		if err := a.db.Delete(bucket, key); err != nil {
+			return fmt.Errorf("failed to delete search segment: %w", err)
+		}
+	}
+	return nil
+}
+
+// Search returns at most limit archived logs within from and to,
+// zero meaning unbounded, matching q, newest first. Logs not written
+// to a segment yet are searched too.
+func (a *Archive) Search(q Query, from, to internal.Timestamp, limit int) ([]internal.Log, error) {
+	var res []internal.Log
+	add := func(log *internal.Log) bool {
+		within := (from == 0 || log.Timestamp >= from) && (to == 0 || log.Timestamp <= to)
+		if within && q.Match(log) {
+			res = append(res, *log)
+		}
+		return len(res) < limit
+	}
+
+	a.mu.Lock()
+	for i := len(a.pending) - 1; i >= 0 && len(res) < limit; i-- {
+		add(&a.pending[i])
+	}
+	segments := slices.Clone(a.segments)
+	a.mu.Unlock()
+
+	for i := len(segments) - 1; i >= 0 && len(res) < limit; i-- {
+		s := segments[i]
+		if !s.overlaps(from, to) {
+			continue
+		}
+
+		offsets, err := a.searchSegment(s.ID, q)
+		if err != nil {
+			return res, err
+		}
+		if offsets != nil && len(offsets) == 0 {
+			continue
+		}
+
+		logs, err := a.segmentLogs(s.ID)
+		if err != nil {
+			return res, err
+		}
+
+		for j := len(logs) - 1; j >= 0; j-- {
+			if offsets != nil {
+				if len(offsets) == 0 {
+					break
+				}
+				if offsets[len(offsets)-1] != j {
+					continue
+				}
+				offsets = offsets[:len(offsets)-1]
+			}
+			if !add(&logs[j]) {
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// LogsWithin returns an iterator over archived logs within from and to,
+// zero meaning unbounded, oldest segment first. It stops after yielding
+// an error if a segment cannot be read.
+func (a *Archive) LogsWithin(from, to internal.Timestamp) iter.Seq2[*internal.Log, error] {
+	return func(yield func(*internal.Log, error) bool) {
+		a.mu.Lock()
+		segments := slices.Clone(a.segments)
+		pending := slices.Clone(a.pending)
+		a.mu.Unlock()
+
+		within := func(log *internal.Log) bool {
+			return (from == 0 || log.Timestamp >= from) && (to == 0 || log.Timestamp <= to)
+		}
+
+		for _, s := range segments {
+			if !s.overlaps(from, to) {
+				continue
+			}
+
+			logs, err := a.segmentLogs(s.ID)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for i := range logs {
+				if within(&logs[i]) && !yield(&logs[i], nil) {
+					return
+				}
+			}
+		}
+
+		for i := range pending {
+			if within(&pending[i]) && !yield(&pending[i], nil) {
+				return
+			}
+		}
+	}
+}
+
+// segmentLogs returns logs of the segment id, none if it was deleted.
+func (a *Archive) segmentLogs(id uint64) ([]internal.Log, error) {
+	var logs []internal.Log
+	err := a.db.Get(types.GetSearchSegmentLogsBucketName(), segmentKey(id), func(value []byte) error {
+		if value == nil {
+			return nil
+		}
+		return json.Unmarshal(value, &logs)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search segment: %w", err)
+	}
+	return logs, nil
+}
+
+// searchSegment returns offsets of logs of the segment id that may
+// match q, ascending, or nil if q is empty.
+func (a *Archive) searchSegment(id uint64, q Query) ([]int, error) {
+	if q.Empty() {
+		return nil, nil
+	}
+
+	idx := NewIndex()
+	err := a.db.Get(types.GetSearchSegmentIndexBucketName(), segmentKey(id), func(value []byte) error {
+		if value == nil {
+			return nil
+		}
+		return json.Unmarshal(value, &idx.postings)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search segment index: %w", err)
+	}
+	for term := range idx.postings {
+		idx.added = append(idx.added, term)
+	}
+
+	offsets, _ := idx.Search(q)
+	if offsets == nil {
+		offsets = []int{}
+	}
+	return offsets, nil
+}
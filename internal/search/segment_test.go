@@ -0,0 +1,102 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package search
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	db := internal.NewBoltDBAt(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	a, err := NewArchive(db, 2)
+	require.NoError(t, err)
+
+	s := internal.NewStore(2)
+	s.AddIndex(a)
+
+	n := 2*SegmentSize + 5
+	logs := make([]internal.Log, n)
+	for i := range logs {
+		message := fmt.Sprintf("request %d", i)
+		if i%1000 == 0 {
+			message += " timeout"
+		}
+		logs[i] = newLog(i, message, map[string]any{"region": []string{"eu", "us", "ap"}[i%3]})
+		logs[i].Timestamp = internal.Timestamp(i)
+	}
+	s.AddLogs(logs)
+
+	messages := func(logs []internal.Log) []string {
+		var res []string
+		for _, log := range logs {
+			res = append(res, log.Message)
+		}
+		return res
+	}
+	search := func(query string, from, to internal.Timestamp, limit int) []string {
+		t.Helper()
+		logs, err := a.Search(ParseQuery(query), from, to, limit)
+		require.NoError(t, err)
+		return messages(logs)
+	}
+
+	// Evicted logs are searched before they are written to a segment.
+	assert.Equal(t, []string{"request 20000 timeout", "request 19000 timeout"}, search("timeout", 0, 0, 2))
+
+	require.NoError(t, a.Flush(false))
+	assert.Len(t, a.Segments(), 2)
+	assert.Equal(t, 3, a.Pending())
+
+	assert.Equal(t, []string{"request 20000 timeout", "request 19000 timeout"}, search("timeout", 0, 0, 2))
+	assert.Equal(t, []string{"request 9000 timeout", "request 8000 timeout"}, search("timeout", 0, 9500, 2))
+	assert.Equal(t, []string{"request 15000 timeout"}, search(`"request 15000"`, 0, 0, 10))
+	assert.Equal(t, []string{"request 20002", "request 20001"}, search("", 0, 0, 2))
+	assert.Equal(t, []string{"request 10000 timeout"}, search("us timeout", 10000, 12000, 10))
+	assert.Equal(t, []string{"request 1000 timeout", "request 0 timeout"}, search("time*", 0, 1000, 10))
+	assert.Empty(t, search("missing", 0, 0, 10))
+
+	var within []string
+	for log, err := range a.LogsWithin(9998, 10001) {
+		require.NoError(t, err)
+		within = append(within, log.Message)
+	}
+	assert.Equal(t, []string{"request 9998", "request 9999", "request 10000 timeout", "request 10001"}, within)
+
+	within = nil
+	for log, err := range a.LogsWithin(20001, 0) {
+		require.NoError(t, err)
+		within = append(within, log.Message)
+	}
+	assert.Equal(t, []string{"request 20001", "request 20002"}, within)
+
+	// The oldest segment is deleted beyond the maximum.
+	require.NoError(t, a.Flush(true))
+	segments := a.Segments()
+	require.Len(t, segments, 2)
+	assert.Equal(t, Segment{ID: 1, From: SegmentSize, To: 2*SegmentSize - 1, Count: SegmentSize}, segments[0])
+	assert.Equal(t, Segment{ID: 2, From: 2 * SegmentSize, To: 2*SegmentSize + 2, Count: 3}, segments[1])
+	assert.Zero(t, a.Pending())
+	assert.Empty(t, search("timeout", 0, 9999, 10))
+
+	// Segments are kept in the database.
+	a, err = NewArchive(db, 2)
+	require.NoError(t, err)
+	assert.Equal(t, segments, a.Segments())
+	assert.Equal(t, []string{"request 20002", "request 20001"}, search("", 0, 0, 2))
+
+	got, err := a.Search(ParseQuery("request 20000"), 0, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, logs[2*SegmentSize].ID, got[0].ID)
+	assert.Equal(t, "ap", got[0].Attrs["region"])
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package search
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// MaxTokenLength is the maximum length of a token in bytes,
+// longer tokens are truncated.
+const MaxTokenLength = 64
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// Tokenize splits s into lower case tokens of letters and digits.
+func Tokenize(s string) []string {
+	tokens := strings.FieldsFunc(s, isSeparator)
+	for i, tok := range tokens {
+		tok = strings.ToLower(tok)
+		if len(tok) > MaxTokenLength {
+			tok = truncate(tok)
+		}
+		tokens[i] = tok
+	}
+	return tokens
+}
+
+// truncate cuts tok to MaxTokenLength bytes at a rune boundary.
+func truncate(tok string) string {
+	n := 0
+	for i := range tok {
+		if i > MaxTokenLength {
+			break
+		}
+		n = i
+	}
+	return tok[:n]
+}
+
+// fields calls fn with the values of log that are indexed: its message
+// and string, number and boolean values at flattened attribute paths.
+func fields(log *internal.Log, fn func(path, value string)) {
+	for path, v := range log.AllAttrs() {
+		// Integer fields, such as the source line, are unset when zero.
+		if s, ok := Format(v); ok && s != "" && v != 0 {
+			fn(path, s)
+		}
+	}
+}
+
+// Format formats a value at a flattened attribute path as it is indexed.
+// It returns false for values that are not indexed, such as timestamps.
+func Format(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
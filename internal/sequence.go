@@ -0,0 +1,28 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package internal
+
+import "sync"
+
+// SequenceGenerator assigns ids to logs of sources that do not number
+// their logs, such as OTLP exporters or syslog. Sources share a generator
+// so that their logs never collide when they use the same producer id.
+type SequenceGenerator struct {
+	mu   sync.Mutex
+	last map[string]int
+}
+
+func NewSequenceGenerator() *SequenceGenerator {
+	return &SequenceGenerator{last: make(map[string]int)}
+}
+
+// Next returns the id of the next log of the producer.
+// Sequence numbers start at 1.
+func (g *SequenceGenerator) Next(producerID string) LogID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.last[producerID]++
+	return LogID{ProducerID: producerID, SequenceNumber: g.last[producerID]}
+}
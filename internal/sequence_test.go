@@ -0,0 +1,18 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceGenerator_Next(t *testing.T) {
+	g := NewSequenceGenerator()
+	assert.Equal(t, LogID{ProducerID: "a", SequenceNumber: 1}, g.Next("a"))
+	assert.Equal(t, LogID{ProducerID: "a", SequenceNumber: 2}, g.Next("a"))
+	assert.Equal(t, LogID{ProducerID: "b", SequenceNumber: 1}, g.Next("b"))
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"iter"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/aggregate"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
+	"github.com/KirilStrezikozin/logcrunch/internal/search"
+	"github.com/rs/zerolog"
+)
+
+type IAggregateService interface {
+	Aggregate(query string, window profiler.Window) (aggregate.Result, error)
+}
+
+type AggregateService struct {
+	store *internal.Store
+	// Archive of logs evicted from the store, nil if disabled.
+	archive *search.Archive
+	logger  zerolog.Logger
+}
+
+// NewAggregateService returns a service evaluating aggregation
+// queries over logs of store, and logs evicted from it to archive,
+// which may be nil.
+func NewAggregateService(
+	store *internal.Store,
+	archive *search.Archive,
+	parentLogger zerolog.Logger,
+) *AggregateService {
+	logger := parentLogger.
+		With().
+		Str("service", "aggregate").
+		Logger()
+
+	return &AggregateService{
+		store:   store,
+		archive: archive,
+		logger:  logger,
+	}
+}
+
+// Aggregate evaluates an aggregation query over logs within window,
+// archived ones included. See [aggregate.ParseQuery] for the query syntax.
+func (s *AggregateService) Aggregate(query string, window profiler.Window) (aggregate.Result, error) {
+	q, err := aggregate.ParseQuery(query)
+	if err != nil {
+		return aggregate.Result{}, err
+	}
+
+	res := q.Evaluate(s.logsWithin(window))
+
+	s.logger.Debug().
+		Str("query", query).
+		Int("series", len(res.Series)).
+		Msg("aggregate")
+	return res, nil
+}
+
+// logsWithin returns an iterator over archived logs within window,
+// followed by those in the store. Archived logs that cannot be read
+// are skipped.
+func (s *AggregateService) logsWithin(window profiler.Window) iter.Seq[*internal.Log] {
+	return func(yield func(*internal.Log) bool) {
+		if s.archive != nil {
+			for log, err := range s.archive.LogsWithin(window.From, window.To) {
+				if err != nil {
+					s.logger.Error().Err(err).Msg("archive read")
+					break
+				}
+				if !yield(log) {
+					return
+				}
+			}
+		}
+
+		for log := range s.store.LogsWithin(window.From, window.To) {
+			if !yield(log) {
+				return
+			}
+		}
+	}
+}
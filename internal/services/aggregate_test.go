@@ -0,0 +1,52 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
+	"github.com/KirilStrezikozin/logcrunch/internal/search"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateService_Archive(t *testing.T) {
+	archive, err := search.NewArchive(openTestDB(t), 1)
+	require.NoError(t, err)
+
+	store := internal.NewStore(2)
+	store.AddIndex(archive)
+	s := NewAggregateService(store, archive, zerolog.Nop())
+
+	for i, level := range []string{"error", "info", "error", "info", "info"} {
+		store.AddLog(internal.Log{
+			ID:        internal.LogID{ProducerID: "app", SequenceNumber: i},
+			Timestamp: internal.Timestamp(i),
+			Level:     level,
+		})
+	}
+	require.NoError(t, archive.Flush(false))
+	assert.Equal(t, 3, archive.Pending())
+
+	counts := func(window profiler.Window) map[string]int {
+		res, err := s.Aggregate("count() by level", window)
+		require.NoError(t, err)
+		counts := make(map[string]int)
+		for _, series := range res.Series {
+			counts[series.Group["level"]] = series.Count
+		}
+		return counts
+	}
+
+	assert.Equal(t, map[string]int{"error": 2, "info": 3}, counts(profiler.Window{}))
+	assert.Equal(t, map[string]int{"error": 1, "info": 2}, counts(profiler.Window{From: 1, To: 3}))
+
+	require.NoError(t, archive.Flush(true))
+	assert.Len(t, archive.Segments(), 1)
+	assert.Equal(t, map[string]int{"error": 2, "info": 3}, counts(profiler.Window{}))
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/alert"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// AlertEvaluationInterval is how often alert rules are evaluated.
+	AlertEvaluationInterval = time.Second
+	// AlertWebhookTimeout limits a webhook notification.
+	AlertWebhookTimeout = 10 * time.Second
+)
+
+type IAlertService interface {
+	GetAlerts() []alert.Alert
+}
+
+type AlertService struct {
+	engine *alert.Engine
+	client *http.Client
+	logger zerolog.Logger
+}
+
+// NewAlertService returns a service evaluating the alert rules configured
+// by the file at path over logs added to store. There are no rules if
+// path is empty.
+func NewAlertService(
+	store *internal.Store,
+	path string,
+	parentLogger zerolog.Logger,
+) (*AlertService, error) {
+	logger := parentLogger.
+		With().
+		Str("service", "alert").
+		Logger()
+
+	var cfg alert.Config
+	if path != "" {
+		var err error
+		if cfg, err = alert.LoadConfig(path); err != nil {
+			return nil, err
+		}
+	}
+
+	engine, err := alert.NewEngine(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alert engine: %w", err)
+	}
+	store.AddIndex(engine)
+
+	if path != "" {
+		logger.Info().Str("path", path).Int("rules", engine.Len()).Msg("alert rules loaded")
+	}
+
+	return &AlertService{
+		engine: engine,
+		client: &http.Client{Timeout: AlertWebhookTimeout},
+		logger: logger,
+	}, nil
+}
+
+// Run evaluates the rules until stop is closed, notifying webhooks
+// of alerts that fire and resolve.
+func (s *AlertService) Run(stop <-chan struct{}) {
+	if s.engine.Len() == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(AlertEvaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		for _, n := range s.engine.Evaluate() {
+			event := s.logger.Info()
+			if n.State == alert.StateFiring {
+				event = s.logger.Warn()
+			}
+			event.
+				Str("rule", n.Rule).
+				Str("state", n.State).
+				Float64("value", n.Value).
+				Msg("alert")
+
+			if len(n.Webhooks) != 0 {
+				go s.notify(n)
+			}
+		}
+	}
+}
+
+// notify notifies the webhooks of n, recording errors on the alert.
+func (s *AlertService) notify(n alert.Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), AlertWebhookTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, url := range n.Webhooks {
+		if err := alert.Notify(ctx, s.client, url, n); err != nil {
+			s.logger.Error().Err(err).Str("rule", n.Rule).Msg("alert webhook")
+			errs = append(errs, err)
+		}
+	}
+	s.engine.SetNotifyError(n.Rule, errors.Join(errs...))
+}
+
+// GetAlerts returns alerts of the rules, in the order they are configured.
+func (s *AlertService) GetAlerts() []alert.Alert {
+	return s.engine.Alerts()
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/anomaly"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
+	"github.com/rs/zerolog"
+)
+
+type IAnomalyService interface {
+	GetAnomalies(window profiler.Window) []anomaly.Anomaly
+	AnnotateTimeline(tl *profiler.Timeline)
+}
+
+type AnomalyService struct {
+	detector *anomaly.Detector
+	logger   zerolog.Logger
+}
+
+// NewAnomalyService returns a service detecting anomalous log rates and
+// latencies of logs added to store. Anomalies are logged when detected.
+func NewAnomalyService(
+	store *internal.Store,
+	cfg anomaly.Config,
+	parentLogger zerolog.Logger,
+) *AnomalyService {
+	logger := parentLogger.
+		With().
+		Str("service", "anomaly").
+		Logger()
+
+	detector := anomaly.NewDetector(cfg, func(a anomaly.Anomaly) {
+		logger.Info().
+			Str("kind", a.Kind).
+			Str("key", a.Key).
+			Float64("value", a.Value).
+			Float64("expected", a.Expected).
+			Float64("sigma", a.Sigma).
+			Msg("anomaly")
+	})
+	store.AddIndex(detector)
+
+	return &AnomalyService{
+		detector: detector,
+		logger:   logger,
+	}
+}
+
+// GetAnomalies returns anomalies within window, in the order they were
+// detected.
+func (s *AnomalyService) GetAnomalies(window profiler.Window) []anomaly.Anomaly {
+	return s.detector.Anomalies(window.From, window.To)
+}
+
+// AnnotateTimeline marks anomalous rates on the lanes of their producers
+// and flags spans of anomalously slow calls on tl.
+func (s *AnomalyService) AnnotateTimeline(tl *profiler.Timeline) {
+	for _, a := range s.detector.Anomalies(tl.Window.From, tl.Window.To) {
+		switch a.Kind {
+		case anomaly.KindRate:
+			tl.Mark(a.Key, profiler.Marker{Timestamp: a.Start, Title: a.String()})
+		case anomaly.KindLatency:
+			tl.FlagSpan(*a.LogID, a.String())
+		}
+	}
+}
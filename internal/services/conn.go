@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/metrics"
 	"github.com/KirilStrezikozin/logcrunch/internal/types"
 	"github.com/KirilStrezikozin/logcrunch/pkg/strings"
 	"github.com/rs/zerolog"
@@ -39,6 +40,8 @@ type ConnectionService struct {
 	wsClient   internal.IWebSocketControl
 	logService ILogService
 	logger     zerolog.Logger
+
+	reconnects *metrics.Counter
 }
 
 func NewConnectionService(
@@ -66,6 +69,14 @@ func NewConnectionService(
 	return s
 }
 
+// Instrument registers the number of WebSocket reconnects with reg.
+func (s *ConnectionService) Instrument(reg *metrics.Registry) {
+	s.reconnects = reg.NewCounter(
+		"logcrunch_websocket_reconnects_total",
+		"Reconnects to the WebSocket log source after the connection ended.",
+	)
+}
+
 // XXX: unsafely modifying the returned string will modify the internal buffer.
 func (s *ConnectionService) GetURL() (string, error) {
 	s.mu.Lock()
@@ -156,13 +167,20 @@ func (s *ConnectionService) connect() {
 const ReconnectDelay = 3 * time.Second
 
 func (s *ConnectionService) ReconnectLoop(interrupt <-chan struct{}) {
+	connecting := false // Whether a connect go-routine is running.
+
 	cancel := func() {
+		if !connecting {
+			return
+		}
+
 		// Close existing connection if any.
 		if err := s.wsClient.Close(); err != nil {
 			s.logger.Debug().Err(err).Msg("websocket client close failed")
 		}
 
 		<-s.connectDone // Wait for the connect go-routine to exit.
+		connecting = false
 		s.status.Store(int32(types.ConnectionStatusDisconnected))
 	}
 
@@ -175,8 +193,10 @@ func (s *ConnectionService) ReconnectLoop(interrupt <-chan struct{}) {
 		case <-s.doConnect:
 			s.logger.Info().Msgf("connecting to %s...", s.url.String())
 			cancel()
+			connecting = true
 			go s.connect()
 		case <-s.connectDone:
+			connecting = false
 			if err := s.wsClient.Close(); err != nil {
 				s.logger.Debug().Err(err).Msg("websocket client close failed")
 			}
@@ -190,6 +210,8 @@ func (s *ConnectionService) ReconnectLoop(interrupt <-chan struct{}) {
 				s.logger.Debug().Msg("reconnect loop interrupted, stopping...")
 				return
 			case <-time.After(ReconnectDelay):
+				s.reconnects.Inc()
+				connecting = true
 				go s.connect()
 			}
 		}
@@ -0,0 +1,61 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/catalog"
+	"github.com/rs/zerolog"
+)
+
+type IFieldService interface {
+	GetFields(prefix string) FieldCatalog
+	Suggest(query string, limit int) []string
+}
+
+// FieldCatalog lists cataloged fields.
+type FieldCatalog struct {
+	Fields []catalog.Field `json:"fields"`
+	// Dropped counts field occurrences not cataloged once
+	// [catalog.MaxFields] fields were seen.
+	Dropped int64 `json:"dropped"`
+}
+
+type FieldService struct {
+	catalog *catalog.Catalog
+	logger  zerolog.Logger
+}
+
+// NewFieldService returns a service cataloging fields of logs added to store.
+func NewFieldService(
+	store *internal.Store,
+	parentLogger zerolog.Logger,
+) *FieldService {
+	logger := parentLogger.
+		With().
+		Str("service", "fields").
+		Logger()
+
+	c := catalog.New()
+	store.AddIndex(c)
+
+	return &FieldService{
+		catalog: c,
+		logger:  logger,
+	}
+}
+
+// GetFields returns cataloged fields whose paths start with prefix.
+func (s *FieldService) GetFields(prefix string) FieldCatalog {
+	return FieldCatalog{
+		Fields:  s.catalog.Fields(prefix),
+		Dropped: s.catalog.Dropped(),
+	}
+}
+
+// Suggest returns at most limit completions of a search query.
+func (s *FieldService) Suggest(query string, limit int) []string {
+	return s.catalog.Suggest(query, limit)
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/histogram"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
+	"github.com/rs/zerolog"
+)
+
+type IHistogramService interface {
+	GetHistogram(window profiler.Window, buckets int) histogram.Histogram
+}
+
+type HistogramService struct {
+	index  *histogram.Index
+	logger zerolog.Logger
+}
+
+// NewHistogramService returns a service counting logs of store over time
+// with a time index kept in step with it.
+func NewHistogramService(
+	store *internal.Store,
+	parentLogger zerolog.Logger,
+) *HistogramService {
+	logger := parentLogger.
+		With().
+		Str("service", "histogram").
+		Logger()
+
+	index := histogram.NewIndex()
+	store.AddIndex(index)
+
+	return &HistogramService{
+		index:  index,
+		logger: logger,
+	}
+}
+
+// GetHistogram returns the number of logs within window by level,
+// in at most the given number of buckets.
+func (s *HistogramService) GetHistogram(window profiler.Window, buckets int) histogram.Histogram {
+	return s.index.Histogram(window.From, window.To, buckets)
+}
@@ -11,15 +11,21 @@ import (
 
 type ILogService interface {
 	ReadLoop() error
+	AddLogData(source string, data []byte) error
+	GetLog(id internal.LogID) (internal.Log, bool)
 }
 
 type LogService struct {
 	wsClient internal.IWebSocketReader
+	store    *internal.Store
+	pipeline IPipelineService
 	logger   zerolog.Logger
 }
 
 func NewLogService(
 	wsClient internal.IWebSocketReader,
+	store *internal.Store,
+	pipeline IPipelineService,
 	parentLogger zerolog.Logger,
 ) *LogService {
 	logger := parentLogger.
@@ -29,21 +35,38 @@ func NewLogService(
 
 	return &LogService{
 		wsClient: wsClient,
+		store:    store,
+		pipeline: pipeline,
 		logger:   logger,
 	}
 }
 
 func (s *LogService) ReadLoop() error {
 	return s.wsClient.Read(func(messageType int, p []byte) {
-		log, err := internal.NewLog(p)
-		if err != nil {
+		if err := s.AddLogData(SourceWebSocket, p); err != nil {
 			s.logger.Error().Err(err).Bytes("data", p).Msg("unparsable log data, skipping")
-			return
 		}
+	})
+}
 
-		s.logger.Debug().Str("id", string(log.ID)).Msg("log received")
+// AddLogData parses a single JSON encoded log ingested from the named
+// source and adds it to the store.
+func (s *LogService) AddLogData(source string, data []byte) error {
+	log, err := internal.NewLog(data)
+	if err != nil {
+		internal.CountParseError()
+		return err
+	}
 
-		// Change reader service -> log service
-		// s.store.AddLog(log)
-	})
+	s.logger.Debug().
+		Str("producer_id", log.ID.ProducerID).
+		Int("sequence_number", log.ID.SequenceNumber).
+		Msg("log received")
+
+	s.pipeline.Ingest(source, log)
+	return nil
+}
+
+func (s *LogService) GetLog(id internal.LogID) (internal.Log, bool) {
+	return s.store.GetLog(id)
 }
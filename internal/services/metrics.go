@@ -0,0 +1,85 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/logmetrics"
+	"github.com/KirilStrezikozin/logcrunch/internal/metrics"
+	"github.com/rs/zerolog"
+)
+
+type IMetricsService interface {
+	WriteMetrics(w io.Writer) error
+}
+
+type MetricsService struct {
+	reg    *metrics.Registry
+	logger zerolog.Logger
+}
+
+// NewMetricsService returns a service writing the metrics of reg, to
+// which it adds metrics of store and of parsed logs and those derived
+// from logs added to store configured by the file at path. No metrics
+// are derived from logs if path is empty.
+func NewMetricsService(
+	store *internal.Store,
+	reg *metrics.Registry,
+	path string,
+	parentLogger zerolog.Logger,
+) (*MetricsService, error) {
+	logger := parentLogger.
+		With().
+		Str("service", "metrics").
+		Logger()
+
+	var cfg logmetrics.Config
+	if path != "" {
+		var err error
+		if cfg, err = logmetrics.LoadConfig(path); err != nil {
+			return nil, err
+		}
+	}
+
+	recorder, err := logmetrics.NewRecorder(cfg, reg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log metrics: %w", err)
+	}
+	store.AddIndex(recorder)
+
+	if path != "" {
+		logger.Info().Str("path", path).Int("metrics", recorder.Len()).Msg("log metrics loaded")
+	}
+
+	reg.NewGaugeFunc(
+		"logcrunch_store_logs",
+		"Logs kept in memory.",
+		func() float64 { return float64(store.Len()) },
+	)
+	reg.NewCounterFunc(
+		"logcrunch_store_evicted_logs_total",
+		"Logs evicted from memory over the store capacity.",
+		func() float64 { return float64(store.Evicted()) },
+	)
+	reg.NewCounterFunc(
+		"logcrunch_log_parse_errors_total",
+		"Logs dropped as they failed to parse.",
+		func() float64 { return float64(internal.ParseErrors()) },
+	)
+
+	return &MetricsService{
+		reg:    reg,
+		logger: logger,
+	}, nil
+}
+
+// WriteMetrics writes all metrics to w in the Prometheus text
+// exposition format.
+func (s *MetricsService) WriteMetrics(w io.Writer) error {
+	return s.reg.Write(w)
+}
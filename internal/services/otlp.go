@@ -0,0 +1,66 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/otlp"
+	"github.com/rs/zerolog"
+)
+
+type IOTLPService interface {
+	ExportLogs(data []byte, format otlp.Format) error
+	ExportTraces(data []byte, format otlp.Format) error
+}
+
+type OTLPService struct {
+	pipeline IPipelineService
+	ids      *internal.SequenceGenerator
+	logger   zerolog.Logger
+}
+
+func NewOTLPService(
+	pipeline IPipelineService,
+	ids *internal.SequenceGenerator,
+	parentLogger zerolog.Logger,
+) *OTLPService {
+	logger := parentLogger.
+		With().
+		Str("service", "otlp").
+		Logger()
+
+	return &OTLPService{
+		pipeline: pipeline,
+		ids:      ids,
+		logger:   logger,
+	}
+}
+
+// ExportLogs decodes an OTLP logs export request and adds its logs to the store.
+func (s *OTLPService) ExportLogs(data []byte, format otlp.Format) error {
+	return s.export(otlp.DecodeLogs, data, format)
+}
+
+// ExportTraces decodes an OTLP traces export request and adds its spans
+// to the store as metric logs.
+func (s *OTLPService) ExportTraces(data []byte, format otlp.Format) error {
+	return s.export(otlp.DecodeTraces, data, format)
+}
+
+func (s *OTLPService) export(
+	decode func([]byte, otlp.Format, otlp.IDFunc) ([]internal.Log, error),
+	data []byte,
+	format otlp.Format,
+) error {
+	logs, err := decode(data, format, s.ids.Next)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debug().Int("count", len(logs)).Msg("OTLP logs received")
+
+	s.pipeline.Ingest(SourceOTLP, logs...)
+	return nil
+}
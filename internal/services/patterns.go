@@ -0,0 +1,69 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/patterns"
+	"github.com/rs/zerolog"
+)
+
+type IPatternService interface {
+	GetPatterns() []patterns.Pattern
+	GetPatternLogs(id int, limit int) (patterns.Pattern, []internal.Log, error)
+}
+
+type PatternService struct {
+	store  *internal.Store
+	miner  *patterns.Miner
+	logger zerolog.Logger
+}
+
+// NewPatternService returns a service mining patterns of messages of logs
+// added to store. New patterns are logged when they first appear.
+func NewPatternService(
+	store *internal.Store,
+	parentLogger zerolog.Logger,
+) *PatternService {
+	logger := parentLogger.
+		With().
+		Str("service", "patterns").
+		Logger()
+
+	miner := patterns.NewMiner(func(p patterns.Pattern) {
+		logger.Info().
+			Int("pattern", p.ID).
+			Str("template", p.Template).
+			Msg("new log pattern")
+	})
+	store.AddIndex(miner)
+
+	return &PatternService{
+		store:  store,
+		miner:  miner,
+		logger: logger,
+	}
+}
+
+// GetPatterns returns mined patterns, those matching the most logs first.
+func (s *PatternService) GetPatterns() []patterns.Pattern {
+	return s.miner.Patterns()
+}
+
+// GetPatternLogs returns the pattern with the given id and at most limit
+// of its logs in the store, newest first.
+func (s *PatternService) GetPatternLogs(id int, limit int) (patterns.Pattern, []internal.Log, error) {
+	p, positions, ok := s.miner.Pattern(id)
+	if !ok {
+		return p, nil, fmt.Errorf("%w: %d", internal.ErrPatternNotFound, id)
+	}
+
+	positions = positions[max(len(positions)-limit, 0):]
+	slices.Reverse(positions)
+	return p, s.store.GetLogsAt(positions), nil
+}
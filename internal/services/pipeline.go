@@ -0,0 +1,232 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/metrics"
+	"github.com/KirilStrezikozin/logcrunch/internal/pipeline"
+	"github.com/rs/zerolog"
+)
+
+// Names of the sources logs are ingested from, as seen by the pipeline.
+const (
+	SourceWebSocket = "websocket"
+	SourceHTTP      = "http"
+	SourceOTLP      = "otlp"
+	SourceSyslog    = "syslog"
+	SourceTail      = "tail"
+	SourceStdin     = "stdin"
+)
+
+// PipelineReloadInterval is how often the pipeline config file
+// is checked for changes.
+const PipelineReloadInterval = 2 * time.Second
+
+// PipelineStatus describes the loaded pipeline.
+type PipelineStatus struct {
+	// Path of the config file, empty if there is none.
+	Path     string    `json:"path"`
+	LoadedAt time.Time `json:"loaded_at"`
+	// Error reloading the config file, if it failed since it was last loaded.
+	ReloadError string `json:"reload_error,omitempty"`
+
+	pipeline.Metrics
+}
+
+type IPipelineService interface {
+	Ingest(source string, logs ...internal.Log)
+	Reload() error
+	GetStatus() PipelineStatus
+}
+
+type PipelineService struct {
+	store  *internal.Store
+	path   string
+	logger zerolog.Logger
+
+	mu       sync.RWMutex
+	pipeline *pipeline.Pipeline
+	loadedAt time.Time
+	// Modification time of the loaded config file.
+	modTime   time.Time
+	reloadErr error
+
+	ingested *metrics.Counter
+	dropped  *metrics.Counter
+}
+
+// NewPipelineService returns a service running ingested logs through
+// the pipeline configured by the file at path before they are stored.
+// Logs are stored as they are if path is empty.
+func NewPipelineService(
+	store *internal.Store,
+	path string,
+	parentLogger zerolog.Logger,
+) (*PipelineService, error) {
+	logger := parentLogger.
+		With().
+		Str("service", "pipeline").
+		Logger()
+
+	s := &PipelineService{
+		store:  store,
+		path:   path,
+		logger: logger,
+	}
+
+	if path == "" {
+		s.pipeline, _ = pipeline.New(pipeline.Config{})
+		s.loadedAt = time.Now()
+	} else if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Instrument registers the number of logs ingested and dropped
+// by the pipeline per source with reg.
+func (s *PipelineService) Instrument(reg *metrics.Registry) {
+	s.ingested = reg.NewCounter(
+		"logcrunch_ingested_logs_total",
+		"Logs ingested by source.",
+		"source",
+	)
+	s.dropped = reg.NewCounter(
+		"logcrunch_dropped_logs_total",
+		"Ingested logs dropped by the pipeline by source.",
+		"source",
+	)
+}
+
+// Ingest runs logs ingested from the named source through the pipeline
+// and stores the logs it keeps.
+func (s *PipelineService) Ingest(source string, logs ...internal.Log) {
+	s.mu.RLock()
+	p := s.pipeline
+	s.mu.RUnlock()
+
+	kept := make([]internal.Log, 0, len(logs))
+	for _, log := range logs {
+		if p.Process(source, &log) {
+			kept = append(kept, log)
+		}
+	}
+
+	s.ingested.Add(float64(len(logs)), source)
+	s.dropped.Add(float64(len(logs)-len(kept)), source)
+
+	switch len(kept) {
+	case 0:
+	case 1:
+		s.store.AddLog(kept[0])
+	default:
+		s.store.AddLogs(kept)
+	}
+}
+
+// Reload loads the config file again. On failure,
+// the previously loaded pipeline keeps running.
+func (s *PipelineService) Reload() error {
+	if s.path == "" {
+		return nil
+	}
+
+	p, modTime, err := loadPipeline(s.path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.reloadErr = err
+		return err
+	}
+
+	s.pipeline = p
+	s.loadedAt = time.Now()
+	s.modTime = modTime
+	s.reloadErr = nil
+
+	s.logger.Info().Str("path", s.path).Msg("pipeline loaded")
+	return nil
+}
+
+// loadPipeline returns the pipeline configured by the file at path
+// and the modification time of the file.
+func loadPipeline(path string) (*pipeline.Pipeline, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to stat pipeline config: %w", err)
+	}
+
+	cfg, err := pipeline.LoadConfig(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	p, err := pipeline.New(cfg)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to create pipeline: %w", err)
+	}
+	return p, info.ModTime(), nil
+}
+
+// Watch reloads the config file when it changes, until stop is closed.
+func (s *PipelineService) Watch(stop <-chan struct{}) {
+	if s.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(PipelineReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(s.path)
+		if err != nil {
+			continue
+		}
+
+		s.mu.RLock()
+		changed := !info.ModTime().Equal(s.modTime)
+		s.mu.RUnlock()
+
+		if changed {
+			if err = s.Reload(); err != nil {
+				s.logger.Error().Err(err).Msg("keeping previous pipeline")
+				s.mu.Lock()
+				// Do not retry until the file changes again.
+				s.modTime = info.ModTime()
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// GetStatus returns the loaded pipeline and its metrics.
+func (s *PipelineService) GetStatus() PipelineStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := PipelineStatus{
+		Path:     s.path,
+		LoadedAt: s.loadedAt,
+		Metrics:  s.pipeline.Metrics(),
+	}
+	if s.reloadErr != nil {
+		status.ReloadError = s.reloadErr.Error()
+	}
+	return status
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
+	"github.com/KirilStrezikozin/logcrunch/internal/types"
+	"github.com/rs/zerolog"
+)
+
+// ProfileSource selects logs to profile: those of a saved session,
+// or those of the current capture if Session is empty.
+// Either is narrowed down to Window.
+type ProfileSource struct {
+	Session string
+	Window  profiler.Window
+}
+
+type IProfilerService interface {
+	GetTimeline(window profiler.Window, traceID string) (profiler.Timeline, error)
+	GetCriticalPath(root internal.LogID) (profiler.CriticalPath, error)
+	GetHealthReport(window profiler.Window) (profiler.HealthReport, error)
+	GetProfileDiff(before, after ProfileSource) (profiler.ProfileDiff, error)
+
+	SaveSession(name string) error
+	ListSessions() ([]string, error)
+}
+
+type ProfilerService struct {
+	db     internal.DBReadWriter
+	store  *internal.Store
+	logger zerolog.Logger
+}
+
+func NewProfilerService(
+	db internal.DBReadWriter,
+	store *internal.Store,
+	parentLogger zerolog.Logger,
+) *ProfilerService {
+	logger := parentLogger.
+		With().
+		Str("service", "profiler").
+		Logger()
+
+	return &ProfilerService{
+		db:     db,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// GetTimeline returns the timeline of the current capture within window.
+// If traceID is not empty, only logs of that trace are included.
+func (s *ProfilerService) GetTimeline(window profiler.Window, traceID string) (profiler.Timeline, error) {
+	if traceID == "" {
+		return profiler.BuildTimeline(s.store.GetAllLogs(), window), nil
+	}
+
+	timeline := profiler.BuildTimeline(s.store.GetTraceLogs(traceID), window)
+	timeline.TraceID = traceID
+	return timeline, nil
+}
+
+func (s *ProfilerService) GetCriticalPath(root internal.LogID) (profiler.CriticalPath, error) {
+	tree := profiler.BuildCallTree(s.store.GetAllLogs())
+	path, ok := tree.CriticalPath(root)
+	if !ok {
+		return path, internal.ErrCallNotFound
+	}
+	return path, nil
+}
+
+func (s *ProfilerService) GetHealthReport(window profiler.Window) (profiler.HealthReport, error) {
+	return profiler.CheckHealth(s.store.GetAllLogs(), window), nil
+}
+
+func (s *ProfilerService) GetProfileDiff(before, after ProfileSource) (profiler.ProfileDiff, error) {
+	beforeLogs, err := s.getLogs(before)
+	if err != nil {
+		return profiler.ProfileDiff{}, err
+	}
+
+	afterLogs, err := s.getLogs(after)
+	if err != nil {
+		return profiler.ProfileDiff{}, err
+	}
+
+	return profiler.DiffProfiles(beforeLogs, afterLogs), nil
+}
+
+func (s *ProfilerService) getLogs(source ProfileSource) ([]internal.Log, error) {
+	if source.Session == "" {
+		return profiler.FilterLogs(s.store.GetAllLogs(), source.Window), nil
+	}
+
+	var logs []internal.Log
+	err := s.db.Get(types.GetProfilerSessionsBucketName(), []byte(source.Session), func(value []byte) error {
+		if value == nil {
+			return internal.ErrSessionNotFound
+		}
+		return json.Unmarshal(value, &logs)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session %q from db: %w", source.Session, err)
+	}
+
+	return profiler.FilterLogs(logs, source.Window), nil
+}
+
+// SaveSession persists logs of the current capture under name,
+// replacing a session with the same name.
+func (s *ProfilerService) SaveSession(name string) error {
+	logs := s.store.GetAllLogs()
+
+	data, err := json.Marshal(logs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session %q: %w", name, err)
+	}
+
+	err = s.db.Put(types.GetProfilerSessionsBucketName(), []byte(name), data)
+	if err != nil {
+		return fmt.Errorf("failed to put session %q to db: %w", name, err)
+	}
+
+	s.logger.Info().Str("session", name).Int("logs", len(logs)).Msg("session saved")
+	return nil
+}
+
+func (s *ProfilerService) ListSessions() ([]string, error) {
+	var names []string
+	err := s.db.ForEach(types.GetProfilerSessionsBucketName(), func(key, _ []byte) error {
+		names = append(names, string(key))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions in db: %w", err)
+	}
+	return names, nil
+}
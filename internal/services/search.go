@@ -0,0 +1,150 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"slices"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
+	"github.com/KirilStrezikozin/logcrunch/internal/search"
+	"github.com/rs/zerolog"
+)
+
+// SearchArchiveFlushInterval is how often logs evicted from the store
+// are written to on-disk search segments.
+const SearchArchiveFlushInterval = time.Second
+
+type ISearchService interface {
+	Search(query string, window profiler.Window, limit int) []internal.Log
+}
+
+type SearchService struct {
+	store *internal.Store
+	index *search.Index
+	// Archive of logs evicted from the store, nil if disabled.
+	archive *search.Archive
+	logger  zerolog.Logger
+}
+
+// NewSearchService returns a service searching logs of store with
+// a full-text index kept in step with it. Logs evicted from the store
+// are searched in archive, which may be nil.
+func NewSearchService(
+	store *internal.Store,
+	archive *search.Archive,
+	parentLogger zerolog.Logger,
+) *SearchService {
+	logger := parentLogger.
+		With().
+		Str("service", "search").
+		Logger()
+
+	index := search.NewIndex()
+	store.AddIndex(index)
+	if archive != nil {
+		store.AddIndex(archive)
+	}
+
+	return &SearchService{
+		store:   store,
+		index:   index,
+		archive: archive,
+		logger:  logger,
+	}
+}
+
+// Run writes logs evicted from the store to on-disk search segments
+// until stop is closed, then writes the logs left over.
+func (s *SearchService) Run(stop <-chan struct{}) {
+	if s.archive == nil {
+		return
+	}
+
+	ticker := time.NewTicker(SearchArchiveFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			if err := s.archive.Flush(true); err != nil {
+				s.logger.Error().Err(err).Msg("archive flush")
+			}
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.archive.Flush(false); err != nil {
+			s.logger.Error().Err(err).Msg("archive flush")
+		}
+	}
+}
+
+// Search returns at most limit logs within window matching the
+// full-text query, newest first. See [search.ParseQuery] for the query
+// syntax. Logs evicted from the store follow those in it, if archived.
+func (s *SearchService) Search(query string, window profiler.Window, limit int) []internal.Log {
+	q := search.ParseQuery(query)
+	if q.Empty() {
+		var res []internal.Log
+		if window == (profiler.Window{}) {
+			res = s.store.GetLatestLogs(limit)
+		} else {
+			res = s.store.GetLatestLogsWithin(window.From, window.To, limit)
+		}
+		return s.searchArchive(q, window, limit, res)
+	}
+
+	positions, exact := s.index.Search(q)
+	res := make([]internal.Log, 0, min(limit, len(positions)))
+
+	// Phrases are matched against logs, fetched in batches
+	// until enough of them match.
+	for end := len(positions); end > 0 && len(res) < limit; {
+		start := max(end-limit, 0)
+		batch := slices.Clone(positions[start:end])
+		slices.Reverse(batch)
+		end = start
+
+		for _, log := range s.store.GetLogsAt(batch) {
+			if !window.Contains(log.Timestamp, log.Timestamp) {
+				continue
+			}
+			if exact || q.Match(&log) {
+				res = append(res, log)
+				if len(res) == limit {
+					break
+				}
+			}
+		}
+	}
+
+	s.logger.Debug().
+		Str("query", query).
+		Int("candidates", len(positions)).
+		Int("results", len(res)).
+		Msg("search")
+	return s.searchArchive(q, window, limit, res)
+}
+
+// searchArchive appends archived logs within window matching q
+// to res, the logs found in the store, up to limit logs in total.
+func (s *SearchService) searchArchive(
+	q search.Query,
+	window profiler.Window,
+	limit int,
+	res []internal.Log,
+) []internal.Log {
+	if s.archive == nil || len(res) >= limit {
+		return res
+	}
+
+	archived, err := s.archive.Search(q, window.From, window.To, limit-len(res))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("archive search")
+	}
+	return append(res, archived...)
+}
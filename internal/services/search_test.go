@@ -0,0 +1,61 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
+	"github.com/KirilStrezikozin/logcrunch/internal/search"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestDB opens a database in a temporary file, closed with the test.
+func openTestDB(t *testing.T) *internal.BoltDB {
+	t.Helper()
+	db := internal.NewBoltDBAt(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+	return db
+}
+
+func TestSearchService_Archive(t *testing.T) {
+	archive, err := search.NewArchive(openTestDB(t), 1)
+	require.NoError(t, err)
+
+	store := internal.NewStore(2)
+	s := NewSearchService(store, archive, zerolog.Nop())
+
+	for i := range 5 {
+		store.AddLog(internal.Log{
+			ID:        internal.LogID{ProducerID: "app", SequenceNumber: i},
+			Timestamp: internal.Timestamp(i),
+			Message:   fmt.Sprintf("request %d", i),
+		})
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	s.Run(stop)
+	assert.Len(t, archive.Segments(), 1)
+
+	messages := func(query string, window profiler.Window, limit int) []string {
+		var res []string
+		for _, log := range s.Search(query, window, limit) {
+			res = append(res, log.Message)
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{"request 4", "request 3", "request 2", "request 1"}, messages("", profiler.Window{}, 4))
+	assert.Equal(t, []string{"request 4", "request 3", "request 2", "request 1", "request 0"}, messages("request", profiler.Window{}, 10))
+	assert.Equal(t, []string{"request 1"}, messages("1", profiler.Window{}, 10))
+	assert.Equal(t, []string{"request 3", "request 2"}, messages("request", profiler.Window{From: 2, To: 3}, 10))
+}
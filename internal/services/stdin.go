@@ -0,0 +1,80 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/multiline"
+	"github.com/KirilStrezikozin/logcrunch/internal/tail"
+	"github.com/rs/zerolog"
+)
+
+// StdinProducerID is the name of standard input as a source, and the
+// producer of its logs when the decoder does not read log ids.
+const StdinProducerID = "stdin"
+
+type IStdinService interface {
+	Read(r io.Reader) error
+}
+
+type StdinService struct {
+	decode   tail.Decoder
+	pipeline IPipelineService
+	lines    *multiline.Aggregator
+	logger   zerolog.Logger
+}
+
+// NewStdinService returns a service reading logs piped to standard input,
+// decoding lines with the named decoder. Lines are stitched into logs
+// by multilineConfig, if not nil.
+func NewStdinService(
+	pipeline IPipelineService,
+	ids *internal.SequenceGenerator,
+	decoderName string,
+	multilineConfig *multiline.Config,
+	parentLogger zerolog.Logger,
+) (*StdinService, error) {
+	logger := parentLogger.
+		With().
+		Str("service", "stdin").
+		Logger()
+
+	decode, err := tail.NewDecoder(decoderName, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin decoder: %w", err)
+	}
+
+	s := &StdinService{
+		decode:   decode,
+		pipeline: pipeline,
+		logger:   logger,
+	}
+	if multilineConfig != nil {
+		s.lines = multiline.New(*multilineConfig, s.ingest)
+	}
+	return s, nil
+}
+
+// Read adds logs decoded from lines of r to the store until r ends.
+func (s *StdinService) Read(r io.Reader) error {
+	handle := func(_ string, log internal.Log) { s.ingest(log) }
+	if s.lines != nil {
+		handle = s.lines.Add
+		defer s.lines.Flush()
+	}
+
+	return tail.ReadLines(r, StdinProducerID, s.decode, handle,
+		func(err error) {
+			s.logger.Error().Err(err).Msg("unparsable log data, skipping")
+		},
+	)
+}
+
+func (s *StdinService) ingest(log internal.Log) {
+	s.pipeline.Ingest(SourceStdin, log)
+}
@@ -0,0 +1,194 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/syslog"
+	"github.com/rs/zerolog"
+)
+
+type ISyslogService interface {
+	ListenUDP(addr string) error
+	ListenTCP(addr string) error
+	AddMessage(data []byte) error
+	Close() error
+}
+
+type SyslogService struct {
+	pipeline IPipelineService
+	ids      *internal.SequenceGenerator
+	logger   zerolog.Logger
+
+	mu sync.Mutex
+	// Listeners and connections to close on Close.
+	closers map[io.Closer]struct{}
+	closed  bool
+}
+
+func NewSyslogService(
+	pipeline IPipelineService,
+	ids *internal.SequenceGenerator,
+	parentLogger zerolog.Logger,
+) *SyslogService {
+	logger := parentLogger.
+		With().
+		Str("service", "syslog").
+		Logger()
+
+	return &SyslogService{
+		pipeline: pipeline,
+		ids:      ids,
+		logger:   logger,
+		closers:  make(map[io.Closer]struct{}),
+	}
+}
+
+// track registers c to be closed on Close. It returns false,
+// closing c, if the service is already closed.
+func (s *SyslogService) track(c io.Closer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		_ = c.Close()
+		return false
+	}
+	s.closers[c] = struct{}{}
+	return true
+}
+
+func (s *SyslogService) untrack(c io.Closer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.closers, c)
+}
+
+func (s *SyslogService) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// ListenUDP receives messages, one per datagram, on addr
+// until the service is closed.
+func (s *SyslogService) ListenUDP(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on udp %s: %w", addr, err)
+	}
+	if !s.track(conn) {
+		return nil
+	}
+	defer s.untrack(conn)
+
+	s.logger.Info().Str("addr", conn.LocalAddr().String()).Msg("listening for syslog over udp")
+
+	buf := make([]byte, syslog.MaxMessageSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return fmt.Errorf("failed to read from udp %s: %w", addr, err)
+		}
+
+		if err := s.AddMessage(buf[:n]); err != nil {
+			s.logger.Error().Err(err).Bytes("data", buf[:n]).Msg("unparsable syslog message, skipping")
+		}
+	}
+}
+
+// ListenTCP accepts connections on addr until the service is closed,
+// and receives messages framed by octet counting or line feeds.
+func (s *SyslogService) ListenTCP(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on tcp %s: %w", addr, err)
+	}
+	if !s.track(listener) {
+		return nil
+	}
+	defer s.untrack(listener)
+
+	s.logger.Info().Str("addr", listener.Addr().String()).Msg("listening for syslog over tcp")
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return fmt.Errorf("failed to accept on tcp %s: %w", addr, err)
+		}
+
+		if !s.track(conn) {
+			return nil
+		}
+		go s.readConn(conn)
+	}
+}
+
+func (s *SyslogService) readConn(conn net.Conn) {
+	defer s.untrack(conn)
+	defer conn.Close()
+
+	frames := syslog.NewFrameReader(conn)
+	for {
+		data, err := frames.Next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !s.isClosed() {
+				s.logger.Error().Err(err).Str("remote", conn.RemoteAddr().String()).Msg("syslog connection")
+			}
+			return
+		}
+
+		if err := s.AddMessage(data); err != nil {
+			s.logger.Error().Err(err).Bytes("data", data).Msg("unparsable syslog message, skipping")
+		}
+	}
+}
+
+// AddMessage parses a single syslog message and adds it to the store.
+func (s *SyslogService) AddMessage(data []byte) error {
+	now := time.Now()
+	msg, err := syslog.Parse(data, now)
+	if err != nil {
+		return err
+	}
+
+	log := msg.Log(s.ids.Next(msg.ProducerID()), now)
+
+	s.logger.Debug().
+		Str("producer_id", log.ID.ProducerID).
+		Int("sequence_number", log.ID.SequenceNumber).
+		Msg("syslog message received")
+
+	s.pipeline.Ingest(SourceSyslog, log)
+	return nil
+}
+
+// Close stops all listeners and closes their connections.
+func (s *SyslogService) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	var errs []error
+	for c := range s.closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	clear(s.closers)
+	return errors.Join(errs...)
+}
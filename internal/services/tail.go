@@ -0,0 +1,86 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/multiline"
+	"github.com/KirilStrezikozin/logcrunch/internal/tail"
+	"github.com/rs/zerolog"
+)
+
+type ITailService interface {
+	Run(stop <-chan struct{})
+}
+
+type TailService struct {
+	tailer *tail.Tailer
+	lines  *multiline.Aggregator
+	logger zerolog.Logger
+}
+
+// NewTailService returns a service following files matching patterns,
+// decoding their lines with the named decoder. Lines are stitched into
+// logs by multilineConfig, if not nil.
+func NewTailService(
+	db internal.DBReadWriter,
+	pipeline IPipelineService,
+	ids *internal.SequenceGenerator,
+	patterns []string,
+	decoderName string,
+	multilineConfig *multiline.Config,
+	parentLogger zerolog.Logger,
+) (*TailService, error) {
+	logger := parentLogger.
+		With().
+		Str("service", "tail").
+		Logger()
+
+	decode, err := tail.NewDecoder(decoderName, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tail decoder: %w", err)
+	}
+
+	ingest := func(log internal.Log) {
+		logger.Debug().
+			Str("producer_id", log.ID.ProducerID).
+			Int("sequence_number", log.ID.SequenceNumber).
+			Msg("log read")
+		pipeline.Ingest(SourceTail, log)
+	}
+	handle := func(_ string, log internal.Log) { ingest(log) }
+
+	var lines *multiline.Aggregator
+	if multilineConfig != nil {
+		lines = multiline.New(*multilineConfig, ingest)
+		handle = lines.Add
+	}
+
+	tailer, err := tail.New(patterns, decode, db, handle,
+		func(err error) {
+			logger.Error().Err(err).Msg("tail")
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tailer: %w", err)
+	}
+
+	return &TailService{
+		tailer: tailer,
+		lines:  lines,
+		logger: logger,
+	}, nil
+}
+
+// Run follows files until stop is closed.
+func (s *TailService) Run(stop <-chan struct{}) {
+	s.logger.Info().Msg("following files")
+	s.tailer.Run(stop)
+	if s.lines != nil {
+		s.lines.Flush()
+	}
+}
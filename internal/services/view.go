@@ -0,0 +1,116 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/types"
+	"github.com/rs/zerolog"
+)
+
+type IViewService interface {
+	SaveView(view internal.SavedView) (internal.SavedView, error)
+	GetView(name string) (internal.SavedView, error)
+	ListViews() ([]internal.SavedView, error)
+	DeleteView(name string) error
+}
+
+type ViewService struct {
+	db     internal.DBReadWriter
+	logger zerolog.Logger
+}
+
+// NewViewService returns a service persisting saved views in db.
+func NewViewService(
+	db internal.DBReadWriter,
+	parentLogger zerolog.Logger,
+) *ViewService {
+	logger := parentLogger.
+		With().
+		Str("service", "view").
+		Logger()
+
+	return &ViewService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveView persists view under its name, replacing a view with the same
+// name, and returns the saved view.
+func (s *ViewService) SaveView(view internal.SavedView) (internal.SavedView, error) {
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" || utf8.RuneCountInString(view.Name) > internal.MaxViewNameLength {
+		return view, fmt.Errorf("%w: %q", internal.ErrInvalidViewName, view.Name)
+	}
+	view.SavedAt = time.Now()
+
+	data, err := json.Marshal(view)
+	if err != nil {
+		return view, fmt.Errorf("failed to marshal view %q: %w", view.Name, err)
+	}
+
+	err = s.db.Put(types.GetSavedViewsBucketName(), []byte(view.Name), data)
+	if err != nil {
+		return view, fmt.Errorf("failed to put view %q to db: %w", view.Name, err)
+	}
+
+	s.logger.Info().Str("view", view.Name).Msg("view saved")
+	return view, nil
+}
+
+// GetView returns the view saved under name.
+func (s *ViewService) GetView(name string) (internal.SavedView, error) {
+	var view internal.SavedView
+	err := s.db.Get(types.GetSavedViewsBucketName(), []byte(name), func(value []byte) error {
+		if value == nil {
+			return internal.ErrViewNotFound
+		}
+		return json.Unmarshal(value, &view)
+	})
+	if err != nil {
+		return view, fmt.Errorf("failed to get view %q from db: %w", name, err)
+	}
+	return view, nil
+}
+
+// ListViews returns saved views, the most recently saved first.
+func (s *ViewService) ListViews() ([]internal.SavedView, error) {
+	var views []internal.SavedView
+	err := s.db.ForEach(types.GetSavedViewsBucketName(), func(_, value []byte) error {
+		var view internal.SavedView
+		if err := json.Unmarshal(value, &view); err != nil {
+			return err
+		}
+		views = append(views, view)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views in db: %w", err)
+	}
+
+	slices.SortFunc(views, func(a, b internal.SavedView) int {
+		return cmp.Compare(b.SavedAt.UnixNano(), a.SavedAt.UnixNano())
+	})
+	return views, nil
+}
+
+// DeleteView deletes the view saved under name, if any.
+func (s *ViewService) DeleteView(name string) error {
+	if err := s.db.Delete(types.GetSavedViewsBucketName(), []byte(name)); err != nil {
+		return fmt.Errorf("failed to delete view %q from db: %w", name, err)
+	}
+
+	s.logger.Info().Str("view", name).Msg("view deleted")
+	return nil
+}
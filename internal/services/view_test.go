@@ -0,0 +1,84 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewService(t *testing.T) {
+	s := NewViewService(openTestDB(t), zerolog.Nop())
+
+	views, err := s.ListViews()
+	require.NoError(t, err)
+	assert.Empty(t, views)
+
+	_, err = s.GetView("errors")
+	assert.ErrorIs(t, err, internal.ErrViewNotFound)
+
+	saved, err := s.SaveView(internal.SavedView{
+		Name:    "  errors ",
+		Mode:    types.ViewModeLogList,
+		Query:   "level:error",
+		Columns: []internal.Column{{Path: "message", Width: 300}},
+		From:    10,
+		To:      20,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "errors", saved.Name)
+	assert.False(t, saved.SavedAt.IsZero())
+
+	view, err := s.GetView("errors")
+	require.NoError(t, err)
+	assert.True(t, saved.SavedAt.Equal(view.SavedAt))
+	view.SavedAt = saved.SavedAt
+	assert.Equal(t, saved, view)
+
+	time.Sleep(time.Millisecond)
+	_, err = s.SaveView(internal.SavedView{Name: "traces", Mode: types.ViewModeProfiler})
+	require.NoError(t, err)
+
+	views, err = s.ListViews()
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, "traces", views[0].Name)
+	assert.Equal(t, "errors", views[1].Name)
+
+	// Saving under a taken name replaces the view.
+	_, err = s.SaveView(internal.SavedView{Name: "errors", Query: "level:warn"})
+	require.NoError(t, err)
+	views, err = s.ListViews()
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, "level:warn", views[0].Query)
+
+	require.NoError(t, s.DeleteView("errors"))
+	require.NoError(t, s.DeleteView("missing"))
+	_, err = s.GetView("errors")
+	assert.ErrorIs(t, err, internal.ErrViewNotFound)
+	views, err = s.ListViews()
+	require.NoError(t, err)
+	assert.Len(t, views, 1)
+}
+
+func TestViewService_InvalidName(t *testing.T) {
+	s := NewViewService(openTestDB(t), zerolog.Nop())
+
+	for _, name := range []string{"", "   ", strings.Repeat("a", internal.MaxViewNameLength+1)} {
+		_, err := s.SaveView(internal.SavedView{Name: name})
+		assert.ErrorIs(t, err, internal.ErrInvalidViewName, name)
+	}
+
+	_, err := s.SaveView(internal.SavedView{Name: strings.Repeat("é", internal.MaxViewNameLength)})
+	assert.NoError(t, err)
+}
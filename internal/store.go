@@ -4,7 +4,23 @@
 
 package internal
 
-import "sync"
+import (
+	"iter"
+	"slices"
+	"sync"
+)
+
+// DefaultStoreCapacity is the default number of logs kept in memory.
+const DefaultStoreCapacity = 100_000
+
+// LogIndex is an index of logs kept in step with the [Store] it is added
+// to. Logs are identified by their position, which grows by one with every
+// log added and is never reused. Methods are called with the store locked,
+// logs are removed oldest first.
+type LogIndex interface {
+	Add(pos int, log *Log)
+	Remove(pos int, log *Log)
+}
 
 // There is a number of logs that we store in memory, and the rest is stored in a db.
 type Store struct {
@@ -17,26 +33,219 @@ type Store struct {
 	logs            []Log
 	lastReadOffset  int
 	lastSavedOffset int
+
+	// Maximum number of logs kept, the oldest are evicted.
+	capacity int
+	// Number of logs evicted, the position of logs[0].
+	evicted int
+
+	// Index of log id to its position.
+	ids map[LogID]int
+	// Index of trace id to positions of its logs.
+	traces map[string][]int
+
+	indexes []LogIndex
 }
 
+// NewStore returns a store keeping at most capacity logs in memory.
 func NewStore(capacity int) *Store {
 	return &Store{
-		logs:            make([]Log, 0, capacity),
+		logs:            make([]Log, 0, min(capacity, 1<<12)),
 		lastReadOffset:  -1,
 		lastSavedOffset: -1,
+		capacity:        capacity,
+		ids:             make(map[LogID]int),
+		traces:          make(map[string][]int),
 	}
 }
 
 func (s *Store) AddLog(log Log) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.index(&log, s.evicted+len(s.logs))
 	s.logs = append(s.logs, log)
+	s.evict()
 }
 
 func (s *Store) AddLogs(logs []Log) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	for i := range logs {
+		s.index(&logs[i], s.evicted+len(s.logs)+i)
+	}
 	s.logs = append(s.logs, logs...)
+	s.evict()
+}
+
+// AddIndex adds idx to be kept in step with the store,
+// adding logs already in the store to it.
+func (s *Store) AddIndex(idx LogIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.logs {
+		idx.Add(s.evicted+i, &s.logs[i])
+	}
+	s.indexes = append(s.indexes, idx)
+}
+
+// index adds log at position pos to the indexes. Must be called with s.mu held.
+func (s *Store) index(log *Log, pos int) {
+	s.ids[log.ID] = pos
+	if log.TraceID != "" {
+		s.traces[log.TraceID] = append(s.traces[log.TraceID], pos)
+	}
+	for _, idx := range s.indexes {
+		idx.Add(pos, log)
+	}
+}
+
+// evict removes the oldest logs over capacity from the store
+// and the indexes. Must be called with s.mu held.
+func (s *Store) evict() {
+	n := len(s.logs) - s.capacity
+	if s.capacity <= 0 || n <= 0 {
+		return
+	}
+
+	for i := range n {
+		log, pos := &s.logs[i], s.evicted+i
+
+		// A later log with the same id replaces it in the index.
+		if s.ids[log.ID] == pos {
+			delete(s.ids, log.ID)
+		}
+		if log.TraceID != "" {
+			if positions := s.traces[log.TraceID][1:]; len(positions) > 0 {
+				s.traces[log.TraceID] = positions
+			} else {
+				delete(s.traces, log.TraceID)
+			}
+		}
+		for _, idx := range s.indexes {
+			idx.Remove(pos, log)
+		}
+	}
+
+	// Slices returned by GetLogs may still refer to evicted logs, they are
+	// let go of when the slice grows again.
+	s.logs = s.logs[n:]
+	s.evicted += n
+	s.lastReadOffset = max(s.lastReadOffset-n, -1)
+	s.lastSavedOffset = max(s.lastSavedOffset-n, -1)
+}
+
+// Len returns the number of logs in the store.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.logs)
+}
+
+// Evicted returns the number of logs evicted from the store.
+func (s *Store) Evicted() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.evicted
+}
+
+func (s *Store) GetLog(id LogID) (Log, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pos, ok := s.ids[id]
+	if !ok {
+		return Log{}, false
+	}
+	return s.logs[pos-s.evicted], true
+}
+
+// GetLogsAt returns logs at the given positions, in the same order.
+// Positions of logs evicted since are skipped.
+func (s *Store) GetLogsAt(positions []int) []Log {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]Log, 0, len(positions))
+	for _, pos := range positions {
+		if i := pos - s.evicted; i >= 0 && i < len(s.logs) {
+			res = append(res, s.logs[i])
+		}
+	}
+	return res
+}
+
+// GetTraceLogs returns logs of the given trace, oldest first.
+func (s *Store) GetTraceLogs(traceID string) []Log {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	positions := s.traces[traceID]
+	res := make([]Log, 0, len(positions))
+	for _, pos := range positions {
+		res = append(res, s.logs[pos-s.evicted])
+	}
+	return res
+}
+
+// GetAllLogs returns a copy of all logs in the store, oldest first.
+func (s *Store) GetAllLogs() []Log {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.logs)
+}
+
+// within reports whether ts is within from, to. A zero bound leaves
+// that side open.
+func within(ts, from, to Timestamp) bool {
+	return (from == 0 || ts >= from) && (to == 0 || ts <= to)
+}
+
+// GetLatestLogsWithin returns at most limit of the latest logs timestamped
+// within from, to, newest first. A zero bound leaves that side open.
+func (s *Store) GetLatestLogsWithin(from, to Timestamp, limit int) []Log {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]Log, 0, min(limit, len(s.logs)))
+	for i := len(s.logs) - 1; i >= 0 && len(res) < limit; i-- {
+		if within(s.logs[i].Timestamp, from, to) {
+			res = append(res, s.logs[i])
+		}
+	}
+	return res
+}
+
+// LogsWithin returns an iterator over copies of logs timestamped within
+// from, to, oldest first. A zero bound leaves that side open. The store
+// is read locked while iterating, so logs must not be added to it within
+// the loop.
+func (s *Store) LogsWithin(from, to Timestamp) iter.Seq[*Log] {
+	return func(yield func(*Log) bool) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		for i := range s.logs {
+			if !within(s.logs[i].Timestamp, from, to) {
+				continue
+			}
+			log := s.logs[i]
+			if !yield(&log) {
+				return
+			}
+		}
+	}
+}
+
+// GetLatestLogs returns at most limit of the latest logs, newest first.
+func (s *Store) GetLatestLogs(limit int) []Log {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]Log, 0, min(limit, len(s.logs)))
+	for i := len(s.logs) - 1; i >= 0 && len(res) < limit; i-- {
+		res = append(res, s.logs[i])
+	}
+	return res
 }
 
 func (s *Store) GetLogs(offset int, limit int) []Log {
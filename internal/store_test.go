@@ -5,14 +5,13 @@
 package internal
 
 import (
-	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func newLog(id int) Log {
-	return Log{ID: LogID(strconv.Itoa(id))}
+	return Log{ID: LogID{ProducerID: "test", SequenceNumber: id}}
 }
 
 func newStore(initialCount int) *Store {
@@ -76,3 +75,135 @@ func TestStore_GetUnreadLogs(t *testing.T) {
 		assert.Equal(t, []Log{newLog(5)}, s.GetUnreadLogs(10))
 	})
 }
+
+func TestStore_GetLog(t *testing.T) {
+	s := newStore(3)
+	s.AddLogs([]Log{newLog(3), newLog(4)})
+
+	log, ok := s.GetLog(newLog(4).ID)
+	assert.True(t, ok)
+	assert.Equal(t, newLog(4), log)
+
+	_, ok = s.GetLog(newLog(5).ID)
+	assert.False(t, ok)
+}
+
+func TestStore_GetAllLogs(t *testing.T) {
+	s := newStore(2)
+	logs := s.GetAllLogs()
+	assert.Equal(t, []Log{newLog(0), newLog(1)}, logs)
+
+	logs[0] = newLog(9)
+	assert.Equal(t, []Log{newLog(0), newLog(1)}, s.GetAllLogs())
+}
+
+func TestStore_GetTraceLogs(t *testing.T) {
+	s := newStore(0)
+	traced := func(seq int, traceID string) Log {
+		log := newLog(seq)
+		log.TraceID = traceID
+		return log
+	}
+
+	s.AddLog(traced(1, "a"))
+	s.AddLogs([]Log{traced(2, "b"), traced(3, "a"), newLog(4)})
+
+	assert.Equal(t, []Log{traced(1, "a"), traced(3, "a")}, s.GetTraceLogs("a"))
+	assert.Equal(t, []Log{traced(2, "b")}, s.GetTraceLogs("b"))
+	assert.Empty(t, s.GetTraceLogs("c"))
+}
+
+// recordingIndex records positions added to and removed from it.
+type recordingIndex struct {
+	added, removed []int
+}
+
+func (idx *recordingIndex) Add(pos int, _ *Log)    { idx.added = append(idx.added, pos) }
+func (idx *recordingIndex) Remove(pos int, _ *Log) { idx.removed = append(idx.removed, pos) }
+
+func TestStore_Evict(t *testing.T) {
+	s := NewStore(3)
+	s.AddLog(newLog(0))
+	s.AddLog(newLog(1))
+
+	idx := &recordingIndex{}
+	s.AddIndex(idx)
+	assert.Equal(t, []int{0, 1}, idx.added)
+
+	traced := newLog(2)
+	traced.TraceID = "a"
+	s.AddLogs([]Log{traced, newLog(3), newLog(4)})
+
+	assert.Equal(t, []Log{traced, newLog(3), newLog(4)}, s.GetAllLogs())
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, idx.added)
+	assert.Equal(t, []int{0, 1}, idx.removed)
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, 2, s.Evicted())
+
+	_, ok := s.GetLog(newLog(0).ID)
+	assert.False(t, ok)
+	log, ok := s.GetLog(newLog(3).ID)
+	assert.True(t, ok)
+	assert.Equal(t, newLog(3), log)
+
+	assert.Equal(t, []Log{newLog(4), traced}, s.GetLogsAt([]int{0, 4, 2, 7}))
+
+	s.AddLogs([]Log{newLog(5), newLog(6)})
+	assert.Empty(t, s.GetTraceLogs("a"))
+	assert.Empty(t, s.traces)
+	assert.Equal(t, []Log{newLog(4), newLog(5), newLog(6)}, s.GetUnreadLogs(10))
+}
+
+func TestStore_GetLatestLogs(t *testing.T) {
+	s := newStore(3)
+	assert.Equal(t, []Log{newLog(2), newLog(1)}, s.GetLatestLogs(2))
+	assert.Len(t, s.GetLatestLogs(10), 3)
+	assert.Empty(t, s.GetLatestLogs(0))
+}
+
+func TestStore_GetLatestLogsWithin(t *testing.T) {
+	s := NewStore(10)
+	for i, ts := range []Timestamp{5, 1, 3, 7, 4} {
+		log := newLog(i)
+		log.Timestamp = ts
+		s.AddLog(log)
+	}
+
+	seqs := func(logs []Log) []int {
+		var res []int
+		for _, log := range logs {
+			res = append(res, log.ID.SequenceNumber)
+		}
+		return res
+	}
+
+	assert.Equal(t, []int{4, 2, 0}, seqs(s.GetLatestLogsWithin(3, 5, 10)))
+	assert.Equal(t, []int{4, 2}, seqs(s.GetLatestLogsWithin(3, 5, 2)))
+	assert.Equal(t, []int{4, 3, 2, 1, 0}, seqs(s.GetLatestLogsWithin(0, 0, 10)))
+	assert.Empty(t, s.GetLatestLogsWithin(8, 0, 10))
+}
+
+func TestStore_LogsWithin(t *testing.T) {
+	s := NewStore(10)
+	for i, ts := range []Timestamp{5, 1, 3, 7, 4} {
+		log := newLog(i)
+		log.Timestamp = ts
+		s.AddLog(log)
+	}
+
+	var within []int
+	for log := range s.LogsWithin(4, 0) {
+		within = append(within, log.ID.SequenceNumber)
+		log.Message = "changed"
+	}
+	assert.Equal(t, []int{0, 3, 4}, within)
+	// Logs are copies.
+	assert.Empty(t, s.GetAllLogs()[0].Message)
+
+	var first []int
+	for log := range s.LogsWithin(0, 4) {
+		first = append(first, log.ID.SequenceNumber)
+		break
+	}
+	assert.Equal(t, []int{1}, first)
+}
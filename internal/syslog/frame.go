@@ -0,0 +1,81 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// FrameReader reads messages from a syslog stream, such as a TCP
+// connection. Both framing methods of RFC 6587 are supported,
+// detected per message: octet counting, where a message is prefixed
+// with its length, and non-transparent framing, where messages are
+// terminated by a line feed.
+type FrameReader struct {
+	r *bufio.Reader
+}
+
+func NewFrameReader(r io.Reader) *FrameReader {
+	return &FrameReader{r: bufio.NewReaderSize(r, MaxMessageSize)}
+}
+
+// Next returns the next message. The error wraps [io.EOF]
+// when the stream ends at a message boundary.
+func (f *FrameReader) Next() ([]byte, error) {
+	// Skip empty lines, including trailers some senders append
+	// to octet counted messages.
+	first, err := f.r.Peek(1)
+	for err == nil && (first[0] == '\n' || first[0] == '\r') {
+		_, _ = f.r.Discard(1)
+		first, err = f.r.Peek(1)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	if first[0] >= '1' && first[0] <= '9' {
+		return f.nextOctetCounted()
+	}
+	return f.nextLine()
+}
+
+func (f *FrameReader) nextOctetCounted() ([]byte, error) {
+	length, err := f.r.ReadSlice(' ')
+	if err != nil {
+		return nil, fmt.Errorf("%w: unterminated message length", internal.ErrMalformedSyslog)
+	}
+
+	n, err := strconv.Atoi(string(length[:len(length)-1]))
+	if err != nil || n > MaxMessageSize {
+		return nil, fmt.Errorf("%w: invalid message length %q", internal.ErrMalformedSyslog, length)
+	}
+
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(f.r, msg); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return msg, nil
+}
+
+func (f *FrameReader) nextLine() ([]byte, error) {
+	line, err := f.r.ReadSlice('\n')
+	switch {
+	case errors.Is(err, bufio.ErrBufferFull):
+		return nil, fmt.Errorf("%w: message exceeds %d bytes", internal.ErrMalformedSyslog, MaxMessageSize)
+	case errors.Is(err, io.EOF) && len(line) > 0:
+		// Last message of the stream without a trailer.
+	case err != nil:
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	return bytes.Clone(bytes.TrimRight(line, "\r\n")), nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package syslog
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFrames(t *testing.T, stream string) ([]string, error) {
+	t.Helper()
+
+	f := NewFrameReader(strings.NewReader(stream))
+	var frames []string
+	for {
+		frame, err := f.Next()
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, string(frame))
+	}
+}
+
+func TestFrameReader(t *testing.T) {
+	frames, err := readFrames(t, "9 <13>1 a\nb7 <13>1 c\n\n<13>line\r\n<13>last")
+	assert.ErrorIs(t, err, io.EOF)
+	require.Equal(t, []string{"<13>1 a\nb", "<13>1 c", "<13>line", "<13>last"}, frames)
+}
+
+func TestFrameReader_Malformed(t *testing.T) {
+	_, err := readFrames(t, "99999999 <13>")
+	assert.ErrorIs(t, err, internal.ErrMalformedSyslog)
+
+	_, err = readFrames(t, "20 <13>short")
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,323 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package syslog parses syslog messages in the RFC 5424 and
+// RFC 3164 (BSD) formats, framed as in RFC 6587, into logs.
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+const (
+	// MaxMessageSize is the maximum size of a message.
+	MaxMessageSize = 64 << 10 // 64KB
+
+	// DefaultProducerID is the producer of messages
+	// with no hostname, app name and process id.
+	DefaultProducerID = "syslog"
+
+	nilValue = "-"
+)
+
+// Message is a parsed syslog message.
+type Message struct {
+	Facility int
+	Severity int
+
+	// Timestamp of the message, zero if not known.
+	Timestamp time.Time
+
+	Hostname string
+	AppName  string
+	ProcID   string
+	MsgID    string
+
+	// Structured data parameters by SD-ID. RFC 5424 only.
+	StructuredData map[string]map[string]string
+
+	Message string
+}
+
+// Parse parses a syslog message, detecting its format.
+// now is used to complete RFC 3164 timestamps, which have no year.
+func Parse(b []byte, now time.Time) (Message, error) {
+	var m Message
+
+	b = bytes.TrimRight(b, "\r\n\x00")
+	rest, err := m.parsePriority(b)
+	if err != nil {
+		return m, err
+	}
+
+	// RFC 5424 messages have a version after the priority.
+	if version, rest5424 := field(rest); version == "1" && rest5424 != nil {
+		return m, m.parseRFC5424(rest5424)
+	}
+
+	m.parseRFC3164(rest, now)
+	return m, nil
+}
+
+func (m *Message) parsePriority(b []byte) ([]byte, error) {
+	end := bytes.IndexByte(b, '>')
+	if len(b) == 0 || b[0] != '<' || end < 2 || end > 4 {
+		return nil, fmt.Errorf("%w: missing priority", internal.ErrMalformedSyslog)
+	}
+
+	pri, err := strconv.Atoi(string(b[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return nil, fmt.Errorf("%w: invalid priority %q", internal.ErrMalformedSyslog, b[1:end])
+	}
+
+	m.Facility, m.Severity = pri/8, pri%8
+	return b[end+1:], nil
+}
+
+// field returns the next space separated field of b and the rest of b.
+func field(b []byte) (string, []byte) {
+	i := bytes.IndexByte(b, ' ')
+	if i < 0 {
+		return string(b), nil
+	}
+	return string(b[:i]), b[i+1:]
+}
+
+func optional(s string) string {
+	if s == nilValue {
+		return ""
+	}
+	return s
+}
+
+func (m *Message) parseRFC5424(b []byte) error {
+	var ts string
+	ts, b = field(b)
+	if ts != nilValue {
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return fmt.Errorf("%w: invalid timestamp %q", internal.ErrMalformedSyslog, ts)
+		}
+		m.Timestamp = t
+	}
+
+	var hostname, appName, procID, msgID string
+	hostname, b = field(b)
+	appName, b = field(b)
+	procID, b = field(b)
+	msgID, b = field(b)
+	m.Hostname, m.AppName, m.ProcID, m.MsgID = optional(hostname), optional(appName), optional(procID), optional(msgID)
+
+	b, err := m.parseStructuredData(b)
+	if err != nil {
+		return err
+	}
+
+	b = bytes.TrimPrefix(b, []byte(" "))
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+	m.Message = string(b)
+	return nil
+}
+
+func (m *Message) parseStructuredData(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("%w: missing structured data", internal.ErrMalformedSyslog)
+	}
+	if b[0] == '-' {
+		return b[1:], nil
+	}
+
+	for len(b) > 0 && b[0] == '[' {
+		end := bytes.IndexAny(b, " ]")
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated structured data", internal.ErrMalformedSyslog)
+		}
+
+		id := string(b[1:end])
+		params := make(map[string]string)
+		b = b[end:]
+
+		for len(b) > 0 && b[0] == ' ' {
+			eq := bytes.IndexByte(b, '=')
+			if eq < 0 || eq+1 >= len(b) || b[eq+1] != '"' {
+				return nil, fmt.Errorf("%w: invalid structured data parameter", internal.ErrMalformedSyslog)
+			}
+			name := string(b[1:eq])
+
+			value, n, ok := unquote(b[eq+2:])
+			if !ok {
+				return nil, fmt.Errorf("%w: unterminated structured data value", internal.ErrMalformedSyslog)
+			}
+			params[name] = value
+			b = b[eq+2+n:]
+		}
+
+		if len(b) == 0 || b[0] != ']' {
+			return nil, fmt.Errorf("%w: unterminated structured data", internal.ErrMalformedSyslog)
+		}
+		b = b[1:]
+
+		if m.StructuredData == nil {
+			m.StructuredData = make(map[string]map[string]string)
+		}
+		m.StructuredData[id] = params
+	}
+
+	return b, nil
+}
+
+// unquote reads a structured data parameter value up to the closing quote,
+// returning the value and the number of bytes consumed including the quote.
+func unquote(b []byte) (string, int, bool) {
+	var sb strings.Builder
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '"':
+			return sb.String(), i + 1, true
+		case '\\':
+			// Only '"', '\' and ']' are escaped, keep other backslashes.
+			if i+1 < len(b) && (b[i+1] == '"' || b[i+1] == '\\' || b[i+1] == ']') {
+				i++
+			}
+		}
+		sb.WriteByte(b[i])
+	}
+	return "", 0, false
+}
+
+// rfc3164TimestampLayout is the timestamp of BSD syslog messages,
+// with a space padded day.
+const rfc3164TimestampLayout = "Jan _2 15:04:05"
+
+// parseRFC3164 parses the rest of a BSD syslog message after the priority.
+// The format is loosely followed by senders, any part of it may be
+// missing, in which case it becomes part of the message.
+func (m *Message) parseRFC3164(b []byte, now time.Time) {
+	if len(b) >= len(rfc3164TimestampLayout)+1 && b[len(rfc3164TimestampLayout)] == ' ' {
+		t, err := time.ParseInLocation(rfc3164TimestampLayout, string(b[:len(rfc3164TimestampLayout)]), now.Location())
+		if err == nil {
+			m.Timestamp = withYear(t, now)
+			b = b[len(rfc3164TimestampLayout)+1:]
+
+			// Hostname follows the timestamp, unless the sender omitted it
+			// and the next field is already the tag.
+			if host, rest := field(b); rest != nil && !isTag(host) {
+				m.Hostname = host
+				b = rest
+			}
+		}
+	}
+
+	if tag, rest := field(b); rest != nil && isTag(tag) {
+		tag = strings.TrimSuffix(tag, ":")
+		if i := strings.IndexByte(tag, '['); i >= 0 && strings.HasSuffix(tag, "]") {
+			m.ProcID = tag[i+1 : len(tag)-1]
+			tag = tag[:i]
+		}
+		m.AppName = tag
+		b = rest
+	}
+
+	m.Message = string(b)
+}
+
+func isTag(s string) bool {
+	return strings.HasSuffix(s, ":")
+}
+
+// withYear sets the year of t, a timestamp without one, so that it is
+// closest to now. Messages from up to a day ahead are accepted as is
+// to tolerate clock skew, later ones are from the previous year.
+func withYear(t, now time.Time) time.Time {
+	t = t.AddDate(now.Year()-t.Year(), 0, 0)
+	if t.After(now.AddDate(0, 0, 1)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t
+}
+
+// ProducerID returns the hostname, app name and process id
+// of the message joined by slashes, skipping unknown ones.
+func (m *Message) ProducerID() string {
+	var parts []string
+	for _, p := range []string{m.Hostname, m.AppName, m.ProcID} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return DefaultProducerID
+	}
+	return strings.Join(parts, "/")
+}
+
+// Level returns the log level of the message severity.
+func (m *Message) Level() string {
+	switch m.Severity {
+	case 0, 1, 2: // Emergency, alert, critical.
+		return "fatal"
+	case 3:
+		return "error"
+	case 4:
+		return "warn"
+	case 5, 6: // Notice, informational.
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// Log converts the message to a log with the given id. The time the
+// message was received is used when the message has no timestamp.
+// Header fields are kept in the "syslog" attribute and structured data
+// elements under "sd" by their ids, so that no element can replace the
+// header fields.
+func (m *Message) Log(id internal.LogID, received time.Time) internal.Log {
+	ts := m.Timestamp
+	if ts.IsZero() {
+		ts = received
+	}
+
+	meta := map[string]any{
+		"facility": float64(m.Facility),
+		"severity": float64(m.Severity),
+	}
+	for key, value := range map[string]string{
+		"hostname": m.Hostname,
+		"app_name": m.AppName,
+		"procid":   m.ProcID,
+		"msgid":    m.MsgID,
+	} {
+		if value != "" {
+			meta[key] = value
+		}
+	}
+
+	attrs := map[string]any{"syslog": meta}
+	if len(m.StructuredData) > 0 {
+		elements := make(map[string]any, len(m.StructuredData))
+		for id, params := range m.StructuredData {
+			element := make(map[string]any, len(params))
+			for name, value := range params {
+				element[name] = value
+			}
+			elements[id] = element
+		}
+		attrs["sd"] = elements
+	}
+
+	return internal.Log{
+		ID:        id,
+		Timestamp: internal.NewTimestamp(ts),
+		Level:     m.Level(),
+		Message:   m.Message,
+		Attrs:     attrs,
+	}
+}
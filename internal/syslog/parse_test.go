@@ -0,0 +1,136 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func TestParse_RFC5424(t *testing.T) {
+	m, err := Parse([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 `+
+		`[exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"]`+
+		" \xef\xbb\xbfAn application event log entry...\n"), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, m.Facility)
+	assert.Equal(t, 5, m.Severity)
+	assert.Equal(t, time.Date(2003, time.October, 11, 22, 14, 15, 3e6, time.UTC), m.Timestamp)
+	assert.Equal(t, "mymachine.example.com", m.Hostname)
+	assert.Equal(t, "evntslog", m.AppName)
+	assert.Empty(t, m.ProcID)
+	assert.Equal(t, "ID47", m.MsgID)
+	assert.Equal(t, map[string]map[string]string{
+		"exampleSDID@32473":     {"iut": "3", "eventSource": "Application", "eventID": "1011"},
+		"examplePriority@32473": {"class": "high"},
+	}, m.StructuredData)
+	assert.Equal(t, "An application event log entry...", m.Message)
+	assert.Equal(t, "mymachine.example.com/evntslog", m.ProducerID())
+}
+
+func TestParse_RFC5424_Minimal(t *testing.T) {
+	m, err := Parse([]byte(`<14>1 - - - - - [id a="q\"u\]o\\te" b="\x"]`), now)
+	require.NoError(t, err)
+
+	assert.True(t, m.Timestamp.IsZero())
+	assert.Equal(t, DefaultProducerID, m.ProducerID())
+	assert.Equal(t, map[string]string{"a": `q"u]o\te`, "b": `\x`}, m.StructuredData["id"])
+	assert.Empty(t, m.Message)
+}
+
+func TestParse_RFC3164(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Message
+	}{
+		{
+			name: "full",
+			data: "<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed",
+			want: Message{
+				Facility:  4,
+				Severity:  2,
+				Timestamp: time.Date(2024, time.October, 11, 22, 14, 15, 0, time.UTC),
+				Hostname:  "mymachine",
+				AppName:   "su",
+				ProcID:    "123",
+				Message:   "'su root' failed",
+			},
+		},
+		{
+			name: "no hostname",
+			data: "<13>Feb  5 17:32:18 cron: job done",
+			want: Message{
+				Facility:  1,
+				Severity:  5,
+				Timestamp: time.Date(2025, time.February, 5, 17, 32, 18, 0, time.UTC),
+				AppName:   "cron",
+				Message:   "job done",
+			},
+		},
+		{
+			name: "no header",
+			data: "<13>just a message",
+			want: Message{Facility: 1, Severity: 5, Message: "just a message"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse([]byte(tt.data), now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m)
+		})
+	}
+}
+
+func TestParse_Malformed(t *testing.T) {
+	for _, data := range []string{
+		"no priority",
+		"<192>1 - - - - - -",
+		"<13>1 yesterday - - - - -",
+		`<13>1 - - - - - [id a="open`,
+		"<13>1 - - - - -",
+	} {
+		_, err := Parse([]byte(data), now)
+		assert.ErrorIs(t, err, internal.ErrMalformedSyslog, data)
+	}
+}
+
+func TestMessage_Log(t *testing.T) {
+	m, err := Parse([]byte(`<11>1 - host app 42 - [meta x="1"][syslog app_name="sd"] disk full`), now)
+	require.NoError(t, err)
+
+	id := internal.LogID{ProducerID: m.ProducerID(), SequenceNumber: 1}
+	log := m.Log(id, now)
+
+	assert.Equal(t, "host/app/42", log.ID.ProducerID)
+	assert.Equal(t, internal.Timestamp(now.Unix()), log.Timestamp)
+	assert.Equal(t, "error", log.Level)
+	assert.Equal(t, "disk full", log.Message)
+
+	v, ok := log.Attr("attrs.sd.meta.x")
+	assert.True(t, ok)
+	assert.Equal(t, "1", v)
+
+	// An element with the id syslog does not replace the header fields.
+	v, ok = log.Attr("attrs.syslog.app_name")
+	assert.True(t, ok)
+	assert.Equal(t, "app", v)
+
+	v, ok = log.Attr("attrs.sd.syslog.app_name")
+	assert.True(t, ok)
+	assert.Equal(t, "sd", v)
+
+	m, err = Parse([]byte(`<11>1 - host app 42 - - disk full`), now)
+	require.NoError(t, err)
+	assert.NotContains(t, m.Log(id, now).Attrs, "sd")
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package tail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// MaxPartialSize is the maximum size of a log reassembled from partial
+// container log lines. Longer logs are split.
+const MaxPartialSize = 1 << 20 // 1MB
+
+type partialKey struct {
+	name   string
+	stream string
+}
+
+type partial struct {
+	buf []byte
+	// Time of the first part.
+	ts time.Time
+}
+
+// reassembler joins partial lines of container logs,
+// per source and output stream.
+type reassembler map[partialKey]*partial
+
+// add adds a part of a log. When last is set, or the log grows too long,
+// it returns the complete log and the time of its first part.
+func (r reassembler) add(key partialKey, part []byte, ts time.Time, last bool) ([]byte, time.Time, bool) {
+	p, ok := r[key]
+	if !ok {
+		if last {
+			return part, ts, true
+		}
+		p = &partial{ts: ts}
+		r[key] = p
+	}
+
+	p.buf = append(p.buf, part...)
+	if !last && len(p.buf) < MaxPartialSize {
+		return nil, time.Time{}, false
+	}
+
+	delete(r, key)
+	return p.buf, p.ts, true
+}
+
+// envelopeLog returns the log carried by a container log line.
+// Payloads that are not JSON encoded logs become info logs.
+// Logs missing a time take it from the envelope, the output stream
+// is kept in the stream attribute.
+func envelopeLog(ids *internal.SequenceGenerator, name string, payload []byte, ts time.Time, stream string) internal.Log {
+	payload = bytes.TrimRight(payload, "\r")
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	var log internal.Log
+	isLog := false
+	if bytes.HasPrefix(bytes.TrimSpace(payload), []byte("{")) {
+		var err error
+		log, err = internal.NewLog(payload)
+		isLog = err == nil && log.ID.ProducerID != ""
+	}
+
+	if !isLog {
+		log = textLog(ids, name, payload, ts)
+	} else if log.Timestamp == 0 {
+		log.Timestamp = internal.NewTimestamp(ts)
+	}
+
+	if _, ok := log.Attrs["stream"]; !ok && stream != "" {
+		log.SetAttr("stream", stream)
+	}
+	return log
+}
+
+// dockerEntry is a line of the Docker json-file logging driver.
+type dockerEntry struct {
+	Log    string    `json:"log"`
+	Stream string    `json:"stream"`
+	Time   time.Time `json:"time"`
+}
+
+// DockerDecoder returns a decoder of files written by the Docker json-file
+// logging driver, as shown by docker logs. Long lines split by Docker
+// are reassembled. The returned decoder is not safe for concurrent use.
+func DockerDecoder(ids *internal.SequenceGenerator) Decoder {
+	partials := make(reassembler)
+	return func(name string, line []byte) (internal.Log, bool, error) {
+		var e dockerEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return internal.Log{}, false, fmt.Errorf("%w: %w", internal.ErrMalformedContainerLog, err)
+		}
+
+		// Only the last part of a line ends with a line feed.
+		last := strings.HasSuffix(e.Log, "\n")
+		payload, ts, ok := partials.add(partialKey{name, e.Stream}, []byte(strings.TrimSuffix(e.Log, "\n")), e.Time, last)
+		if !ok {
+			return internal.Log{}, false, nil
+		}
+		return envelopeLog(ids, name, payload, ts, e.Stream), true, nil
+	}
+}
+
+// CRIDecoder returns a decoder of container log files written by kubelet
+// for CRI runtimes, in the "<time> <stream> <tag> <message>" format.
+// Partial lines, tagged P, are reassembled up to the full line tagged F.
+// The returned decoder is not safe for concurrent use.
+func CRIDecoder(ids *internal.SequenceGenerator) Decoder {
+	partials := make(reassembler)
+	return func(name string, line []byte) (internal.Log, bool, error) {
+		fields := bytes.SplitN(line, []byte(" "), 4)
+		if len(fields) < 3 {
+			return internal.Log{}, false, fmt.Errorf("%w: missing fields in %q", internal.ErrMalformedContainerLog, line)
+		}
+
+		ts, err := time.Parse(time.RFC3339Nano, string(fields[0]))
+		if err != nil {
+			return internal.Log{}, false, fmt.Errorf("%w: %w", internal.ErrMalformedContainerLog, err)
+		}
+		stream := string(fields[1])
+
+		// Tags are separated by colons, the first tells whether the line is partial.
+		tag, _, _ := bytes.Cut(fields[2], []byte(":"))
+		last := !bytes.Equal(tag, []byte("P"))
+
+		var msg []byte
+		if len(fields) == 4 {
+			msg = fields[3]
+		}
+
+		payload, ts, ok := partials.add(partialKey{name, stream}, msg, ts, last)
+		if !ok {
+			return internal.Log{}, false, nil
+		}
+		return envelopeLog(ids, name, payload, ts, stream), true, nil
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package tail
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeAll decodes lines of the named source, returning complete logs.
+func decodeAll(t *testing.T, decode Decoder, name string, lines ...string) []internal.Log {
+	t.Helper()
+
+	var logs []internal.Log
+	for _, line := range lines {
+		log, ok, err := decode(name, []byte(line))
+		require.NoError(t, err, line)
+		if ok {
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+func TestDockerDecoder(t *testing.T) {
+	decode := DockerDecoder(internal.NewSequenceGenerator())
+
+	logs := decodeAll(t, decode, "/var/lib/docker/containers/abc/abc-json.log",
+		`{"log":"first ","stream":"stdout","time":"2025-03-01T12:00:00.5Z"}`,
+		`{"log":"oops\n","stream":"stderr","time":"2025-03-01T12:00:01Z"}`,
+		`{"log":"part\r\n","stream":"stdout","time":"2025-03-01T12:00:02Z"}`,
+	)
+	require.Len(t, logs, 2)
+
+	assert.Equal(t, "oops", logs[0].Message)
+	assert.Equal(t, "abc-json.log", logs[0].ID.ProducerID)
+	assert.Equal(t, "stderr", logs[0].Attrs["stream"])
+
+	// Reassembled with the time of the first part.
+	assert.Equal(t, "first part", logs[1].Message)
+	assert.Equal(t, internal.Timestamp(1740830400.5), logs[1].Timestamp)
+	v, ok := logs[1].Attr("attrs.stream")
+	assert.True(t, ok)
+	assert.Equal(t, "stdout", v)
+}
+
+func TestDockerDecoder_Malformed(t *testing.T) {
+	decode := DockerDecoder(internal.NewSequenceGenerator())
+	_, _, err := decode("app", []byte("not json"))
+	assert.ErrorIs(t, err, internal.ErrMalformedContainerLog)
+}
+
+func TestCRIDecoder(t *testing.T) {
+	decode := CRIDecoder(internal.NewSequenceGenerator())
+
+	logs := decodeAll(t, decode, "/var/log/pods/ns_app/app/0.log",
+		"2025-03-01T12:00:00.000000001Z stdout P long ",
+		"2025-03-01T12:00:01Z stderr F failed",
+		"2025-03-01T12:00:02Z stdout P:more line",
+		"2025-03-01T12:00:03Z stdout F",
+	)
+	require.Len(t, logs, 2)
+
+	assert.Equal(t, "failed", logs[0].Message)
+	assert.Equal(t, "stderr", logs[0].Attrs["stream"])
+
+	assert.Equal(t, "long line", logs[1].Message)
+	assert.Equal(t, internal.LogID{ProducerID: "0.log", SequenceNumber: 2}, logs[1].ID)
+	assert.Equal(t, internal.NewTimestamp(time.Date(2025, time.March, 1, 12, 0, 0, 1, time.UTC)), logs[1].Timestamp)
+}
+
+func TestCRIDecoder_JSONPayload(t *testing.T) {
+	decode := CRIDecoder(internal.NewSequenceGenerator())
+
+	parseErrors := internal.ParseErrors()
+	logs := decodeAll(t, decode, "0.log",
+		`2025-03-01T12:00:00Z stdout F {"id":{"producer_id":"api","sequence_number":7},"level":"warn","message":"slow","attrs":{"stream":"custom"}}`,
+		`2025-03-01T12:00:00Z stdout F {"message":"no id"}`,
+		`2025-03-01T12:00:00Z stdout F {"level":30,"msg":"own json"}`,
+	)
+	require.Len(t, logs, 3)
+	// Payloads kept as text are not lost.
+	assert.Equal(t, parseErrors, internal.ParseErrors())
+
+	assert.Equal(t, internal.LogID{ProducerID: "api", SequenceNumber: 7}, logs[0].ID)
+	assert.Equal(t, "warn", logs[0].Level)
+	assert.Equal(t, "slow", logs[0].Message)
+	assert.Equal(t, internal.NewTimestamp(time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)), logs[0].Timestamp)
+	assert.Equal(t, "custom", logs[0].Attrs["stream"])
+
+	// Not a log, kept as text.
+	assert.Equal(t, "0.log", logs[1].ID.ProducerID)
+	assert.Equal(t, `{"message":"no id"}`, logs[1].Message)
+	assert.Equal(t, `{"level":30,"msg":"own json"}`, logs[2].Message)
+}
+
+func TestJSONDecoder_ParseErrors(t *testing.T) {
+	parseErrors := internal.ParseErrors()
+	_, ok, err := JSONDecoder("app", []byte(`{"level":30}`))
+	assert.Error(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, parseErrors+1, internal.ParseErrors())
+}
+
+func TestCRIDecoder_Malformed(t *testing.T) {
+	decode := CRIDecoder(internal.NewSequenceGenerator())
+	for _, line := range []string{"2025-03-01T12:00:00Z stdout", "yesterday stdout F msg"} {
+		_, _, err := decode("0.log", []byte(line))
+		assert.ErrorIs(t, err, internal.ErrMalformedContainerLog, line)
+	}
+}
+
+func TestReassembler_MaxPartialSize(t *testing.T) {
+	r := make(reassembler)
+	key := partialKey{"app", "stdout"}
+	part := make([]byte, MaxPartialSize/2)
+
+	_, _, ok := r.add(key, part, time.Time{}, false)
+	assert.False(t, ok)
+	msg, _, ok := r.add(key, part, time.Time{}, false)
+	assert.True(t, ok)
+	assert.Len(t, msg, MaxPartialSize)
+	assert.Empty(t, r)
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package tail
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+)
+
+// Decoder converts a line read from the named source, such as a file
+// path, to a log. It returns false if the line does not complete a log,
+// as when a log is split across lines.
+type Decoder func(name string, line []byte) (log internal.Log, ok bool, err error)
+
+const (
+	DecoderJSON   = "json"
+	DecoderText   = "text"
+	DecoderDocker = "docker"
+	DecoderCRI    = "cri"
+)
+
+// NewDecoder returns the decoder with the given name.
+// ids is used by decoders of lines that do not carry log ids.
+func NewDecoder(name string, ids *internal.SequenceGenerator) (Decoder, error) {
+	switch name {
+	case DecoderJSON:
+		return JSONDecoder, nil
+	case DecoderText:
+		return TextDecoder(ids), nil
+	case DecoderDocker:
+		return DockerDecoder(ids), nil
+	case DecoderCRI:
+		return CRIDecoder(ids), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", internal.ErrUnknownDecoder, name)
+	}
+}
+
+// JSONDecoder decodes lines as JSON encoded logs.
+// Lines failing to decode are counted as parse errors.
+func JSONDecoder(_ string, line []byte) (internal.Log, bool, error) {
+	log, err := internal.NewLog(line)
+	if err != nil {
+		internal.CountParseError()
+	}
+	return log, err == nil, err
+}
+
+// TextDecoder returns a decoder making an info log of every line.
+// Logs are produced by the base name of their source and stamped
+// with the time they are read.
+func TextDecoder(ids *internal.SequenceGenerator) Decoder {
+	return func(name string, line []byte) (internal.Log, bool, error) {
+		return textLog(ids, name, line, time.Now()), true, nil
+	}
+}
+
+func textLog(ids *internal.SequenceGenerator, name string, line []byte, ts time.Time) internal.Log {
+	return internal.Log{
+		ID:        ids.Next(filepath.Base(name)),
+		Timestamp: internal.NewTimestamp(ts),
+		Level:     "info",
+		Message:   string(line),
+	}
+}
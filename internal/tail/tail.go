@@ -0,0 +1,395 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package tail follows log files like tail -F, decoding their lines
+// into logs. Read offsets are checkpointed in the database so that
+// following resumes where it left off across restarts.
+package tail
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/types"
+)
+
+const (
+	DefaultPollInterval       = 250 * time.Millisecond
+	DefaultCheckpointInterval = time.Second
+
+	// MaxLineSize is the maximum size of a line, longer lines are split.
+	MaxLineSize = 64 << 10 // 64KB
+
+	// Size of the file prefix identifying a file across renames and restarts.
+	fingerprintSize = 256
+)
+
+// checkpoint is the persisted read state of a file.
+type checkpoint struct {
+	// Offset after the last line read.
+	Offset int64 `json:"offset"`
+
+	// Hash of the first FingerprintSize bytes of the file. It tells
+	// whether the file at the path is still the one the offset is of.
+	Fingerprint     string `json:"fingerprint"`
+	FingerprintSize int64  `json:"fingerprint_size"`
+}
+
+// file is a followed file.
+type file struct {
+	path string
+	f    *os.File
+	info os.FileInfo
+	r    *bufio.Reader
+
+	cp checkpoint
+	// Last saved checkpoint and when it was saved.
+	saved   checkpoint
+	savedAt time.Time
+}
+
+// Tailer follows files matching glob patterns.
+//
+// Files are polled for new lines. A file renamed to a path that is
+// no longer matched, or removed, is read to its end and let go of.
+// A file truncated in place is read again from its start.
+// Files seen for the first time are read from their start.
+// The last line of a file is only read once it is terminated
+// by a line feed, or when the file is let go of.
+type Tailer struct {
+	patterns []string
+	decode   Decoder
+	db       internal.DBReadWriter
+
+	handle  func(source string, log internal.Log)
+	onError func(err error)
+
+	files map[string]*file
+
+	PollInterval       time.Duration
+	CheckpointInterval time.Duration
+}
+
+// New returns a tailer of files matching patterns, passing logs decoded
+// from their lines to handle along with the path of their file. Errors
+// reading or decoding a file do not stop the tailer and are passed to
+// onError.
+func New(
+	patterns []string,
+	decode Decoder,
+	db internal.DBReadWriter,
+	handle func(source string, log internal.Log),
+	onError func(err error),
+) (*Tailer, error) {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	return &Tailer{
+		patterns: patterns,
+		decode:   decode,
+		db:       db,
+		handle:   handle,
+		onError:  onError,
+		files:    make(map[string]*file),
+
+		PollInterval:       DefaultPollInterval,
+		CheckpointInterval: DefaultCheckpointInterval,
+	}, nil
+}
+
+// Run follows files until stop is closed.
+func (t *Tailer) Run(stop <-chan struct{}) {
+	defer t.close()
+
+	ticker := time.NewTicker(t.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		t.poll()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// glob returns regular files matching the patterns.
+func (t *Tailer) glob() map[string]os.FileInfo {
+	res := make(map[string]os.FileInfo)
+	for _, pattern := range t.patterns {
+		// Patterns are validated in New.
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err == nil && info.Mode().IsRegular() {
+				res[path] = info
+			}
+		}
+	}
+	return res
+}
+
+func (t *Tailer) poll() {
+	matched := t.glob()
+
+	for _, path := range slices.Sorted(maps.Keys(t.files)) {
+		tf := t.files[path]
+		if info, ok := matched[path]; ok && os.SameFile(info, tf.info) {
+			continue
+		}
+
+		// Read what was written before the file was renamed or removed.
+		t.read(tf, false)
+
+		// Keep following a file renamed to another matched path,
+		// as in rotation to a numbered file.
+		moved := ""
+		for p, info := range matched {
+			if _, ok := t.files[p]; !ok && os.SameFile(info, tf.info) {
+				moved = p
+				break
+			}
+		}
+
+		delete(t.files, path)
+		t.deleteCheckpoint(path)
+
+		if moved != "" {
+			tf.path = moved
+			t.files[moved] = tf
+			t.saveCheckpoint(tf, true)
+			continue
+		}
+
+		t.read(tf, true)
+		_ = tf.f.Close()
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(matched)) {
+		if _, ok := t.files[path]; ok {
+			continue
+		}
+
+		tf, err := t.open(path)
+		if err != nil {
+			t.onError(err)
+			continue
+		}
+		t.files[path] = tf
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(t.files)) {
+		tf := t.files[path]
+		t.read(tf, false)
+		t.saveCheckpoint(tf, false)
+	}
+}
+
+// open opens the file at path, resuming from its checkpoint
+// if it is of the same file.
+func (t *Tailer) open(path string) (*file, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	tf := &file{path: path, f: f, info: info, r: bufio.NewReaderSize(nil, MaxLineSize)}
+
+	cp, ok, err := t.loadCheckpoint(path)
+	if err != nil {
+		t.onError(err)
+	}
+	if ok && cp.Offset <= info.Size() && cp.FingerprintSize <= info.Size() {
+		fp, err := fingerprint(f, cp.FingerprintSize)
+		if err == nil && fp == cp.Fingerprint {
+			tf.cp = cp
+			tf.saved = cp
+		}
+	}
+
+	return tf, nil
+}
+
+// read reads and decodes new complete lines of tf.
+// If final, an unterminated last line is read as well.
+func (t *Tailer) read(tf *file, final bool) {
+	info, err := tf.f.Stat()
+	if err != nil {
+		t.onError(fmt.Errorf("failed to stat %s: %w", tf.path, err))
+		return
+	}
+
+	size := info.Size()
+	if size < tf.cp.Offset {
+		// Truncated in place, the fingerprint is of the old content.
+		tf.cp = checkpoint{}
+	}
+	if size == tf.cp.Offset {
+		return
+	}
+
+	tf.r.Reset(io.NewSectionReader(tf.f, tf.cp.Offset, size-tf.cp.Offset))
+	for {
+		line, err := tf.r.ReadSlice('\n')
+		switch {
+		case err == nil, errors.Is(err, bufio.ErrBufferFull):
+		case errors.Is(err, io.EOF):
+			if final && len(line) > 0 {
+				tf.cp.Offset += int64(len(line))
+				t.emit(tf, line)
+			}
+			return
+		default:
+			t.onError(fmt.Errorf("failed to read %s: %w", tf.path, err))
+			return
+		}
+
+		tf.cp.Offset += int64(len(line))
+		t.emit(tf, line)
+	}
+}
+
+func (t *Tailer) emit(tf *file, line []byte) {
+	decodeLine(t.decode, tf.path, line, t.handle, t.onError)
+}
+
+// decodeLine decodes a line of the named source, skipping blank lines.
+func decodeLine(decode Decoder, name string, line []byte, handle func(string, internal.Log), onError func(error)) {
+	line = bytes.TrimRight(line, "\r\n")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+
+	log, ok, err := decode(name, line)
+	if err != nil {
+		onError(fmt.Errorf("failed to decode line of %s: %w", name, err))
+		return
+	}
+	if ok {
+		handle(name, log)
+	}
+}
+
+// ReadLines decodes lines of r, a stream such as standard input, until
+// its end. Logs are passed to handle along with name, decoding errors
+// to onError.
+func ReadLines(
+	r io.Reader,
+	name string,
+	decode Decoder,
+	handle func(source string, log internal.Log),
+	onError func(err error),
+) error {
+	br := bufio.NewReaderSize(r, MaxLineSize)
+	for {
+		line, err := br.ReadSlice('\n')
+		switch {
+		case err == nil, errors.Is(err, bufio.ErrBufferFull):
+		case errors.Is(err, io.EOF):
+			decodeLine(decode, name, line, handle, onError)
+			return nil
+		default:
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		decodeLine(decode, name, line, handle, onError)
+	}
+}
+
+// fingerprint returns the hash of the first n bytes of f.
+func fingerprint(f *os.File, n int64) (string, error) {
+	buf := make([]byte, n)
+	if _, err := f.ReadAt(buf, 0); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read %s: %w", f.Name(), err)
+	}
+
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+func (t *Tailer) loadCheckpoint(path string) (checkpoint, bool, error) {
+	var cp checkpoint
+	var ok bool
+	err := t.db.Get(types.GetTailCheckpointsBucketName(), []byte(path), func(value []byte) error {
+		if value == nil {
+			return nil
+		}
+		ok = true
+		return json.Unmarshal(value, &cp)
+	})
+	if err != nil {
+		return cp, false, fmt.Errorf("failed to get checkpoint of %s from db: %w", path, err)
+	}
+	return cp, ok, nil
+}
+
+// saveCheckpoint saves the checkpoint of tf if it changed, at most
+// once per CheckpointInterval unless force is set.
+func (t *Tailer) saveCheckpoint(tf *file, force bool) {
+	if tf.cp.FingerprintSize < fingerprintSize {
+		// The file was shorter than the fingerprint when last computed.
+		n := min(tf.cp.Offset, fingerprintSize)
+		fp, err := fingerprint(tf.f, n)
+		if err != nil {
+			t.onError(err)
+			return
+		}
+		tf.cp.Fingerprint, tf.cp.FingerprintSize = fp, n
+	}
+
+	if tf.cp == tf.saved || (!force && time.Since(tf.savedAt) < t.CheckpointInterval) {
+		return
+	}
+
+	data, err := json.Marshal(tf.cp)
+	if err != nil {
+		t.onError(fmt.Errorf("failed to marshal checkpoint of %s: %w", tf.path, err))
+		return
+	}
+
+	err = t.db.Put(types.GetTailCheckpointsBucketName(), []byte(tf.path), data)
+	if err != nil {
+		t.onError(fmt.Errorf("failed to put checkpoint of %s to db: %w", tf.path, err))
+		return
+	}
+	tf.saved, tf.savedAt = tf.cp, time.Now()
+}
+
+func (t *Tailer) deleteCheckpoint(path string) {
+	if err := t.db.Delete(types.GetTailCheckpointsBucketName(), []byte(path)); err != nil {
+		t.onError(fmt.Errorf("failed to delete checkpoint of %s from db: %w", path, err))
+	}
+}
+
+// close saves checkpoints and closes followed files.
+func (t *Tailer) close() {
+	for _, tf := range t.files {
+		t.saveCheckpoint(tf, true)
+		_ = tf.f.Close()
+	}
+	clear(t.files)
+}
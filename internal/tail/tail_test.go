@@ -0,0 +1,213 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package tail
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memDB is an in-memory [internal.DBReadWriter].
+type memDB map[string]map[string][]byte
+
+func (db memDB) Get(bucketName, key []byte, fn func([]byte) error) error {
+	return fn(db[string(bucketName)][string(key)])
+}
+
+func (db memDB) ForEach(bucketName []byte, fn func(key, value []byte) error) error {
+	for k, v := range db[string(bucketName)] {
+		if err := fn([]byte(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db memDB) Put(bucketName, key, value []byte) error {
+	if db[string(bucketName)] == nil {
+		db[string(bucketName)] = make(map[string][]byte)
+	}
+	db[string(bucketName)][string(key)] = value
+	return nil
+}
+
+func (db memDB) Delete(bucketName, key []byte) error {
+	delete(db[string(bucketName)], string(key))
+	return nil
+}
+
+type tailerTest struct {
+	t     *testing.T
+	dir   string
+	db    memDB
+	lines []string
+}
+
+func newTailerTest(t *testing.T) *tailerTest {
+	t.Helper()
+	return &tailerTest{t: t, dir: t.TempDir(), db: make(memDB)}
+}
+
+func (tt *tailerTest) tailer() *Tailer {
+	tt.t.Helper()
+
+	decode := func(_ string, line []byte) (internal.Log, bool, error) {
+		return internal.Log{Message: string(line)}, true, nil
+	}
+	tailer, err := New([]string{filepath.Join(tt.dir, "*.log*")}, decode, tt.db,
+		func(_ string, log internal.Log) { tt.lines = append(tt.lines, log.Message) },
+		func(err error) { tt.t.Error(err) },
+	)
+	require.NoError(tt.t, err)
+	tailer.CheckpointInterval = 0
+	return tailer
+}
+
+func (tt *tailerTest) path(name string) string {
+	return filepath.Join(tt.dir, name)
+}
+
+func (tt *tailerTest) write(name, data string) {
+	tt.t.Helper()
+	f, err := os.OpenFile(tt.path(name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(tt.t, err)
+	_, err = f.WriteString(data)
+	require.NoError(tt.t, err)
+	require.NoError(tt.t, f.Close())
+}
+
+// take returns lines read since the last call.
+func (tt *tailerTest) take() []string {
+	lines := tt.lines
+	tt.lines = nil
+	return lines
+}
+
+func TestTailer_Follow(t *testing.T) {
+	tt := newTailerTest(t)
+	tailer := tt.tailer()
+	defer tailer.close()
+
+	tt.write("app.log", "one\ntw")
+	tailer.poll()
+	assert.Equal(t, []string{"one"}, tt.take())
+
+	tt.write("app.log", "o\n\nthree\n")
+	tt.write("other.log", "x\n")
+	tailer.poll()
+	assert.Equal(t, []string{"two", "three", "x"}, tt.take())
+
+	tailer.poll()
+	assert.Empty(t, tt.take())
+}
+
+func TestTailer_RenameRotation(t *testing.T) {
+	tt := newTailerTest(t)
+	tailer := tt.tailer()
+	defer tailer.close()
+
+	tt.write("app.log", "one\n")
+	tailer.poll()
+	assert.Equal(t, []string{"one"}, tt.take())
+
+	// Written before rotation and not yet polled.
+	tt.write("app.log", "two\n")
+	require.NoError(t, os.Rename(tt.path("app.log"), tt.path("app.log.1")))
+	tt.write("app.log", "three\n")
+	tailer.poll()
+	assert.Equal(t, []string{"two", "three"}, tt.take())
+
+	// Renamed out of the matched paths, read to the unterminated end.
+	tt.write("app.log.1", "four")
+	require.NoError(t, os.Rename(tt.path("app.log.1"), tt.path("archived")))
+	tailer.poll()
+	assert.Equal(t, []string{"four"}, tt.take())
+}
+
+func TestTailer_Truncate(t *testing.T) {
+	tt := newTailerTest(t)
+	tailer := tt.tailer()
+	defer tailer.close()
+
+	tt.write("app.log", "one\ntwo\n")
+	tailer.poll()
+	assert.Equal(t, []string{"one", "two"}, tt.take())
+
+	require.NoError(t, os.Truncate(tt.path("app.log"), 0))
+	tt.write("app.log", "new\n")
+	tailer.poll()
+	assert.Equal(t, []string{"new"}, tt.take())
+}
+
+func TestTailer_Checkpoint(t *testing.T) {
+	tt := newTailerTest(t)
+
+	tailer := tt.tailer()
+	tt.write("app.log", "one\n")
+	tailer.poll()
+	tailer.close()
+	assert.Equal(t, []string{"one"}, tt.take())
+
+	// Resume after a restart.
+	tt.write("app.log", "two\n")
+	tailer = tt.tailer()
+	tailer.poll()
+	tailer.close()
+	assert.Equal(t, []string{"two"}, tt.take())
+
+	// A different file at the same path is read from its start.
+	require.NoError(t, os.Remove(tt.path("app.log")))
+	tt.write("app.log", "fresh start\n")
+	tailer = tt.tailer()
+	tailer.poll()
+	tailer.close()
+	assert.Equal(t, []string{"fresh start"}, tt.take())
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	_, err := New([]string{"["}, JSONDecoder, make(memDB), nil, nil)
+	assert.Error(t, err)
+}
+
+func TestNewDecoder(t *testing.T) {
+	ids := internal.NewSequenceGenerator()
+
+	decode, err := NewDecoder(DecoderText, ids)
+	require.NoError(t, err)
+	log, ok, err := decode("/var/log/app.log", []byte("hello"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, internal.LogID{ProducerID: "app.log", SequenceNumber: 1}, log.ID)
+	assert.Equal(t, "hello", log.Message)
+
+	_, err = NewDecoder("xml", ids)
+	assert.ErrorIs(t, err, internal.ErrUnknownDecoder)
+}
+
+func TestReadLines(t *testing.T) {
+	var logs []internal.Log
+	var errs []error
+	err := ReadLines(strings.NewReader("plain\n\n{\"message\": \"json\"}\r\nlast"), "stdin",
+		func(name string, line []byte) (internal.Log, bool, error) {
+			if line[0] == '{' {
+				return internal.Log{}, false, errors.New("not text")
+			}
+			return internal.Log{Message: name + ": " + string(line)}, true, nil
+		},
+		func(_ string, log internal.Log) { logs = append(logs, log) },
+		func(err error) { errs = append(errs, err) },
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []internal.Log{{Message: "stdin: plain"}, {Message: "stdin: last"}}, logs)
+	assert.Len(t, errs, 1)
+}
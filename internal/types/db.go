@@ -12,6 +12,16 @@ const (
 var (
 	connectionBucketName = []byte("connection")
 	connectionURLKey     = []byte("url")
+
+	profilerSessionsBucketName = []byte("profiler_sessions")
+
+	tailCheckpointsBucketName = []byte("tail_checkpoints")
+
+	searchSegmentsBucketName     = []byte("search_segments")
+	searchSegmentIndexBucketName = []byte("search_segment_index")
+	searchSegmentLogsBucketName  = []byte("search_segment_logs")
+
+	savedViewsBucketName = []byte("saved_views")
 )
 
 func GetConnectionBucketName() []byte {
@@ -21,3 +31,27 @@ func GetConnectionBucketName() []byte {
 func GetConnectionURLKey() []byte {
 	return connectionURLKey
 }
+
+func GetProfilerSessionsBucketName() []byte {
+	return profilerSessionsBucketName
+}
+
+func GetTailCheckpointsBucketName() []byte {
+	return tailCheckpointsBucketName
+}
+
+func GetSearchSegmentsBucketName() []byte {
+	return searchSegmentsBucketName
+}
+
+func GetSearchSegmentIndexBucketName() []byte {
+	return searchSegmentIndexBucketName
+}
+
+func GetSearchSegmentLogsBucketName() []byte {
+	return searchSegmentLogsBucketName
+}
+
+func GetSavedViewsBucketName() []byte {
+	return savedViewsBucketName
+}
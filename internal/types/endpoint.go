@@ -4,6 +4,12 @@
 
 package types
 
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
 const (
 	EndpontIndex   = "/"
 	EndpointStatic = "/static/*"
@@ -12,4 +18,94 @@ const (
 	EndpointPostConnectionURL = "/api/v1/connection/url"
 
 	EndpointGetConnectionStatus = "/api/v1/connection/status"
+
+	EndpointGetLog       = "/api/v1/logs/{producer_id}/{sequence_number}"
+	EndpointPostLogs     = "/api/v1/logs"
+	EndpointGetLogSearch = "/api/v1/logs/search"
+
+	EndpointGetFields = "/api/v1/fields"
+
+	EndpointGetAggregate = "/api/v1/aggregate"
+	EndpointGetHistogram = "/api/v1/histogram"
+
+	EndpointGetPatterns    = "/api/v1/patterns"
+	EndpointGetPatternLogs = "/api/v1/patterns/{id}/logs"
+
+	EndpointGetAlerts    = "/api/v1/alerts"
+	EndpointGetAnomalies = "/api/v1/anomalies"
+
+	EndpointGetMetrics = "/metrics"
+
+	EndpointGetLogListView    = "/api/v1/views/logs"
+	EndpointGetLogSearchView  = "/api/v1/views/logs/search"
+	EndpointGetLogColumnsView = "/api/v1/views/logs/columns"
+	EndpointGetProfilerView   = "/api/v1/views/profiler"
+
+	EndpointGetFieldSuggestView = "/api/v1/views/fields/suggest"
+	EndpointGetFieldPathsView   = "/api/v1/views/fields/paths"
+	EndpointGetAggregateView    = "/api/v1/views/aggregate"
+	EndpointGetHistogramView    = "/api/v1/views/histogram"
+	EndpointGetPatternsView     = "/api/v1/views/patterns"
+	EndpointGetPatternLogsView  = "/api/v1/views/patterns/{id}/logs"
+	EndpointGetAlertsView       = "/api/v1/views/alerts"
+
+	EndpointGetProfilerTimeline = "/api/v1/profiler/timeline"
+	EndpointGetProfilerDiff     = "/api/v1/profiler/diff"
+	EndpointGetProfilerHealth   = "/api/v1/profiler/health"
+
+	EndpointGetProfilerCriticalPath = "/api/v1/profiler/critical-path/{producer_id}/{sequence_number}"
+
+	EndpointGetProfilerSessions  = "/api/v1/profiler/sessions"
+	EndpointPostProfilerSessions = "/api/v1/profiler/sessions"
+
+	EndpointGetSavedViews   = "/api/v1/saved-views"
+	EndpointPostSavedViews  = "/api/v1/saved-views"
+	EndpointDeleteSavedView = "/api/v1/saved-views/{name}"
+
+	// Permalink restoring a saved view.
+	EndpointGetSavedViewPage = "/views/{name}"
+
+	EndpointGetPipeline        = "/api/v1/pipeline"
+	EndpointPostPipelineReload = "/api/v1/pipeline/reload"
+
+	// OTLP/HTTP ingestion, at the default paths of OpenTelemetry exporters.
+	EndpointPostOTLPLogs   = "/v1/logs"
+	EndpointPostOTLPTraces = "/v1/traces"
 )
+
+// GetLogEndpoint returns the [EndpointGetLog] path for the given log id.
+func GetLogEndpoint(producerID string, sequenceNumber int) string {
+	return withLogID(EndpointGetLog, producerID, sequenceNumber)
+}
+
+// GetProfilerCriticalPathEndpoint returns the [EndpointGetProfilerCriticalPath]
+// path for the given root log id.
+func GetProfilerCriticalPathEndpoint(producerID string, sequenceNumber int) string {
+	return withLogID(EndpointGetProfilerCriticalPath, producerID, sequenceNumber)
+}
+
+// GetSavedViewEndpoint returns the [EndpointDeleteSavedView] path
+// for the given view name.
+func GetSavedViewEndpoint(name string) string {
+	return strings.Replace(EndpointDeleteSavedView, "{name}", url.PathEscape(name), 1)
+}
+
+// GetSavedViewPageEndpoint returns the [EndpointGetSavedViewPage] path,
+// the permalink of the given view name.
+func GetSavedViewPageEndpoint(name string) string {
+	return strings.Replace(EndpointGetSavedViewPage, "{name}", url.PathEscape(name), 1)
+}
+
+// GetPatternLogsViewEndpoint returns the [EndpointGetPatternLogsView]
+// path for the given pattern id.
+func GetPatternLogsViewEndpoint(id int) string {
+	return strings.Replace(EndpointGetPatternLogsView, "{id}", strconv.Itoa(id), 1)
+}
+
+func withLogID(endpoint, producerID string, sequenceNumber int) string {
+	r := strings.NewReplacer(
+		"{producer_id}", url.PathEscape(producerID),
+		"{sequence_number}", strconv.Itoa(sequenceNumber),
+	)
+	return r.Replace(endpoint)
+}
@@ -0,0 +1,12 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package types
+
+type ViewMode int
+
+const (
+	ViewModeLogList ViewMode = iota
+	ViewModeProfiler
+)
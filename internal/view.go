@@ -0,0 +1,50 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal/types"
+)
+
+const (
+	// MaxViewNameLength limits names of saved views.
+	MaxViewNameLength = 100
+
+	// MaxColumns limits columns of the logs table.
+	MaxColumns = 20
+	// MaxColumnWidth limits widths of columns in pixels.
+	MaxColumnWidth = 2000
+)
+
+// Column is a column of the logs table showing values at a flattened
+// attribute path, as accepted by [Log.Attr].
+type Column struct {
+	Path string `json:"path"`
+	// Width in pixels, or zero to share the remaining width.
+	Width int `json:"width,omitempty"`
+}
+
+// DefaultColumns are columns of the logs table unless configured.
+var DefaultColumns = []Column{
+	{Path: "timestamp", Width: 224},
+	{Path: "level", Width: 80},
+	{Path: "message"},
+}
+
+// SavedView is a named state of the UI: the filter query, visible
+// columns, time range and view mode, restored from its permalink.
+type SavedView struct {
+	Name    string         `json:"name"`
+	Mode    types.ViewMode `json:"mode"`
+	Query   string         `json:"query,omitempty"`
+	Columns []Column       `json:"columns,omitempty"`
+	// Time range filter, a zero bound leaves that side open.
+	From Timestamp `json:"from,omitempty"`
+	To   Timestamp `json:"to,omitempty"`
+
+	SavedAt time.Time `json:"saved_at"`
+}
@@ -52,7 +52,7 @@ type WebSocketClient struct {
 	ReadLimit        int64
 }
 
-func NewWebSocketClient(string, parentLogger zerolog.Logger) *WebSocketClient {
+func NewWebSocketClient(parentLogger zerolog.Logger) *WebSocketClient {
 	logger := parentLogger.
 		With().
 		Str("component", "websocket_client").
@@ -0,0 +1,140 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package producer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultPushBatchSize     = 256
+	DefaultPushFlushInterval = time.Second
+	DefaultPushTimeout       = 10 * time.Second
+
+	// ContentTypeNDJSON is the content type of pushed batches,
+	// one JSON encoded record per line.
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+type PushError struct {
+	StatusCode int
+}
+
+func (e *PushError) Error() string {
+	return fmt.Sprintf("push rejected with status %d", e.StatusCode)
+}
+
+// HTTPPusher posts records of a producer to logcrunch in batches.
+// Batches that fail to be delivered are dropped.
+type HTTPPusher struct {
+	p   *Producer
+	url string
+
+	Client        *http.Client
+	BatchSize     int
+	FlushInterval time.Duration
+
+	// OnError, if set, is called with errors of failed pushes.
+	OnError func(err error)
+}
+
+func NewHTTPPusher(p *Producer, url string) *HTTPPusher {
+	return &HTTPPusher{
+		p:   p,
+		url: url,
+
+		Client:        &http.Client{Timeout: DefaultPushTimeout},
+		BatchSize:     DefaultPushBatchSize,
+		FlushInterval: DefaultPushFlushInterval,
+	}
+}
+
+// Run pushes records until ctx is done, or the producer is closed
+// and all buffered records are pushed.
+func (h *HTTPPusher) Run(ctx context.Context) {
+	records := make(chan Record)
+	go func() {
+		defer close(records)
+		for {
+			rec, ok := h.p.next(ctx.Done())
+			if !ok {
+				return
+			}
+
+			select {
+			case records <- rec:
+			case <-ctx.Done():
+				h.p.dropped.Add(1)
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(h.FlushInterval)
+	defer ticker.Stop()
+
+	var batch bytes.Buffer
+	var count int
+	enc := json.NewEncoder(&batch)
+
+	flush := func() {
+		if count == 0 {
+			return
+		}
+		if err := h.push(ctx, batch.Bytes()); err != nil {
+			h.p.dropped.Add(uint64(count))
+			if h.OnError != nil {
+				h.OnError(err)
+			}
+		}
+		batch.Reset()
+		count = 0
+	}
+
+	for {
+		select {
+		case rec, ok := <-records:
+			if !ok {
+				flush()
+				return
+			}
+
+			if err := enc.Encode(rec); err != nil {
+				h.p.dropped.Add(1)
+				continue
+			}
+			count++
+			if count >= h.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (h *HTTPPusher) push(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error creating push request: %w", err)
+	}
+	req.Header.Set("Content-Type", ContentTypeNDJSON)
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error pushing records: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &PushError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
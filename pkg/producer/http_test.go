@@ -0,0 +1,79 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package producer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPPusher(t *testing.T) {
+	var mu sync.Mutex
+	var received []Record
+	var batches int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, ContentTypeNDJSON, r.Header.Get("Content-Type"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		batches++
+
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var rec Record
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+			received = append(received, rec)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	p := New(Options{ProducerID: "app"})
+	pusher := NewHTTPPusher(p, srv.URL)
+	pusher.BatchSize = 2
+	pusher.FlushInterval = time.Hour
+
+	for _, msg := range []string{"a", "b", "c"} {
+		p.Emit(Record{Message: msg})
+	}
+	assert.NoError(t, p.Close())
+
+	pusher.Run(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"a", "b", "c"}, messages(received))
+	assert.Equal(t, 2, batches)
+	assert.Zero(t, p.Dropped())
+}
+
+func TestHTTPPusher_Rejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	p := New(Options{ProducerID: "app"})
+	pusher := NewHTTPPusher(p, srv.URL)
+
+	var errs []error
+	pusher.OnError = func(err error) { errs = append(errs, err) }
+
+	p.Emit(Record{Message: "a"})
+	assert.NoError(t, p.Close())
+	pusher.Run(context.Background())
+
+	assert.Equal(t, []error{&PushError{StatusCode: http.StatusBadRequest}}, errs)
+	assert.Equal(t, uint64(1), p.Dropped())
+}
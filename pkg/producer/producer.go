@@ -0,0 +1,178 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Package producer emits logs to logcrunch from Go applications.
+//
+// Records are queued in a bounded buffer and delivered by a transport,
+// either [WebSocketServer] which logcrunch connects to, or [HTTPPusher]
+// which posts them to logcrunch. Emitting never blocks: when the buffer
+// is full, records are dropped according to the [DropPolicy].
+package producer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DefaultBufferSize = 1024
+
+type DropPolicy int
+
+const (
+	// DropNewest discards records emitted while the buffer is full.
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest buffered record to make room.
+	DropOldest
+)
+
+type LogID struct {
+	ProducerID     string `json:"producer_id"`
+	SequenceNumber int    `json:"sequence_number"`
+}
+
+// Record is a log in the logcrunch log schema.
+// Timestamps are in fractional seconds since the Unix epoch.
+type Record struct {
+	ID LogID `json:"id"`
+
+	Timestamp float64 `json:"timestamp"`
+	Level     string  `json:"level"`
+	Message   string  `json:"message"`
+
+	SourceFile     string `json:"source_file,omitempty"`
+	SourceLine     int    `json:"source_line,omitempty"`
+	SourceFunction string `json:"source_function,omitempty"`
+
+	FunctionCallStartedAt float64 `json:"function_call_started_at,omitempty"`
+	FunctionCallEndedAt   float64 `json:"function_call_ended_at,omitempty"`
+	FunctionCallStack     []LogID `json:"call_stack,omitempty"`
+
+	TraceID      string `json:"trace_id,omitempty"`
+	SpanID       string `json:"span_id,omitempty"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
+
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+// Timestamp converts t to a logcrunch timestamp.
+func Timestamp(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+type Options struct {
+	// ProducerID identifies the application in logcrunch.
+	ProducerID string
+
+	// BufferSize is the number of records buffered for delivery.
+	// Defaults to [DefaultBufferSize].
+	BufferSize int
+
+	DropPolicy DropPolicy
+}
+
+type Producer struct {
+	id         string
+	dropPolicy DropPolicy
+
+	sequence atomic.Int64
+	dropped  atomic.Uint64
+
+	queue     chan Record
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func New(opts Options) *Producer {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+
+	return &Producer{
+		id:         opts.ProducerID,
+		dropPolicy: opts.DropPolicy,
+		queue:      make(chan Record, size),
+		done:       make(chan struct{}),
+	}
+}
+
+func (p *Producer) ID() string {
+	return p.id
+}
+
+// NextID reserves the next log id of the producer.
+func (p *Producer) NextID() LogID {
+	return LogID{ProducerID: p.id, SequenceNumber: int(p.sequence.Add(1))}
+}
+
+// Emit queues r for delivery without blocking. If r has no id yet,
+// the next id of the producer is assigned to it.
+func (p *Producer) Emit(r Record) {
+	select {
+	case <-p.done:
+		p.dropped.Add(1)
+		return
+	default:
+	}
+
+	if r.ID == (LogID{}) {
+		r.ID = p.NextID()
+	}
+
+	for {
+		select {
+		case p.queue <- r:
+			return
+		default:
+		}
+
+		if p.dropPolicy == DropNewest {
+			p.dropped.Add(1)
+			return
+		}
+
+		select {
+		case <-p.queue:
+			p.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// Dropped returns the number of records discarded so far.
+func (p *Producer) Dropped() uint64 {
+	return p.dropped.Load()
+}
+
+// Close stops accepting records and signals transports to stop
+// once they have delivered what is buffered.
+func (p *Producer) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	return nil
+}
+
+// next returns the next buffered record. It returns false once
+// the producer is closed and the buffer is drained, or stop is closed.
+func (p *Producer) next(stop <-chan struct{}) (Record, bool) {
+	select {
+	case r := <-p.queue:
+		return r, true
+	default:
+	}
+
+	select {
+	case r := <-p.queue:
+		return r, true
+	case <-stop:
+		return Record{}, false
+	case <-p.done:
+		select {
+		case r := <-p.queue:
+			return r, true
+		default:
+			return Record{}, false
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package producer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func drain(p *Producer) []Record {
+	var res []Record
+	for {
+		select {
+		case r := <-p.queue:
+			res = append(res, r)
+		default:
+			return res
+		}
+	}
+}
+
+func messages(records []Record) []string {
+	res := make([]string, 0, len(records))
+	for _, r := range records {
+		res = append(res, r.Message)
+	}
+	return res
+}
+
+func TestProducer_Emit(t *testing.T) {
+	p := New(Options{ProducerID: "app"})
+	p.Emit(Record{Message: "a"})
+	p.Emit(Record{Message: "b", ID: LogID{ProducerID: "app", SequenceNumber: 42}})
+	p.Emit(Record{Message: "c"})
+
+	records := drain(p)
+	assert.Equal(t, []LogID{
+		{ProducerID: "app", SequenceNumber: 1},
+		{ProducerID: "app", SequenceNumber: 42},
+		{ProducerID: "app", SequenceNumber: 2},
+	}, []LogID{records[0].ID, records[1].ID, records[2].ID})
+}
+
+func TestProducer_DropPolicy(t *testing.T) {
+	tests := []struct {
+		policy DropPolicy
+		expect []string
+	}{
+		{DropNewest, []string{"a", "b"}},
+		{DropOldest, []string{"c", "d"}},
+	}
+
+	for _, tt := range tests {
+		p := New(Options{ProducerID: "app", BufferSize: 2, DropPolicy: tt.policy})
+		for _, msg := range []string{"a", "b", "c", "d"} {
+			p.Emit(Record{Message: msg})
+		}
+
+		assert.Equal(t, tt.expect, messages(drain(p)))
+		assert.Equal(t, uint64(2), p.Dropped())
+	}
+}
+
+func TestProducer_Close(t *testing.T) {
+	p := New(Options{ProducerID: "app"})
+	p.Emit(Record{Message: "a"})
+	assert.NoError(t, p.Close())
+	assert.NoError(t, p.Close())
+	p.Emit(Record{Message: "b"})
+
+	r, ok := p.next(nil)
+	assert.True(t, ok)
+	assert.Equal(t, "a", r.Message)
+
+	_, ok = p.next(nil)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(1), p.Dropped())
+}
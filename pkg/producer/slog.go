@@ -0,0 +1,189 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package producer
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Handler is a [slog.Handler] that emits records to a [Producer].
+type Handler struct {
+	p    *Producer
+	opts slog.HandlerOptions
+
+	// Attributes added with WithAttrs, already nested in groups.
+	attrs map[string]any
+	// Open groups added with WithGroup.
+	groups []string
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler returns a handler emitting to p. If opts is nil,
+// the default options are used. AddSource is implied, source location
+// is always filled in when available. ReplaceAttr is not supported.
+func NewHandler(p *Producer, opts *slog.HandlerOptions) *Handler {
+	h := &Handler{p: p}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle emits r. Records logged within a call tracked with [Start]
+// refer to it in their call stack.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	rec := Record{
+		Timestamp:         Timestamp(r.Time),
+		Level:             strings.ToLower(r.Level.String()),
+		Message:           r.Message,
+		FunctionCallStack: CallStack(ctx),
+	}
+	if r.Time.IsZero() {
+		rec.Timestamp = Timestamp(time.Now())
+	}
+
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		rec.SourceFile = frame.File
+		rec.SourceLine = frame.Line
+		rec.SourceFunction = frame.Function
+	}
+
+	attrs := cloneAttrs(h.attrs)
+	if r.NumAttrs() > 0 {
+		dest := openGroups(attrs, h.groups)
+		r.Attrs(func(a slog.Attr) bool {
+			addAttr(dest, a)
+			return true
+		})
+	}
+	if len(attrs) > 0 {
+		rec.Attrs = attrs
+	}
+
+	h.p.Emit(rec)
+	return nil
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.attrs = cloneAttrs(h.attrs)
+	dest := openGroups(h2.attrs, h.groups)
+	for _, a := range attrs {
+		addAttr(dest, a)
+	}
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// openGroups returns the map nested in attrs under groups,
+// creating missing levels.
+func openGroups(attrs map[string]any, groups []string) map[string]any {
+	for _, g := range groups {
+		m, ok := attrs[g].(map[string]any)
+		if !ok {
+			m = make(map[string]any)
+			attrs[g] = m
+		}
+		attrs = m
+	}
+	return attrs
+}
+
+func addAttr(dest map[string]any, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	v := a.Value
+	if v.Kind() == slog.KindGroup {
+		group := v.Group()
+		if len(group) == 0 {
+			return
+		}
+
+		// Inline groups with an empty key.
+		m := dest
+		if a.Key != "" {
+			nested, ok := dest[a.Key].(map[string]any)
+			if !ok {
+				nested = make(map[string]any, len(group))
+				dest[a.Key] = nested
+			}
+			m = nested
+		}
+		for _, ga := range group {
+			addAttr(m, ga)
+		}
+		return
+	}
+
+	dest[a.Key] = attrValue(v)
+}
+
+func attrValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return v.Duration().Seconds()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny, slog.KindGroup, slog.KindLogValuer:
+	}
+
+	if err, ok := v.Any().(error); ok {
+		return err.Error()
+	}
+	return v.Any()
+}
+
+// cloneAttrs deep copies nested attribute maps,
+// leaving other values shared.
+func cloneAttrs(attrs map[string]any) map[string]any {
+	res := make(map[string]any, len(attrs))
+	for k, v := range attrs {
+		if m, ok := v.(map[string]any); ok {
+			v = cloneAttrs(m)
+		}
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package producer
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	p := New(Options{ProducerID: "app"})
+	logger := slog.New(NewHandler(p, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	logger.
+		With("env", "dev").
+		WithGroup("req").
+		With("method", "GET").
+		Warn("slow request",
+			"latency", 1500*time.Millisecond,
+			slog.Group("user", "id", 7),
+			"err", errors.New("timeout"),
+		)
+
+	records := drain(p)
+	assert.Len(t, records, 1)
+
+	r := records[0]
+	assert.Equal(t, LogID{ProducerID: "app", SequenceNumber: 1}, r.ID)
+	assert.Equal(t, "warn", r.Level)
+	assert.Equal(t, "slow request", r.Message)
+	assert.True(t, strings.HasSuffix(r.SourceFile, "slog_test.go"))
+	assert.Equal(t, "github.com/KirilStrezikozin/logcrunch/pkg/producer.TestHandler", r.SourceFunction)
+	assert.NotZero(t, r.SourceLine)
+	assert.InDelta(t, Timestamp(time.Now()), r.Timestamp, 5)
+	assert.Equal(t, map[string]any{
+		"env": "dev",
+		"req": map[string]any{
+			"method":  "GET",
+			"latency": 1.5,
+			"user":    map[string]any{"id": int64(7)},
+			"err":     "timeout",
+		},
+	}, r.Attrs)
+}
+
+func TestHandler_Enabled(t *testing.T) {
+	p := New(Options{ProducerID: "app"})
+	logger := slog.New(NewHandler(p, nil))
+
+	logger.Debug("hidden")
+	logger.Info("shown")
+	assert.Equal(t, []string{"shown"}, messages(drain(p)))
+}
+
+func TestHandler_Schema(t *testing.T) {
+	p := New(Options{ProducerID: "app"})
+	slog.New(NewHandler(p, nil)).Info("hello", "user", "alice")
+
+	data, err := json.Marshal(drain(p)[0])
+	assert.NoError(t, err)
+
+	log, err := internal.NewLog(data)
+	assert.NoError(t, err)
+	assert.Equal(t, internal.LogID{ProducerID: "app", SequenceNumber: 1}, log.ID)
+	assert.Equal(t, "info", log.Level)
+	assert.Equal(t, "hello", log.Message)
+	assert.Equal(t, "github.com/KirilStrezikozin/logcrunch/pkg/producer.TestHandler_Schema", log.SourceFunction)
+
+	user, ok := log.Attr("attrs.user")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", user)
+}
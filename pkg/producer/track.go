@@ -0,0 +1,127 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package producer
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type contextKey int
+
+const (
+	producerKey contextKey = iota
+	callStackKey
+)
+
+var defaultProducer atomic.Pointer[Producer]
+
+// SetDefault sets the producer used by [Start] and [Track]
+// when the context does not carry one.
+func SetDefault(p *Producer) {
+	defaultProducer.Store(p)
+}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p *Producer) context.Context {
+	return context.WithValue(ctx, producerKey, p)
+}
+
+// FromContext returns the producer carried by ctx, or the default one.
+// It returns nil if neither is set.
+func FromContext(ctx context.Context) *Producer {
+	if p, ok := ctx.Value(producerKey).(*Producer); ok {
+		return p
+	}
+	return defaultProducer.Load()
+}
+
+// CallStack returns ids of the tracked calls ctx is within, outermost first.
+func CallStack(ctx context.Context) []LogID {
+	stack, _ := ctx.Value(callStackKey).([]LogID)
+	return stack
+}
+
+// Start begins tracking a function call. It returns a context to pass
+// to callees, so calls tracked with it are recorded as children, and
+// a function to call when the call returns, which emits a metric log:
+//
+//	ctx, end := producer.Start(ctx, "DB.Query")
+//	defer end()
+//
+// The returned context is safe to share with other goroutines.
+// If name is empty, the name of the calling function is used.
+func Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, func()) {
+	return start(ctx, name, attrs)
+}
+
+// Track is like [Start] for calls that do not track callees
+// with the returned context:
+//
+//	defer producer.Track(ctx, "DB.Query")()
+func Track(ctx context.Context, name string, attrs ...slog.Attr) func() {
+	_, end := start(ctx, name, attrs)
+	return end
+}
+
+// start must be called directly by the exported functions
+// for the caller to be resolved correctly.
+func start(ctx context.Context, name string, attrs []slog.Attr) (context.Context, func()) {
+	p := FromContext(ctx)
+	if p == nil {
+		return ctx, func() {}
+	}
+
+	rec := Record{
+		ID:             p.NextID(),
+		Level:          "info",
+		SourceFunction: name,
+	}
+
+	// Skip runtime.Callers, start and the exported function.
+	var pcs [1]uintptr
+	if runtime.Callers(3, pcs[:]) > 0 {
+		frame, _ := runtime.CallersFrames(pcs[:]).Next()
+		rec.SourceFile = frame.File
+		rec.SourceLine = frame.Line
+		if rec.SourceFunction == "" {
+			rec.SourceFunction = frame.Function
+		}
+	}
+	rec.Message = rec.SourceFunction
+
+	if len(attrs) > 0 {
+		rec.Attrs = make(map[string]any, len(attrs))
+		for _, a := range attrs {
+			addAttr(rec.Attrs, a)
+		}
+	}
+
+	parentStack := CallStack(ctx)
+	rec.FunctionCallStack = parentStack
+
+	// Copy the parent stack so that sibling calls
+	// in other goroutines never share the backing array.
+	stack := make([]LogID, len(parentStack), len(parentStack)+1)
+	copy(stack, parentStack)
+	stack = append(stack, rec.ID)
+	ctx = context.WithValue(ctx, callStackKey, stack)
+
+	startedAt := time.Now()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			endedAt := time.Now()
+			rec.Timestamp = Timestamp(endedAt)
+			rec.FunctionCallStartedAt = Timestamp(startedAt)
+			rec.FunctionCallEndedAt = Timestamp(endedAt)
+			p.Emit(rec)
+		})
+	}
+}
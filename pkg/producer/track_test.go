@@ -0,0 +1,91 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/KirilStrezikozin/logcrunch/internal"
+	"github.com/KirilStrezikozin/logcrunch/internal/profiler"
+	"github.com/stretchr/testify/assert"
+)
+
+func handleRequest(ctx context.Context, logger *slog.Logger) {
+	ctx, end := Start(ctx, "handler", slog.String("route", "/"))
+	defer end()
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			query(ctx)
+		}()
+	}
+	wg.Wait()
+
+	logger.InfoContext(ctx, "done")
+}
+
+func query(ctx context.Context) {
+	defer Track(ctx, "")()
+}
+
+func TestStart_CallTree(t *testing.T) {
+	p := New(Options{ProducerID: "app"})
+	ctx := NewContext(context.Background(), p)
+	handleRequest(ctx, slog.New(NewHandler(p, nil)))
+
+	records := drain(p)
+	assert.Len(t, records, 4)
+
+	logs := make([]internal.Log, 0, len(records))
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		assert.NoError(t, err)
+		log, err := internal.NewLog(data)
+		assert.NoError(t, err)
+		logs = append(logs, log)
+	}
+
+	tree := profiler.BuildCallTree(logs)
+	assert.Equal(t, 3, tree.Len())
+	assert.Len(t, tree.Roots, 1)
+
+	root := tree.Roots[0]
+	assert.Equal(t, "handler", root.Name())
+	assert.Equal(t, "/", root.Log.Attrs["route"])
+	assert.Len(t, root.Children, 2)
+	for _, c := range root.Children {
+		assert.Equal(t, "github.com/KirilStrezikozin/logcrunch/pkg/producer.query", c.Name())
+		assert.GreaterOrEqual(t, c.Start(), root.Start())
+		assert.LessOrEqual(t, c.End(), root.End())
+	}
+
+	report := profiler.CheckHealth(logs, profiler.Window{})
+	assert.True(t, report.Healthy())
+
+	done := records[2]
+	assert.Equal(t, "done", done.Message)
+	assert.Equal(t, []LogID{{ProducerID: "app", SequenceNumber: 1}}, done.FunctionCallStack)
+}
+
+func TestTrack_NoProducer(t *testing.T) {
+	ctx, end := Start(context.Background(), "noop")
+	end()
+	assert.Empty(t, CallStack(ctx))
+}
+
+func TestTrack_EndOnce(t *testing.T) {
+	p := New(Options{ProducerID: "app"})
+	end := Track(NewContext(context.Background(), p), "once")
+	end()
+	end()
+	assert.Len(t, drain(p), 1)
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package producer
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	WebSocketHandshakeTimeout = 10 * time.Second
+	WebSocketWriteTimeout     = 10 * time.Second
+	WebSocketPingPeriod       = 9 * time.Second
+)
+
+// WebSocketServer is an [http.Handler] streaming records
+// of a producer to connected logcrunch clients.
+//
+// Every record is delivered to a single client. Records are buffered
+// by the producer while no client is connected.
+type WebSocketServer struct {
+	p        *Producer
+	upgrader websocket.Upgrader
+
+	WriteTimeout time.Duration
+	PingPeriod   time.Duration
+}
+
+func NewWebSocketServer(p *Producer) *WebSocketServer {
+	return &WebSocketServer{
+		p: p,
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: WebSocketHandshakeTimeout,
+		},
+
+		WriteTimeout: WebSocketWriteTimeout,
+		PingPeriod:   WebSocketPingPeriod,
+	}
+}
+
+func (s *WebSocketServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade replies to the client on failure.
+	}
+	defer conn.Close()
+
+	// Read to process control messages and notice the client leaving.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	records := make(chan Record)
+	go func() {
+		defer close(records)
+		for {
+			rec, ok := s.p.next(closed)
+			if !ok {
+				return
+			}
+
+			select {
+			case records <- rec:
+			case <-closed:
+				s.p.dropped.Add(1)
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(s.PingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case rec, ok := <-records:
+			if !ok {
+				msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+				_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(s.WriteTimeout))
+				return
+			}
+
+			data, err := json.Marshal(rec)
+			if err != nil {
+				s.p.dropped.Add(1)
+				continue
+			}
+
+			_ = conn.SetWriteDeadline(time.Now().Add(s.WriteTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				s.p.dropped.Add(1)
+				return
+			}
+		case <-ping.C:
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(s.WriteTimeout))
+			if err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Resizing of logs table columns. Dragging the border of a column header
+// sets its width, held by a hidden input, and updates the grid template
+// of the table. Widths are capped at the data-max-column-width of the
+// table, the widest the server accepts.
+(function () {
+  const minWidth = 48;
+
+  let drag = null;
+
+  function gridColumns(table) {
+    const widths = table.querySelectorAll("input[name='width']");
+    return Array.from(widths)
+      .map((input) => (parseInt(input.value, 10) > 0 ? input.value + "px" : "minmax(0,1fr)"))
+      .join(" ");
+  }
+
+  document.addEventListener("mousedown", (e) => {
+    const handle = e.target.closest("[data-column-resize]");
+    if (!handle || e.button !== 0) {
+      return;
+    }
+    e.preventDefault();
+
+    const column = handle.closest("[data-column]");
+    const table = handle.closest("[data-log-table]");
+    drag = {
+      table: table,
+      input: column.querySelector("input[name='width']"),
+      maxWidth: parseInt(table.dataset.maxColumnWidth, 10) || Infinity,
+      startX: e.clientX,
+      startWidth: column.getBoundingClientRect().width,
+    };
+  });
+
+  document.addEventListener("mousemove", (e) => {
+    if (!drag) {
+      return;
+    }
+    const width = Math.min(
+      drag.maxWidth,
+      Math.max(minWidth, Math.round(drag.startWidth + e.clientX - drag.startX)),
+    );
+    drag.input.value = width;
+    drag.table.style.setProperty("--log-columns", gridColumns(drag.table));
+  });
+
+  document.addEventListener("mouseup", () => {
+    drag = null;
+  });
+})();
@@ -0,0 +1,79 @@
+// Copyright 2025 The Logcrunch Authors. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+// Brush-to-zoom on the log volume histogram. Dragging across the
+// histogram sets the time range filter held by hidden inputs, then
+// triggers an event refreshing views filtered by it.
+(function () {
+  const rangeChanged = "range-changed";
+
+  function setRange(from, to) {
+    document.getElementById("range-from").value = from;
+    document.getElementById("range-to").value = to;
+    document.body.dispatchEvent(new Event(rangeChanged));
+  }
+
+  let brush = null;
+
+  document.addEventListener("mousedown", (e) => {
+    const strip = e.target.closest("[data-histogram-from]");
+    if (!strip || e.button !== 0) {
+      return;
+    }
+    e.preventDefault();
+
+    const rect = strip.getBoundingClientRect();
+    brush = {
+      strip: strip,
+      rect: rect,
+      start: e.clientX - rect.left,
+      end: e.clientX - rect.left,
+      el: strip.querySelector("[data-histogram-brush]"),
+    };
+    strip.parentElement.dataset.brushing = "true";
+    draw();
+  });
+
+  document.addEventListener("mousemove", (e) => {
+    if (!brush) {
+      return;
+    }
+    brush.end = Math.min(Math.max(e.clientX - brush.rect.left, 0), brush.rect.width);
+    draw();
+  });
+
+  document.addEventListener("mouseup", () => {
+    if (!brush) {
+      return;
+    }
+    const b = brush;
+    brush = null;
+    delete b.strip.parentElement.dataset.brushing;
+    b.el.classList.add("hidden");
+
+    const left = Math.min(b.start, b.end);
+    const right = Math.max(b.start, b.end);
+    if (right - left < 3) {
+      return;
+    }
+
+    const from = parseFloat(b.strip.dataset.histogramFrom);
+    const to = parseFloat(b.strip.dataset.histogramTo);
+    const at = (x) => from + (x / b.rect.width) * (to - from);
+    setRange(at(left).toFixed(3), at(right).toFixed(3));
+  });
+
+  document.addEventListener("click", (e) => {
+    if (e.target.closest("[data-histogram-reset]")) {
+      setRange("", "");
+    }
+  });
+
+  function draw() {
+    const left = Math.min(brush.start, brush.end);
+    brush.el.style.left = left + "px";
+    brush.el.style.width = Math.abs(brush.end - brush.start) + "px";
+    brush.el.classList.remove("hidden");
+  }
+})();